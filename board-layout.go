@@ -0,0 +1,197 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import "fmt"
+
+// Defaults of the flow layout.
+const (
+	DefaultLayoutColumns = 2
+	DefaultLayoutHeight  = 8
+)
+
+// LayoutOptions configures Board.Layout().
+type LayoutOptions struct {
+	// Columns is the number of panels in a line for panels which width
+	// is not set explicitly. Zero means DefaultLayoutColumns.
+	Columns int
+	// Height of panels in grid cells for panels which height is not set
+	// explicitly. Zero means DefaultLayoutHeight.
+	Height int
+}
+
+// Layout assigns grid positions to all panels of the board in the order
+// they are listed. Panels flow from left to right and wrap to the next
+// line when they don't fit into GridColumnCount columns. Width of a panel
+// is taken from its GridPos.W if set, else from its legacy span (12
+// columns based) if set, else the line is divided evenly by
+// opts.Columns. Height is taken from GridPos.H if set. Row panels always
+// take a whole line and start a new one; panels of collapsed rows are
+// laid out just below their row without moving the following panels, as
+// Grafana does on expanding. The result depends only on the panels, so
+// repeated calls give the same positions.
+func (b *Board) Layout(opts LayoutOptions) {
+	if opts.Columns <= 0 {
+		opts.Columns = DefaultLayoutColumns
+	}
+	if opts.Height <= 0 {
+		opts.Height = DefaultLayoutHeight
+	}
+	flow := layoutFlow{opts: opts}
+	for _, p := range b.Panels {
+		if p == nil {
+			continue
+		}
+		if p.OfType != RowType {
+			flow.place(&p.CommonPanel)
+			continue
+		}
+		flow.newLine()
+		p.setGridPos(0, flow.y, GridColumnCount, 1)
+		flow.y++
+		if p.RowPanel == nil || !p.RowPanel.Collapsed {
+			continue
+		}
+		nested := layoutFlow{opts: opts, y: flow.y}
+		for i := range p.RowPanel.Panels {
+			nested.place(&p.RowPanel.Panels[i].CommonPanel)
+		}
+	}
+}
+
+type layoutFlow struct {
+	opts       LayoutOptions
+	x, y       int
+	lineHeight int
+}
+
+func (f *layoutFlow) newLine() {
+	if f.x > 0 {
+		f.y += f.lineHeight
+	}
+	f.x, f.lineHeight = 0, 0
+}
+
+func (f *layoutFlow) place(p *CommonPanel) {
+	w := gridValue(p.GridPos.W)
+	if w <= 0 && p.Span > 0 {
+		w = int(p.Span) * GridColumnCount / legacyColumnCount
+	}
+	if w <= 0 {
+		w = GridColumnCount / f.opts.Columns
+	}
+	if w > GridColumnCount {
+		w = GridColumnCount
+	}
+	h := gridValue(p.GridPos.H)
+	if h <= 0 {
+		h = f.opts.Height
+	}
+	if f.x+w > GridColumnCount {
+		f.newLine()
+	}
+	p.setGridPos(f.x, f.y, w, h)
+	f.x += w
+	if h > f.lineHeight {
+		f.lineHeight = h
+	}
+}
+
+// LayoutProblem describes a panel with wrong grid position found by
+// Board.ValidateLayout().
+type LayoutProblem struct {
+	PanelID uint
+	Title   string
+	// OverlapsID is the ID of the panel overlapped by this one, it is
+	// set only for overlaps.
+	OverlapsID *uint
+	Reason     string
+}
+
+func (p LayoutProblem) Error() string {
+	return fmt.Sprintf("panel %q (id %d): %s", p.Title, p.PanelID, p.Reason)
+}
+
+// ValidateLayout reports panels without grid position, panels out of the
+// grid bounds and overlapping panels. Panels of collapsed rows are
+// checked against each other only because they are hidden until the
+// row is expanded.
+func (b *Board) ValidateLayout() []LayoutProblem {
+	var (
+		problems []LayoutProblem
+		top      = make([]*CommonPanel, 0, len(b.Panels))
+	)
+	for _, p := range b.Panels {
+		if p == nil {
+			continue
+		}
+		top = append(top, &p.CommonPanel)
+		if p.OfType == RowType && p.RowPanel != nil && p.RowPanel.Collapsed {
+			nested := make([]*CommonPanel, 0, len(p.RowPanel.Panels))
+			for i := range p.RowPanel.Panels {
+				nested = append(nested, &p.RowPanel.Panels[i].CommonPanel)
+			}
+			problems = append(problems, validateGridPos(nested)...)
+		}
+	}
+	return append(validateGridPos(top), problems...)
+}
+
+func validateGridPos(panels []*CommonPanel) []LayoutProblem {
+	var (
+		problems []LayoutProblem
+		placed   []*CommonPanel
+	)
+	for _, p := range panels {
+		pos := p.GridPos
+		problem := LayoutProblem{PanelID: p.ID, Title: p.Title}
+		switch {
+		case pos.X == nil || pos.Y == nil || pos.W == nil || pos.H == nil:
+			problem.Reason = "grid position is not set"
+		case *pos.W <= 0 || *pos.H <= 0:
+			problem.Reason = fmt.Sprintf("wrong size %dx%d", *pos.W, *pos.H)
+		case *pos.X < 0 || *pos.Y < 0 || *pos.X+*pos.W > GridColumnCount:
+			problem.Reason = fmt.Sprintf("out of the grid bounds: x=%d y=%d w=%d (%d columns)",
+				*pos.X, *pos.Y, *pos.W, GridColumnCount)
+		}
+		if problem.Reason != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		for _, other := range placed {
+			if overlaps(pos, other.GridPos) {
+				id := other.ID
+				problems = append(problems, LayoutProblem{
+					PanelID:    p.ID,
+					Title:      p.Title,
+					OverlapsID: &id,
+					Reason:     fmt.Sprintf("overlaps panel %q (id %d)", other.Title, other.ID),
+				})
+			}
+		}
+		placed = append(placed, p)
+	}
+	return problems
+}
+
+func overlaps(a, b GridPos) bool {
+	return *a.X < *b.X+*b.W && *b.X < *a.X+*a.W &&
+		*a.Y < *b.Y+*b.H && *b.Y < *a.Y+*a.H
+}
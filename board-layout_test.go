@@ -0,0 +1,92 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestBoard_Layout(t *testing.T) {
+	board := sdk.NewBoard("Layout")
+	a := sdk.NewGraph("A")
+	b := sdk.NewGraph("B")
+	c := sdk.NewGraph("C")
+	c.Span = 0
+	c.GridPos.W = intPtr(16)
+	c.GridPos.H = intPtr(4)
+	row := sdk.NewRow("Collapsed")
+	row.RowPanel.Collapsed = true
+	row.RowPanel.Panels = []sdk.Panel{*sdk.NewText("D"), *sdk.NewText("E")}
+	f := sdk.NewStat("F")
+	f.Span = 3
+	board.Panels = []*sdk.Panel{a, b, nil, c, row, f}
+	// Graphs made with NewGraph have span 12, reset to use columns.
+	a.Span, b.Span = 0, 0
+
+	board.Layout(sdk.LayoutOptions{Columns: 3})
+
+	checkGridPos(t, a, 0, 0, 8, 8)
+	checkGridPos(t, b, 8, 0, 8, 8)
+	checkGridPos(t, c, 0, 8, 16, 4)
+	checkGridPos(t, row, 0, 12, 24, 1)
+	checkGridPos(t, &row.RowPanel.Panels[0], 0, 13, 8, 8)
+	checkGridPos(t, &row.RowPanel.Panels[1], 8, 13, 8, 8)
+	checkGridPos(t, f, 0, 13, 6, 8)
+
+	if problems := board.ValidateLayout(); len(problems) != 0 {
+		t.Errorf("layout should be valid but got %v", problems)
+	}
+
+	// Layout should be stable.
+	board.Layout(sdk.LayoutOptions{Columns: 3})
+	checkGridPos(t, f, 0, 13, 6, 8)
+}
+
+func TestBoard_ValidateLayout(t *testing.T) {
+	board := sdk.NewBoard("Broken")
+	a := sdk.NewGraph("A")
+	a.ID = 1
+	a.GridPos = sdk.GridPos{X: intPtr(0), Y: intPtr(0), W: intPtr(12), H: intPtr(8)}
+	b := sdk.NewGraph("B")
+	b.ID = 2
+	b.GridPos = sdk.GridPos{X: intPtr(6), Y: intPtr(4), W: intPtr(12), H: intPtr(8)}
+	c := sdk.NewGraph("C")
+	c.ID = 3
+	c.GridPos = sdk.GridPos{X: intPtr(20), Y: intPtr(20), W: intPtr(8), H: intPtr(8)}
+	d := sdk.NewGraph("D")
+	d.ID = 4
+	board.Panels = []*sdk.Panel{a, nil, b, c, d}
+
+	problems := board.ValidateLayout()
+	if len(problems) != 3 {
+		t.Fatalf("should be 3 problems but got %d: %v", len(problems), problems)
+	}
+	if problems[0].PanelID != 2 || problems[0].OverlapsID == nil || *problems[0].OverlapsID != 1 {
+		t.Errorf("panel 2 should overlap panel 1, got %v", problems[0])
+	}
+	if problems[1].PanelID != 3 || problems[1].OverlapsID != nil {
+		t.Errorf("panel 3 should be out of bounds, got %v", problems[1])
+	}
+	if problems[2].PanelID != 4 {
+		t.Errorf("panel 4 should have no position, got %v", problems[2])
+	}
+}
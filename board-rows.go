@@ -0,0 +1,271 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Constants of the grid layout introduced in Grafana 5.
+const (
+	GridColumnCount = 24
+	GridCellHeight  = 30 // px
+	GridCellMargin  = 8  // px
+
+	// GridLayoutSchemaVersion is the first dashboard schema version
+	// that uses gridPos instead of rows.
+	GridLayoutSchemaVersion = 16
+
+	defaultRowHeight  = 250 // px
+	minPanelHeight    = GridCellHeight * 3
+	legacyColumnCount = 12
+)
+
+// ConvertRowsToPanels moves panels from legacy rows into the flat list of
+// panels laid out with gridPos as Grafana does when it loads a dashboard
+// with schema version below 16. Span of panels turns into the width and
+// the height of a row (or of a panel) turns into the height on the grid.
+// Rows become "row" panels when any of them is collapsed, repeated or
+// shows its title. Panels of collapsed rows are nested into their row
+// panels. Schema version is raised to GridLayoutSchemaVersion.
+func (b *Board) ConvertRowsToPanels() {
+	if len(b.Rows) == 0 {
+		b.Rows = nil
+		b.upgradeSchemaVersion()
+		return
+	}
+	showRows := false
+	for _, row := range b.Rows {
+		if row.Collapse || row.ShowTitle || row.Repeat != nil {
+			showRows = true
+			break
+		}
+	}
+	yPos := 0
+	for _, p := range b.Panels {
+		if p.GridPos.Y != nil && p.GridPos.H != nil && *p.GridPos.Y+*p.GridPos.H > yPos {
+			yPos = *p.GridPos.Y + *p.GridPos.H
+		}
+	}
+	for _, row := range b.Rows {
+		rowHeight := gridHeight(parseHeight(string(row.Height)))
+		var rowPanel *Panel
+		if showRows {
+			rowPanel = NewRow(row.Title)
+			rowPanel.ID = b.NextPanelID()
+			rowPanel.Repeat = row.Repeat
+			rowPanel.RowPanel.Collapsed = row.Collapse
+			rowPanel.setGridPos(0, yPos, GridColumnCount, 1)
+			b.Panels = append(b.Panels, rowPanel)
+			yPos++
+		}
+		area := rowArea{y: yPos}
+		for i := range row.Panels {
+			p := row.Panels[i]
+			width := int(math.Floor(float64(p.Span))) * GridColumnCount / legacyColumnCount
+			if width <= 0 {
+				width = GridColumnCount
+			}
+			height := rowHeight
+			if h := parseHeight(p.Height); h > 0 {
+				height = gridHeight(h)
+			}
+			x, y := area.place(width, height)
+			p.setGridPos(x, y, width, height)
+			if rowPanel != nil && row.Collapse {
+				rowPanel.RowPanel.Panels = append(rowPanel.RowPanel.Panels, p)
+				continue
+			}
+			b.Panels = append(b.Panels, &p)
+		}
+		if rowPanel == nil || !row.Collapse {
+			yPos += area.height(rowHeight)
+		}
+	}
+	b.Rows = nil
+	b.upgradeSchemaVersion()
+}
+
+// ConvertPanelsToRows is the reverse of ConvertRowsToPanels for Grafana
+// versions older than 5. Each "row" panel starts a new row, panels above
+// the first row panel go to a row without title. Width of panels turns
+// into span and the height of the highest panel becomes the row height.
+// Schema version is lowered below GridLayoutSchemaVersion.
+func (b *Board) ConvertPanelsToRows() {
+	panels := make([]*Panel, len(b.Panels))
+	copy(panels, b.Panels)
+	sort.SliceStable(panels, func(i, j int) bool {
+		yi, yj := gridValue(panels[i].GridPos.Y), gridValue(panels[j].GridPos.Y)
+		if yi != yj {
+			return yi < yj
+		}
+		return gridValue(panels[i].GridPos.X) < gridValue(panels[j].GridPos.X)
+	})
+	var (
+		row       *Row
+		rowHeight int
+	)
+	flush := func() {
+		if row == nil {
+			return
+		}
+		if rowHeight == 0 {
+			rowHeight = gridHeight(defaultRowHeight)
+		}
+		row.Height = Height(strconv.Itoa(pixelHeight(rowHeight)) + "px")
+		if !row.ShowTitle && len(row.Panels) == 0 {
+			return
+		}
+		row.board = b
+		b.Rows = append(b.Rows, row)
+	}
+	addPanel := func(p Panel) {
+		if w := gridValue(p.GridPos.W); w > 0 {
+			p.Span = float32(w * legacyColumnCount / GridColumnCount)
+			if p.Span < 1 {
+				p.Span = 1
+			}
+		}
+		if h := gridValue(p.GridPos.H); h > rowHeight {
+			rowHeight = h
+		}
+		p.GridPos = GridPos{}
+		row.Panels = append(row.Panels, p)
+	}
+	b.Rows = nil
+	for _, p := range panels {
+		if p.OfType == RowType {
+			flush()
+			row = &Row{Title: p.Title, ShowTitle: true, Editable: true, Repeat: p.Repeat}
+			rowHeight = 0
+			if p.RowPanel != nil {
+				row.Collapse = p.RowPanel.Collapsed
+				for _, nested := range p.RowPanel.Panels {
+					addPanel(nested)
+				}
+			}
+			continue
+		}
+		if row == nil {
+			row = &Row{Title: "New row", Editable: true}
+		}
+		addPanel(*p)
+	}
+	flush()
+	if b.Rows == nil {
+		b.Rows = []*Row{}
+	}
+	b.Panels = nil
+	if b.SchemaVersion >= GridLayoutSchemaVersion {
+		b.SchemaVersion = GridLayoutSchemaVersion - 1
+	}
+}
+
+func (b *Board) upgradeSchemaVersion() {
+	if b.SchemaVersion < GridLayoutSchemaVersion {
+		b.SchemaVersion = GridLayoutSchemaVersion
+	}
+}
+
+// rowArea places panels of a legacy row from left to right wrapping them
+// to the next line when they don't fit into the grid width.
+type rowArea struct {
+	x, y       int
+	lineHeight int
+	used       int
+}
+
+func (a *rowArea) place(w, h int) (int, int) {
+	if a.x+w > GridColumnCount {
+		a.y += a.lineHeight
+		a.used += a.lineHeight
+		a.x, a.lineHeight = 0, 0
+	}
+	x, y := a.x, a.y
+	a.x += w
+	if h > a.lineHeight {
+		a.lineHeight = h
+	}
+	return x, y
+}
+
+// height returns the total height taken by the placed panels but not less
+// than the row height.
+func (a *rowArea) height(rowHeight int) int {
+	if h := a.used + a.lineHeight; h > rowHeight {
+		return h
+	}
+	return rowHeight
+}
+
+// parseHeight parses height in pixels given as a number or a string
+// like "250px". It returns 0 for empty or invalid height.
+func parseHeight(h interface{}) int {
+	switch v := h.(type) {
+	case string:
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px"))
+		if err != nil {
+			return 0
+		}
+		return n
+	case float64:
+		return int(v)
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return 0
+		}
+		return int(n)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// gridHeight converts height in pixels to the height in grid cells.
+func gridHeight(px int) int {
+	if px <= 0 {
+		px = defaultRowHeight
+	}
+	if px < minPanelHeight {
+		px = minPanelHeight
+	}
+	return int(math.Ceil(float64(px) / float64(GridCellHeight+GridCellMargin)))
+}
+
+// pixelHeight converts height in grid cells to the height in pixels.
+func pixelHeight(h int) int {
+	return h*GridCellHeight + (h-1)*GridCellMargin
+}
+
+func gridValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func (p *CommonPanel) setGridPos(x, y, w, h int) {
+	p.GridPos.X, p.GridPos.Y, p.GridPos.W, p.GridPos.H = &x, &y, &w, &h
+}
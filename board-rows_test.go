@@ -0,0 +1,166 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func checkGridPos(t *testing.T, p *sdk.Panel, x, y, w, h int) {
+	t.Helper()
+	pos := p.GridPos
+	if pos.X == nil || pos.Y == nil || pos.W == nil || pos.H == nil {
+		t.Fatalf("panel %q has no grid position", p.Title)
+	}
+	if *pos.X != x || *pos.Y != y || *pos.W != w || *pos.H != h {
+		t.Errorf("panel %q should be at x=%d y=%d w=%d h=%d but got x=%d y=%d w=%d h=%d",
+			p.Title, x, y, w, h, *pos.X, *pos.Y, *pos.W, *pos.H)
+	}
+}
+
+func TestBoard_ConvertRowsToPanels(t *testing.T) {
+	board := sdk.NewBoard("Rows")
+	row1 := board.AddRow("First")
+	row1.ShowTitle = true
+	for _, title := range []string{"A", "B", "C"} {
+		g := sdk.NewGraph(title)
+		g.Span = 6
+		row1.Add(g)
+	}
+	row2 := board.AddRow("Second")
+	row2.Collapse = true
+	row2.Height = "400px"
+	row2.Add(sdk.NewGraph("D"))
+	row3 := board.AddRow("Third")
+	row3.Add(sdk.NewText("E"))
+
+	board.ConvertRowsToPanels()
+
+	if board.Rows != nil {
+		t.Error("rows should be removed")
+	}
+	if board.SchemaVersion != sdk.GridLayoutSchemaVersion {
+		t.Errorf("schema version should be %d but got %d", sdk.GridLayoutSchemaVersion, board.SchemaVersion)
+	}
+	if len(board.Panels) != 7 {
+		t.Fatalf("should be 7 panels but got %d", len(board.Panels))
+	}
+	// 250px row is 7 grid cells high.
+	checkGridPos(t, board.Panels[0], 0, 0, 24, 1)
+	checkGridPos(t, board.Panels[1], 0, 1, 12, 7)
+	checkGridPos(t, board.Panels[2], 12, 1, 12, 7)
+	checkGridPos(t, board.Panels[3], 0, 8, 12, 7)
+	checkGridPos(t, board.Panels[4], 0, 15, 24, 1)
+	checkGridPos(t, board.Panels[5], 0, 16, 24, 1)
+	checkGridPos(t, board.Panels[6], 0, 17, 24, 7)
+
+	collapsed := board.Panels[4]
+	if collapsed.OfType != sdk.RowType || !collapsed.RowPanel.Collapsed {
+		t.Fatalf("second row should be a collapsed row panel")
+	}
+	if len(collapsed.RowPanel.Panels) != 1 {
+		t.Fatalf("panels of a collapsed row should be nested")
+	}
+	// 400px row is 11 grid cells high.
+	checkGridPos(t, &collapsed.RowPanel.Panels[0], 0, 16, 24, 11)
+
+	ids := make(map[uint]bool)
+	for _, p := range board.Panels {
+		if ids[p.ID] {
+			t.Errorf("panel ID %d is duplicated", p.ID)
+		}
+		ids[p.ID] = true
+	}
+}
+
+func TestBoard_ConvertRowsToPanels_NoRowPanels(t *testing.T) {
+	var board sdk.Board
+	raw, _ := ioutil.ReadFile("testdata/default-panels-all-types-2-rows-dashboard-2.6.json")
+	if err := json.Unmarshal(raw, &board); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for _, row := range board.Rows {
+		count += len(row.Panels)
+	}
+
+	board.ConvertRowsToPanels()
+
+	if len(board.Panels) != count {
+		t.Fatalf("should be %d panels but got %d", count, len(board.Panels))
+	}
+	for _, p := range board.Panels {
+		if p.OfType == sdk.RowType {
+			t.Errorf("rows without titles should not become row panels")
+		}
+		if p.GridPos.W == nil || *p.GridPos.W > sdk.GridColumnCount {
+			t.Errorf("panel %q has wrong width", p.Title)
+		}
+	}
+}
+
+func TestBoard_ConvertPanelsToRows(t *testing.T) {
+	board := sdk.NewBoard("Grid")
+	board.SchemaVersion = 30
+	first := sdk.NewGraph("A")
+	first.GridPos = sdk.GridPos{X: intPtr(0), Y: intPtr(0), W: intPtr(12), H: intPtr(8)}
+	second := sdk.NewGraph("B")
+	second.GridPos = sdk.GridPos{X: intPtr(12), Y: intPtr(0), W: intPtr(12), H: intPtr(6)}
+	row := sdk.NewRow("Details")
+	row.GridPos = sdk.GridPos{X: intPtr(0), Y: intPtr(8), W: intPtr(24), H: intPtr(1)}
+	row.RowPanel.Collapsed = true
+	nested := sdk.NewText("C")
+	nested.GridPos = sdk.GridPos{X: intPtr(0), Y: intPtr(9), W: intPtr(8), H: intPtr(11)}
+	row.RowPanel.Panels = append(row.RowPanel.Panels, *nested)
+	board.Panels = []*sdk.Panel{row, second, first}
+
+	board.ConvertPanelsToRows()
+
+	if board.Panels != nil {
+		t.Error("panels should be moved to rows")
+	}
+	if board.SchemaVersion >= sdk.GridLayoutSchemaVersion {
+		t.Errorf("schema version should be below %d", sdk.GridLayoutSchemaVersion)
+	}
+	if len(board.Rows) != 2 {
+		t.Fatalf("should be 2 rows but got %d", len(board.Rows))
+	}
+	if board.Rows[0].ShowTitle || len(board.Rows[0].Panels) != 2 || board.Rows[0].Panels[0].Title != "A" {
+		t.Errorf("unexpected first row %+v", board.Rows[0])
+	}
+	if board.Rows[0].Panels[0].Span != 6 || board.Rows[0].Height != "296px" {
+		t.Errorf("span should be 6 and height 296px but got %v and %s", board.Rows[0].Panels[0].Span, board.Rows[0].Height)
+	}
+	details := board.Rows[1]
+	if details.Title != "Details" || !details.ShowTitle || !details.Collapse || len(details.Panels) != 1 {
+		t.Errorf("unexpected second row %+v", details)
+	}
+	if details.Panels[0].Span != 4 || details.Panels[0].GridPos.X != nil {
+		t.Errorf("nested panel should get span 4 and no grid position")
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
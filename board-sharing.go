@@ -0,0 +1,273 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Types of dashboard inputs.
+const (
+	InputTypeDatasource = "datasource"
+	InputTypeConstant   = "constant"
+)
+
+// DashboardInput is a placeholder of the dashboard exported for sharing.
+// The dashboard refers to it as "${Name}" and the value is asked on
+// import: the datasource of PluginID type for datasource inputs or the
+// text for constant ones.
+type DashboardInput struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	PluginID    string `json:"pluginId,omitempty"`
+	PluginName  string `json:"pluginName,omitempty"`
+	Value       string `json:"value,omitempty"` // default value of constants
+}
+
+// DashboardRequirement is a plugin the dashboard exported for sharing
+// depends on. Type is "grafana", "datasource" or "panel".
+type DashboardRequirement struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// inputRe matches references to dashboard inputs in the same way as the
+// Grafana import does.
+var (
+	inputRe   = regexp.MustCompile(`\$\{(\w+)\}`)
+	nonWordRe = regexp.MustCompile(`\W`)
+)
+
+// ExportForSharing prepares the dashboard to be imported to another
+// Grafana instance in the same way as the "Export for sharing externally"
+// option of Grafana does. References to datasources from the list are
+// replaced by "${DS_NAME}" placeholders described in Inputs, constant
+// variables become "${VAR_NAME}" inputs, query variables lose their
+// current values and Requires lists used datasources and panels. The
+// dashboard ID is reset. References to the special datasources and
+// datasource variables are kept, unknown datasources are reported by
+// the error and the dashboard is not changed then.
+func (b *Board) ExportForSharing(datasources []Datasource) error {
+	var (
+		byName   = make(map[string]Datasource, len(datasources))
+		byUID    = make(map[string]Datasource, len(datasources))
+		inputs   = make(map[string]DashboardInput)
+		requires = make(map[string]DashboardRequirement)
+		unknown  = make(map[string]bool)
+		refs     = make(map[**DatasourceRef]*DatasourceRef)
+	)
+	for _, ds := range datasources {
+		byName[ds.Name] = ds
+		byUID[ds.UID] = ds
+		if ds.IsDefault {
+			byName[DefaultSource] = ds
+		}
+	}
+	b.datasourceRefs(func(ref **DatasourceRef, _ bool) {
+		r := *ref
+		if r == nil || r.IsVariable() || r.Type == "datasource" || r.UID == ExpressionDatasourceUID {
+			return
+		}
+		var (
+			ds Datasource
+			ok bool
+		)
+		if r.IsLegacy() {
+			switch r.Name {
+			case MixedSource, DashboardSource, GrafanaSource:
+				return
+			}
+			ds, ok = byName[r.Name]
+		} else {
+			ds, ok = byUID[r.UID]
+		}
+		if !ok {
+			unknown[r.String()] = true
+			return
+		}
+		name := "DS_" + inputName(ds.Name)
+		inputs[name] = DashboardInput{
+			Name:       name,
+			Label:      ds.Name,
+			Type:       InputTypeDatasource,
+			PluginID:   ds.Type,
+			PluginName: ds.Type,
+		}
+		requires[InputTypeDatasource+"/"+ds.Type] = DashboardRequirement{Type: InputTypeDatasource, ID: ds.Type, Name: ds.Type}
+		placeholder := "${" + name + "}"
+		if r.IsLegacy() {
+			refs[ref] = NewDatasourceRefByName(placeholder)
+		} else {
+			refs[ref] = NewDatasourceRef(ds.Type, placeholder)
+		}
+	})
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown datasources: %s", strings.Join(sortedKeys(unknown), ", "))
+	}
+	for ref, exported := range refs {
+		*ref = exported
+	}
+	for i := range b.Templating.List {
+		v := &b.Templating.List[i]
+		switch v.Type {
+		case VariableTypeConstant:
+			name := "VAR_" + inputName(v.Name)
+			value := fmt.Sprint(v.Query)
+			inputs[name] = DashboardInput{Name: name, Label: v.Name, Type: InputTypeConstant, Value: value}
+			placeholder := "${" + name + "}"
+			v.Query = placeholder
+			v.Current = Current{Text: &StringSliceString{Value: []string{placeholder}, Valid: true}, Value: placeholder}
+			v.Options = []Option{{Text: placeholder, Value: placeholder}}
+		case VariableTypeQuery:
+			if v.Refresh.Flag || (v.Refresh.Value != nil && *v.Refresh.Value != int64(VariableRefreshNever)) {
+				v.Current = Current{}
+				v.Options = []Option{}
+			}
+		}
+	}
+	requirePanel := func(p *Panel) {
+		if p.Type != "" && p.Type != "row" {
+			requires["panel/"+p.Type] = DashboardRequirement{Type: "panel", ID: p.Type, Name: p.Type}
+		}
+	}
+	for _, p := range b.Panels {
+		requirePanel(p)
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				requirePanel(&p.RowPanel.Panels[i])
+			}
+		}
+	}
+	for _, r := range b.Rows {
+		for i := range r.Panels {
+			requirePanel(&r.Panels[i])
+		}
+	}
+	b.Inputs = make([]DashboardInput, 0, len(inputs))
+	for _, in := range inputs {
+		b.Inputs = append(b.Inputs, in)
+	}
+	sort.Slice(b.Inputs, func(i, j int) bool { return b.Inputs[i].Name < b.Inputs[j].Name })
+	b.Requires = make([]DashboardRequirement, 0, len(requires))
+	for _, req := range requires {
+		b.Requires = append(b.Requires, req)
+	}
+	sort.Slice(b.Requires, func(i, j int) bool {
+		if b.Requires[i].Type != b.Requires[j].Type {
+			return b.Requires[i].Type < b.Requires[j].Type
+		}
+		return b.Requires[i].ID < b.Requires[j].ID
+	})
+	b.ID = 0
+	return nil
+}
+
+// ResolveInputs replaces "${NAME}" placeholders of the dashboard exported
+// for sharing with values of the inputs and removes Inputs and Requires,
+// as the Grafana import does. Values of datasource inputs are UIDs of the
+// datasources for references by UID and names for references by name.
+// Constant inputs without a value get their default ones. Missing values
+// are reported by the error and the dashboard is not changed then.
+func (b *Board) ResolveInputs(values map[string]string) error {
+	var (
+		resolved = make(map[string]string, len(b.Inputs))
+		missing  = make(map[string]bool)
+	)
+	for _, in := range b.Inputs {
+		value, ok := values[in.Name]
+		if !ok && in.Type == InputTypeConstant {
+			value, ok = in.Value, true
+		}
+		if !ok || (value == "" && in.Type == InputTypeDatasource) {
+			missing[in.Name] = true
+			continue
+		}
+		resolved[in.Name] = value
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing values of inputs: %s", strings.Join(sortedKeys(missing), ", "))
+	}
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	var board map[string]interface{}
+	if err = decodeWithNumbers(raw, &board); err != nil {
+		return err
+	}
+	delete(board, "__inputs")
+	delete(board, "__requires")
+	if raw, err = json.Marshal(replaceInputs(board, resolved)); err != nil {
+		return err
+	}
+	var result Board
+	if err = json.Unmarshal(raw, &result); err != nil {
+		return err
+	}
+	*b = result
+	b.bindRows()
+	return nil
+}
+
+// replaceInputs replaces input placeholders in all strings of the decoded
+// JSON value.
+func replaceInputs(v interface{}, values map[string]string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = replaceInputs(item, values)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = replaceInputs(item, values)
+		}
+	case string:
+		return inputRe.ReplaceAllStringFunc(val, func(ref string) string {
+			if value, ok := values[inputRe.FindStringSubmatch(ref)[1]]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+	return v
+}
+
+// inputName makes the name of the input from the datasource or variable
+// name: "Prometheus EU-1" becomes "PROMETHEUS_EU_1".
+func inputName(name string) string {
+	return strings.ToUpper(nonWordRe.ReplaceAllString(name, "_"))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
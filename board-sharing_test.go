@@ -0,0 +1,140 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func sharedBoard() *sdk.Board {
+	b := sdk.NewBoard("Shared")
+	b.ID = 42
+	b.SchemaVersion = 36
+	p := sdk.NewTimeseries("Requests")
+	p.Datasource = sdk.NewDatasourceRef("prometheus", "prom1")
+	p.AddTarget(&sdk.Target{Datasource: sdk.NewDatasourceRef("prometheus", "prom1"), Expr: `up{env="$env"}`})
+	b.AddPanel(p)
+	g := sdk.NewGraph("Legacy")
+	g.Datasource = sdk.NewDatasourceRefByName("Loki EU-1")
+	b.AddPanel(g)
+	m := sdk.NewTimeseries("Mixed")
+	m.Datasource = sdk.NewDatasourceRefByName(sdk.MixedSource)
+	m.AddTarget(&sdk.Target{Datasource: sdk.NewDatasourceRef("prometheus", "${ds}")})
+	b.AddPanel(m)
+	query := sdk.NewQueryVariable("job", sdk.NewDatasourceRef("prometheus", "prom1"), "label_values(job)")
+	query.SetRefresh(sdk.VariableRefreshOnDashboardLoad)
+	query.Options = []sdk.Option{{Text: "api", Value: "api"}}
+	_ = b.AddVariable(query)
+	_ = b.AddVariable(sdk.NewConstantVariable("env", "prod"))
+	return b
+}
+
+var sharedDatasources = []sdk.Datasource{
+	{UID: "prom1", Name: "Prometheus", Type: "prometheus"},
+	{UID: "loki1", Name: "Loki EU-1", Type: "loki"},
+}
+
+func TestBoard_ExportForSharing(t *testing.T) {
+	b := sharedBoard()
+	if err := b.ExportForSharing(sharedDatasources); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, in := range b.Inputs {
+		names = append(names, in.Name+":"+in.Type)
+	}
+	if got := strings.Join(names, ","); got != "DS_LOKI_EU_1:datasource,DS_PROMETHEUS:datasource,VAR_ENV:constant" {
+		t.Errorf("unexpected inputs %s", got)
+	}
+	if ds := b.Panels[0].Datasource; ds.UID != "${DS_PROMETHEUS}" || ds.Type != "prometheus" {
+		t.Errorf("unexpected panel datasource %+v", ds)
+	}
+	if ds := b.Panels[1].Datasource; !ds.IsLegacy() || ds.Name != "${DS_LOKI_EU_1}" {
+		t.Errorf("unexpected legacy datasource %+v", ds)
+	}
+	if ds := b.Panels[2].Datasource; ds.Name != sdk.MixedSource {
+		t.Errorf("mixed datasource should be kept, got %+v", ds)
+	}
+	if ds := (*b.Panels[2].GetTargets())[0].Datasource; ds.UID != "${ds}" {
+		t.Errorf("variable datasource should be kept, got %+v", ds)
+	}
+	if job := b.Templating.List[0]; len(job.Options) != 0 || job.Datasource.UID != "${DS_PROMETHEUS}" {
+		t.Errorf("unexpected query variable %+v", job)
+	}
+	if env := b.Templating.List[1]; env.Query != "${VAR_ENV}" {
+		t.Errorf("unexpected constant variable query %v", env.Query)
+	}
+	if b.Inputs[2].Value != "prod" {
+		t.Errorf("expected default value of the constant, got %q", b.Inputs[2].Value)
+	}
+	if b.ID != 0 {
+		t.Errorf("expected ID reset, got %d", b.ID)
+	}
+	var reqs []string
+	for _, req := range b.Requires {
+		reqs = append(reqs, req.Type+"/"+req.ID)
+	}
+	if got := strings.Join(reqs, ","); got != "datasource/loki,datasource/prometheus,panel/graph,panel/timeseries" {
+		t.Errorf("unexpected requirements %s", got)
+	}
+
+	raw, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"__inputs":[`) || !strings.Contains(string(raw), `"__requires":[`) {
+		t.Errorf("inputs are not marshalled: %s", raw)
+	}
+}
+
+func TestBoard_ExportForSharing_UnknownDatasource(t *testing.T) {
+	b := sharedBoard()
+	err := b.ExportForSharing(sharedDatasources[:1])
+	if err == nil || !strings.Contains(err.Error(), "Loki EU-1") {
+		t.Fatalf("expected unknown datasource error, got %v", err)
+	}
+	if b.Panels[0].Datasource.UID != "prom1" || len(b.Inputs) != 0 {
+		t.Error("board should not be changed on error")
+	}
+}
+
+func TestBoard_ResolveInputs(t *testing.T) {
+	b := sharedBoard()
+	if err := b.ExportForSharing(sharedDatasources); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ResolveInputs(map[string]string{"DS_PROMETHEUS": "prom2"}); err == nil || !strings.Contains(err.Error(), "DS_LOKI_EU_1") {
+		t.Fatalf("expected missing input error, got %v", err)
+	}
+	if err := b.ResolveInputs(map[string]string{"DS_PROMETHEUS": "prom2", "DS_LOKI_EU_1": "Loki"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Inputs) != 0 || len(b.Requires) != 0 {
+		t.Errorf("inputs should be removed, got %v and %v", b.Inputs, b.Requires)
+	}
+	if ds := b.Panels[0].Datasource; ds.UID != "prom2" || ds.Type != "prometheus" {
+		t.Errorf("unexpected panel datasource %+v", ds)
+	}
+	if ds := (*b.Panels[0].GetTargets())[0].Datasource; ds.UID != "prom2" {
+		t.Errorf("unexpected target datasource %+v", ds)
+	}
+	// the legacy reference is upgraded on marshalling for schema 36
+	if ds := b.Panels[1].Datasource; ds.String() != "Loki" {
+		t.Errorf("unexpected legacy datasource %+v", ds)
+	}
+	if ds := (*b.Panels[2].GetTargets())[0].Datasource; ds.UID != "${ds}" {
+		t.Errorf("variable datasource should be kept, got %+v", ds)
+	}
+	if env := b.Templating.List[1]; env.Query != "prod" {
+		t.Errorf("expected default value of the constant, got %v", env.Query)
+	}
+	raw, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "__inputs") {
+		t.Errorf("inputs are still marshalled: %s", raw)
+	}
+}
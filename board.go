@@ -23,14 +23,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"strings"
+	"sync"
 
 	"github.com/gosimple/slug"
 )
 
-var (
-	boardID uint
-)
-
 // Constants for templating
 const (
 	TemplatingHideNone = iota
@@ -65,6 +62,17 @@ type (
 		Time          Time        `json:"time"`
 		Timepicker    Timepicker  `json:"timepicker"`
 		GraphTooltip  int         `json:"graphTooltip,omitempty"`
+		// Inputs and Requires are set for dashboards exported for
+		// sharing, see ExportForSharing()
+		Inputs   []DashboardInput       `json:"__inputs,omitempty"`
+		Requires []DashboardRequirement `json:"__requires,omitempty"`
+		// Snapshot is set for snapshots, see MakeSnapshot()
+		Snapshot *SnapshotInfo `json:"snapshot,omitempty"`
+		// JSON the board was decoded from, see marshalLossless()
+		source json.RawMessage
+		// mu guards adding panels and allocation of their IDs, see
+		// lock()
+		mu *sync.Mutex
 	}
 	Time struct {
 		From string `json:"from"`
@@ -79,24 +87,38 @@ type (
 		List []TemplateVar `json:"list"`
 	}
 	TemplateVar struct {
-		Name        string      `json:"name"`
-		Type        string      `json:"type"`
-		Auto        bool        `json:"auto,omitempty"`
-		AutoCount   *int        `json:"auto_count,omitempty"`
-		Datasource  interface{} `json:"datasource"`
-		Refresh     BoolInt     `json:"refresh"`
-		Options     []Option    `json:"options"`
-		IncludeAll  bool        `json:"includeAll"`
-		AllFormat   string      `json:"allFormat"`
-		AllValue    string      `json:"allValue"`
-		Multi       bool        `json:"multi"`
-		MultiFormat string      `json:"multiFormat"`
-		Query       interface{} `json:"query"`
-		Regex       string      `json:"regex"`
-		Current     Current     `json:"current"`
-		Label       string      `json:"label"`
-		Hide        uint8       `json:"hide"`
-		Sort        int         `json:"sort"`
+		Name        string         `json:"name"`
+		Type        string         `json:"type"`
+		Auto        bool           `json:"auto,omitempty"`
+		AutoCount   *int           `json:"auto_count,omitempty"`
+		Datasource  *DatasourceRef `json:"datasource"`
+		Refresh     BoolInt        `json:"refresh"`
+		Options     []Option       `json:"options"`
+		IncludeAll  bool           `json:"includeAll"`
+		AllFormat   string         `json:"allFormat"`
+		AllValue    string         `json:"allValue"`
+		Multi       bool           `json:"multi"`
+		MultiFormat string         `json:"multiFormat"`
+		Query       interface{}    `json:"query"`
+		Regex       string         `json:"regex"`
+		Current     Current        `json:"current"`
+		Label       string         `json:"label"`
+		Hide        uint8          `json:"hide"`
+		Sort        int            `json:"sort"`
+		Definition  string         `json:"definition,omitempty"`  // from grafana 7.x
+		SkipURLSync bool           `json:"skipUrlSync,omitempty"` // from grafana 7.x
+		Description *string        `json:"description,omitempty"`
+		AutoMin     string         `json:"auto_min,omitempty"` // for interval
+		Filters     []AdhocFilter  `json:"filters,omitempty"`  // for adhoc
+		// JSON the variable was decoded from, see marshalLossless()
+		source json.RawMessage
+	}
+	// for templateVar of adhoc type
+	AdhocFilter struct {
+		Key       string `json:"key"`
+		Operator  string `json:"operator"`
+		Value     string `json:"value"`
+		Condition string `json:"condition,omitempty"`
 	}
 	// for templateVar
 	Option struct {
@@ -111,23 +133,23 @@ type (
 		Value interface{}        `json:"value"` // TODO select more precise type
 	}
 	Annotation struct {
-		Name        string      `json:"name"`
-		Datasource  interface{} `json:"datasource"`
-		ShowLine    bool        `json:"showLine"`
-		IconColor   string      `json:"iconColor"`
-		LineColor   string      `json:"lineColor"`
-		IconSize    uint        `json:"iconSize"`
-		Enable      bool        `json:"enable"`
-		Query       string      `json:"query"`
-		Expr        string      `json:"expr"`
-		Step        string      `json:"step"`
-		TextField   string      `json:"textField"`
-		TextFormat  string      `json:"textFormat"`
-		TitleFormat string      `json:"titleFormat"`
-		TagsField   string      `json:"tagsField"`
-		Tags        []string    `json:"tags"`
-		TagKeys     string      `json:"tagKeys"`
-		Type        string      `json:"type"`
+		Name        string         `json:"name"`
+		Datasource  *DatasourceRef `json:"datasource"`
+		ShowLine    bool           `json:"showLine"`
+		IconColor   string         `json:"iconColor"`
+		LineColor   string         `json:"lineColor"`
+		IconSize    uint           `json:"iconSize"`
+		Enable      bool           `json:"enable"`
+		Query       string         `json:"query"`
+		Expr        string         `json:"expr"`
+		Step        string         `json:"step"`
+		TextField   string         `json:"textField"`
+		TextFormat  string         `json:"textFormat"`
+		TitleFormat string         `json:"titleFormat"`
+		TagsField   string         `json:"tagsField"`
+		Tags        []string       `json:"tags"`
+		TagKeys     string         `json:"tagKeys"`
+		Type        string         `json:"type"`
 	}
 	// Link represents link to another dashboard or external weblink
 	Link struct {
@@ -166,10 +188,53 @@ func (h *Height) UnmarshalJSON(raw []byte) error {
 	return err
 }
 
+// UnmarshalJSON decodes the board and keeps the source JSON so keys
+// unknown to Board are not lost when the board is marshalled back.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	type plain Board
+	var board plain
+	if err := json.Unmarshal(data, &board); err != nil {
+		return err
+	}
+	*b = Board(board)
+	b.source = keepSource(data)
+	b.bindRows()
+	return nil
+}
+
+// MarshalJSON marshals the board with keys of its source JSON preserved.
+func (b Board) MarshalJSON() ([]byte, error) {
+	type plain Board
+	raw, err := marshalLossless(plain(b), b.source)
+	if err != nil || !b.hasLegacyDatasourceRefs() {
+		return raw, err
+	}
+	return upgradeDatasourceRefsJSON(raw, b.SchemaVersion)
+}
+
+// UnmarshalJSON decodes the variable and keeps the source JSON so keys
+// unknown to TemplateVar are not lost when it is marshalled back.
+func (v *TemplateVar) UnmarshalJSON(data []byte) error {
+	type plain TemplateVar
+	var tv plain
+	if err := json.Unmarshal(data, &tv); err != nil {
+		return err
+	}
+	*v = TemplateVar(tv)
+	v.source = keepSource(data)
+	return nil
+}
+
+// MarshalJSON marshals the variable with keys of its source JSON preserved.
+func (v TemplateVar) MarshalJSON() ([]byte, error) {
+	type plain TemplateVar
+	return marshalLossless(plain(v), v.source)
+}
+
+// NewBoard initializes a board. Its ID is left empty to be assigned by
+// Grafana on save.
 func NewBoard(title string) *Board {
-	boardID++
 	return &Board{
-		ID:           boardID,
 		Title:        title,
 		Style:        "dark",
 		Timezone:     "browser",
@@ -220,6 +285,8 @@ func (b *Board) HasTag(tag string) bool {
 	return false
 }
 
+// AddRow appends the legacy row to the board. Panels added to the row get
+// IDs unique within the board.
 func (b *Board) AddRow(title string) *Row {
 	if title == "" {
 		title = "New row"
@@ -230,10 +297,134 @@ func (b *Board) AddRow(title string) *Row {
 		Editable: true,
 		Height:   "250px",
 	}
+	defer b.lock()()
+	row.board = b
 	b.Rows = append(b.Rows, row)
 	return row
 }
 
+// bindRows binds the rows to the board so their panels get IDs unique
+// within the board.
+func (b *Board) bindRows() {
+	for _, row := range b.Rows {
+		if row != nil {
+			row.board = b
+		}
+	}
+}
+
+// AddRowPanel appends the panel to the legacy row of the board and sets
+// its ID to the next ID not used by other panels of the board. It is safe
+// to add panels to the same board from several goroutines.
+func (b *Board) AddRowPanel(row *Row, panel *Panel) {
+	defer b.lock()()
+	panel.ID = b.nextPanelID()
+	row.Panels = append(row.Panels, *panel)
+}
+
+// AddPanel appends the panel to the board panels and sets its ID to the
+// next ID not used by other panels of the board. It is safe to add panels
+// to the same board from several goroutines.
+func (b *Board) AddPanel(panel *Panel) {
+	defer b.lock()()
+	panel.ID = b.nextPanelID()
+	b.Panels = append(b.Panels, panel)
+}
+
+// NextPanelID returns the ID following the maximum ID of panels of the
+// board including panels nested into rows.
+func (b *Board) NextPanelID() uint {
+	defer b.lock()()
+	return b.nextPanelID()
+}
+
+// boardMuInit guards the lazy creation of mutexes of the boards, boards
+// are often made as literals or decoded so there is no constructor to
+// create it.
+var boardMuInit sync.Mutex
+
+// lock locks the board and returns the function unlocking it. Copies of
+// the board share the mutex that is harmless as they only wait for each
+// other.
+func (b *Board) lock() func() {
+	boardMuInit.Lock()
+	if b.mu == nil {
+		b.mu = new(sync.Mutex)
+	}
+	mu := b.mu
+	boardMuInit.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (b *Board) nextPanelID() uint {
+	var max uint
+	for _, p := range b.Panels {
+		if p == nil {
+			continue
+		}
+		if p.ID > max {
+			max = p.ID
+		}
+		if p.RowPanel != nil {
+			if id := maxPanelID(p.RowPanel.Panels); id > max {
+				max = id
+			}
+		}
+	}
+	for _, row := range b.Rows {
+		if id := maxPanelID(row.Panels); id > max {
+			max = id
+		}
+	}
+	return max + 1
+}
+
+// RenumberPanels sets IDs of all panels of the board to the sequence
+// starting from 1 in the order panels are listed: panels (each row panel
+// followed by its nested panels) and then panels of legacy rows.
+// References to repeated panels are updated to the new IDs.
+func (b *Board) RenumberPanels() {
+	defer b.lock()()
+	var (
+		next   uint
+		newIDs = make(map[uint]uint)
+		all    []*CommonPanel
+	)
+	renumber := func(p *CommonPanel) {
+		next++
+		if _, ok := newIDs[p.ID]; !ok {
+			newIDs[p.ID] = next
+		}
+		p.ID = next
+		all = append(all, p)
+	}
+	for _, p := range b.Panels {
+		if p == nil {
+			continue
+		}
+		renumber(&p.CommonPanel)
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				renumber(&p.RowPanel.Panels[i].CommonPanel)
+			}
+		}
+	}
+	for _, row := range b.Rows {
+		for i := range row.Panels {
+			renumber(&row.Panels[i].CommonPanel)
+		}
+	}
+	for _, p := range all {
+		if p.RepeatPanelID == nil {
+			continue
+		}
+		if id, ok := newIDs[*p.RepeatPanelID]; ok {
+			p.RepeatPanelID = &id
+		}
+	}
+}
+
 func (b *Board) UpdateSlug() string {
 	b.Slug = strings.ToLower(slug.Make(b.Title))
 	return b.Slug
@@ -20,6 +20,8 @@ package sdk_test
 */
 
 import (
+	"encoding/json"
+	"sync"
 	"testing"
 
 	"github.com/grafana-tools/sdk"
@@ -103,3 +105,124 @@ func TestBoardAddLink(t *testing.T) {
 		t.Error("Link wasn't added")
   }
 }
+
+func TestBoardAddPanel_NextFreeID(t *testing.T) {
+	b := sdk.NewBoard("Sample")
+	row := sdk.NewRow("")
+	nested := sdk.NewText("nested")
+	nested.ID = 7
+	row.ID = 3
+	row.RowPanel.Panels = append(row.RowPanel.Panels, *nested)
+	b.Panels = append(b.Panels, row)
+
+	graph := sdk.NewGraph("")
+	b.AddPanel(graph)
+
+	if graph.ID != 8 {
+		t.Errorf("panel ID should be 8 but got %d", graph.ID)
+	}
+	if len(b.Panels) != 2 {
+		t.Errorf("panel should be added to the board")
+	}
+}
+
+func TestBoardAddRow_IDsPerBoard(t *testing.T) {
+	build := func() *sdk.Board {
+		b := sdk.NewBoard("Sample")
+		b.AddRow("first").Add(sdk.NewGraph(""))
+		r := b.AddRow("second")
+		r.Add(sdk.NewGraph(""))
+		r.AddText(&sdk.TextPanel{})
+		return b
+	}
+	b1, b2 := build(), build()
+	for i, row := range b1.Rows {
+		for j, p := range row.Panels {
+			if p.ID != b2.Rows[i].Panels[j].ID {
+				t.Errorf("IDs should not depend on other boards: %d != %d", p.ID, b2.Rows[i].Panels[j].ID)
+			}
+		}
+	}
+	if id := b1.Rows[1].Panels[1].ID; id != 3 {
+		t.Errorf("last panel ID should be 3 but got %d", id)
+	}
+}
+
+func TestBoardAddRow_DecodedRowsKeepBoardIDs(t *testing.T) {
+	var b sdk.Board
+	if err := json.Unmarshal([]byte(`{"rows": [{"panels": [{"id": 4, "type": "graph"}]}, {"panels": [{"id": 7, "type": "text"}]}]}`), &b); err != nil {
+		t.Fatal(err)
+	}
+	b.Rows[0].Add(sdk.NewGraph(""))
+	if id := b.Rows[0].Panels[1].ID; id != 8 {
+		t.Errorf("panel ID should follow IDs of other rows, expected 8 but got %d", id)
+	}
+}
+
+func TestBoardAddPanel_Concurrent(t *testing.T) {
+	const workers, panels = 8, 50
+	shared := sdk.NewBoard("Shared")
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			own := sdk.NewBoard("Own")
+			row := own.AddRow("")
+			sharedRow := shared.AddRow("")
+			for i := 0; i < panels; i++ {
+				shared.AddPanel(sdk.NewGraph(""))
+				sharedRow.Add(sdk.NewGraph(""))
+				row.Add(sdk.NewGraph(""))
+			}
+			if id := row.Panels[panels-1].ID; id != panels {
+				t.Errorf("last panel ID of the own board should be %d but got %d", panels, id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids := make(map[uint]bool)
+	check := func(id uint) {
+		if ids[id] {
+			t.Fatalf("panel ID %d is duplicated", id)
+		}
+		ids[id] = true
+	}
+	for _, p := range shared.Panels {
+		check(p.ID)
+	}
+	for _, row := range shared.Rows {
+		for _, p := range row.Panels {
+			check(p.ID)
+		}
+	}
+	if len(ids) != 2*workers*panels {
+		t.Errorf("should be %d panels but got %d", 2*workers*panels, len(ids))
+	}
+}
+
+func TestBoardRenumberPanels(t *testing.T) {
+	b := sdk.NewBoard("Sample")
+	first := sdk.NewGraph("")
+	first.ID = 10
+	repeated := sdk.NewGraph("")
+	repeated.ID = 42
+	repeatOf := uint(10)
+	repeated.RepeatPanelID = &repeatOf
+	row := sdk.NewRow("")
+	row.ID = 5
+	nested := sdk.NewText("")
+	nested.ID = 10
+	row.RowPanel.Panels = append(row.RowPanel.Panels, *nested)
+	b.Panels = []*sdk.Panel{first, repeated, row}
+
+	b.RenumberPanels()
+
+	if first.ID != 1 || repeated.ID != 2 || row.ID != 3 || row.RowPanel.Panels[0].ID != 4 {
+		t.Errorf("unexpected IDs %d %d %d %d", first.ID, repeated.ID, row.ID, row.RowPanel.Panels[0].ID)
+	}
+	if *repeated.RepeatPanelID != 1 {
+		t.Errorf("repeat panel ID should be 1 but got %d", *repeated.RepeatPanelID)
+	}
+}
@@ -3,7 +3,12 @@
 // It really useful for Grafana backups!
 //
 // Usage:
-//   backup-dashboards http://grafana.host:3000 api-key-string-here
+//   backup-dashboards http://grafana.host:3000 api-key-string-here [provisioning-dir [grafana-path]]
+//
+// If provisioning-dir is set the dashboards are also written there as
+// the tree for Grafana file provisioning with folders kept. Grafana-path
+// is the path Grafana reads the dashboards directory of the tree from,
+// the absolute path of provisioning-dir/dashboards by default.
 package main
 
 /*
@@ -27,6 +32,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -36,13 +42,14 @@ import (
 
 func main() {
 	var (
-		boardLinks []sdk.FoundBoard
-		rawBoard   []byte
-		meta       sdk.BoardProperties
-		err        error
+		boardLinks  []sdk.FoundBoard
+		rawBoard    []byte
+		meta        sdk.BoardProperties
+		provisioned []sdk.ProvisionedBoard
+		err         error
 	)
-	if len(os.Args) != 3 {
-		fmt.Fprint(os.Stderr, "Usage:  backup-dashboards http://grafana.host:3000 api-key-string-here\n")
+	if len(os.Args) < 3 || len(os.Args) > 5 {
+		fmt.Fprint(os.Stderr, "Usage:  backup-dashboards http://grafana.host:3000 api-key-string-here [provisioning-dir [grafana-path]]\n")
 		os.Exit(0)
 	}
 	ctx := context.Background()
@@ -63,5 +70,23 @@ func main() {
 		if err = ioutil.WriteFile(fmt.Sprintf("%s.json", meta.Slug), rawBoard, os.FileMode(int(0666))); err != nil {
 			fmt.Fprintf(os.Stderr, "%s for %s\n", err, meta.Slug)
 		}
+		if len(os.Args) > 3 {
+			var board sdk.Board
+			if err = json.Unmarshal(rawBoard, &board); err != nil {
+				fmt.Fprintf(os.Stderr, "%s for %s\n", err, meta.Slug)
+				continue
+			}
+			provisioned = append(provisioned, sdk.ProvisionedBoard{Board: &board, FolderTitle: link.FolderTitle, FolderUID: link.FolderUID})
+		}
+	}
+	if len(os.Args) > 3 {
+		provider := sdk.DashboardProvider{Name: "backup"}
+		if len(os.Args) == 5 {
+			provider.Options.Path = os.Args[4]
+		}
+		if _, err = sdk.WriteDashboardProvisioning(os.Args[3], provider, provisioned); err != nil {
+			fmt.Fprint(os.Stderr, err)
+			os.Exit(1)
+		}
 	}
 }
@@ -31,6 +31,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/gosimple/slug"
 	"github.com/grafana-tools/sdk"
@@ -38,10 +39,11 @@ import (
 
 func main() {
 	var (
-		datasources []sdk.Datasource
-		dsPacked    []byte
-		meta        sdk.BoardProperties
-		err         error
+		datasources  []sdk.Datasource
+		dsPacked     []byte
+		meta         sdk.BoardProperties
+		provisioning *sdk.DatasourceProvisioning
+		err          error
 	)
 	if len(os.Args) != 3 {
 		fmt.Fprint(os.Stderr, "Usage:  backup-datasources http://sdk.host:3000 api-key-string-here\n")
@@ -66,4 +68,20 @@ func main() {
 			fmt.Fprintf(os.Stderr, "%s for %s\n", err, meta.Slug)
 		}
 	}
+	// The same datasources for file provisioning, secrets refer to
+	// environment variables.
+	if provisioning, err = sdk.NewDatasourceProvisioning(datasources); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	if dsPacked, err = provisioning.YAML(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile("datasources.yaml", dsPacked, os.FileMode(int(0666))); err != nil {
+		fmt.Fprintf(os.Stderr, "%s for datasources.yaml\n", err)
+	}
+	if env := provisioning.SecretEnv(); len(env) > 0 {
+		fmt.Fprintf(os.Stderr, "datasources.yaml refers to secrets in environment variables: %s\n", strings.Join(env, ", "))
+	}
 }
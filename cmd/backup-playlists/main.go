@@ -0,0 +1,76 @@
+// This is a simple example of usage of Grafana client
+// for copying playlists with their items and saving them to a disk
+// as playlist-<name>.json files.
+// Restore them with import-playlists utility.
+//
+// Usage:
+//
+//	backup-playlists http://sdk.host:3000 api-key-string-here
+package main
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gosimple/slug"
+	"github.com/grafana-tools/sdk"
+)
+
+func main() {
+	var (
+		playlists []sdk.Playlist
+		packed    []byte
+		err       error
+	)
+	if len(os.Args) != 3 {
+		fmt.Fprint(os.Stderr, "Usage:  backup-playlists http://sdk.host:3000 api-key-string-here\n")
+		os.Exit(0)
+	}
+	ctx := context.Background()
+	c, err := sdk.NewClient(os.Args[1], os.Args[2], sdk.DefaultHTTPClient)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create a client: %s\n", err)
+		os.Exit(1)
+	}
+	if playlists, err = c.GetAllPlaylists(ctx); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, link := range playlists {
+		// the listing has no items
+		playlist, err := c.GetPlaylist(ctx, link.UID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s for %s\n", err, link.Name)
+			continue
+		}
+		if packed, err = json.MarshalIndent(playlist, "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "%s for %s\n", err, playlist.Name)
+			continue
+		}
+		if err = ioutil.WriteFile(fmt.Sprintf("playlist-%s.json", slug.Make(playlist.Name)), packed, os.FileMode(int(0666))); err != nil {
+			fmt.Fprintf(os.Stderr, "%s for %s\n", err, playlist.Name)
+		}
+	}
+}
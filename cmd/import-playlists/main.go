@@ -0,0 +1,102 @@
+// This is a simple example of usage of Grafana client
+// for importing playlists from a bunch of playlist-*.json files (current
+// dir used). Files without name or items of playlist are skipped.
+// You are can export playlists with backup-playlists utility.
+// NOTE: playlists with same UIDs (or names for files without UIDs)
+// will be silently replaced!
+//
+// Usage:
+//
+//	import-playlists http://sdk.host:3000 api-key-string-here
+package main
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func main() {
+	var (
+		playlists  []sdk.Playlist
+		filesInDir []os.FileInfo
+		raw        []byte
+		err        error
+	)
+	if len(os.Args) != 3 {
+		fmt.Fprint(os.Stderr, "Usage:  import-playlists http://sdk.host:3000 api-key-string-here\n")
+		os.Exit(0)
+	}
+	ctx := context.Background()
+	c, err := sdk.NewClient(os.Args[1], os.Args[2], sdk.DefaultHTTPClient)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create a client: %s\n", err)
+		os.Exit(1)
+	}
+	if playlists, err = c.GetAllPlaylists(ctx); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	if filesInDir, err = ioutil.ReadDir("."); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, file := range filesInDir {
+		if !strings.HasPrefix(file.Name(), "playlist-") || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		if raw, err = ioutil.ReadFile(file.Name()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			continue
+		}
+		var playlist sdk.Playlist
+		if err = json.Unmarshal(raw, &playlist); err != nil {
+			fmt.Fprintf(os.Stderr, "%s for %s\n", err, file.Name())
+			continue
+		}
+		if playlist.Name == "" || len(playlist.Items) == 0 {
+			fmt.Fprintf(os.Stderr, "%s is not a playlist, skipped\n", file.Name())
+			continue
+		}
+		var exists bool
+		for _, existing := range playlists {
+			if (playlist.UID != "" && existing.UID == playlist.UID) || (playlist.UID == "" && existing.Name == playlist.Name) {
+				playlist.UID = existing.UID
+				exists = true
+				break
+			}
+		}
+		if exists {
+			_, err = c.UpdatePlaylist(ctx, playlist)
+		} else {
+			_, err = c.CreatePlaylist(ctx, playlist)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error on importing playlist %s with %s\n", playlist.Name, err)
+		}
+	}
+}
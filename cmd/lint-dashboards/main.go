@@ -0,0 +1,166 @@
+// This is a simple example of usage of the lint package for checking
+// dashboards saved as JSON files or dashboards of a live Grafana instance.
+// It exits with non-zero code if problems of the failing severity found.
+//
+// Usage:
+//
+//	lint-dashboards [-severity rule=level,...] [-fail-on level] dashboard.json...
+//	lint-dashboards [-severity rule=level,...] [-fail-on level] -url http://grafana.host:3000 -key api-key-string-here
+package main
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+	"github.com/grafana-tools/sdk/lint"
+)
+
+func main() {
+	var (
+		url       = flag.String("url", "", "Grafana URL for checking dashboards of a live instance")
+		key       = flag.String("key", "", "API key or user:password for the Grafana URL")
+		severity  = flag.String("severity", "", "comma separated rule severities, e.g. panel-unit=off,legacy-panel=warning")
+		failOn    = flag.String("fail-on", "error", "exit with code 1 if problems of this severity or higher found")
+		listRules = flag.Bool("rules", false, "list the rules and exit")
+	)
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage:  lint-dashboards [flags] dashboard.json...\n        lint-dashboards [flags] -url http://grafana.host:3000 -key api-key-string-here\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	linter := lint.Default()
+	if err := configure(linter, *severity); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *listRules {
+		for _, r := range linter.Rules() {
+			fmt.Printf("%-22s %-8s %s\n", r.Name, r.Severity, r.Description)
+		}
+		return
+	}
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *url == "" && flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	max := lint.SeverityOff
+	report := func(name string, board *sdk.Board) {
+		problems := linter.Lint(board)
+		for _, p := range problems {
+			fmt.Printf("%s: %s\n", name, p)
+		}
+		if s := lint.MaxSeverity(problems); s > max {
+			max = s
+		}
+	}
+	for _, path := range flag.Args() {
+		board, err := readBoard(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			os.Exit(2)
+		}
+		report(path, board)
+	}
+	if *url != "" {
+		if err := lintInstance(*url, *key, report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if max != lint.SeverityOff && max >= threshold {
+		os.Exit(1)
+	}
+}
+
+// configure applies severities in form "rule=level,rule=level".
+func configure(linter *lint.Linter, severities string) error {
+	for _, item := range strings.Split(severities, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("bad severity %q, expected rule=level", item)
+		}
+		level, err := lint.ParseSeverity(parts[1])
+		if err != nil {
+			return err
+		}
+		if err = linter.SetSeverity(parts[0], level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readBoard(path string) (*sdk.Board, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// dashboards exported with metadata keep the board in "dashboard" key
+	var wrapped struct {
+		Dashboard json.RawMessage `json:"dashboard"`
+	}
+	if err = json.Unmarshal(raw, &wrapped); err == nil && len(wrapped.Dashboard) > 0 {
+		raw = wrapped.Dashboard
+	}
+	var board sdk.Board
+	if err = json.Unmarshal(raw, &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+func lintInstance(url, key string, report func(string, *sdk.Board)) error {
+	ctx := context.Background()
+	c, err := sdk.NewClient(url, key, sdk.DefaultHTTPClient)
+	if err != nil {
+		return fmt.Errorf("failed to create a client: %s", err)
+	}
+	links, err := c.SearchDashboards(ctx, "", false)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		board, _, err := c.GetDashboardByUID(ctx, link.UID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s for %s\n", err, link.URI)
+			continue
+		}
+		report(link.Title, &board)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"gopkg.in/yaml.v3"
+)
+
+// Dashboards provisioned by Grafana from files are described with the
+// provider config, see
+// https://grafana.com/docs/grafana/latest/administration/provisioning/#dashboards
+// WriteDashboardProvisioning() makes the tree of the provider config and
+// the dashboard files:
+//
+//   dir/dashboards.yaml       providers of the dashboards
+//   dir/dashboards/...        JSON files of the dashboards
+//
+// The providers refer to the dashboards by the path where Grafana reads
+// dir/dashboards from, e.g. the volume mounted to the container.
+
+// DashboardsYAML and DashboardsDir are the names of the provider config
+// and the directory of the dashboards in the provisioning tree.
+const (
+	DashboardsYAML = "dashboards.yaml"
+	DashboardsDir  = "dashboards"
+)
+
+// generalFolderDir keeps the dashboards of the General folder when the
+// folders are not made from the files structure. Grafana reserves the
+// name so it can't clash with a real folder.
+const generalFolderDir = "General"
+
+// DashboardProvisioning is the provider config of the provisioned
+// dashboards.
+type DashboardProvisioning struct {
+	APIVersion int                 `yaml:"apiVersion"`
+	Providers  []DashboardProvider `yaml:"providers"`
+}
+
+// DashboardProvider provisions the dashboards from the files of the
+// Options.Path directory and its subdirectories. With
+// FoldersFromFilesStructure dashboards are put into the folders named
+// after their directories, otherwise all of them are put into Folder.
+type DashboardProvider struct {
+	Name                  string                   `yaml:"name"`
+	OrgID                 uint                     `yaml:"orgId,omitempty"`
+	Folder                string                   `yaml:"folder,omitempty"`
+	FolderUID             string                   `yaml:"folderUid,omitempty"`
+	Type                  string                   `yaml:"type"`
+	DisableDeletion       bool                     `yaml:"disableDeletion,omitempty"`
+	UpdateIntervalSeconds int                      `yaml:"updateIntervalSeconds,omitempty"`
+	AllowUIUpdates        bool                     `yaml:"allowUiUpdates,omitempty"`
+	Options               DashboardProviderOptions `yaml:"options"`
+}
+
+// DashboardProviderOptions are the options of the file provider.
+type DashboardProviderOptions struct {
+	Path                      string `yaml:"path"`
+	FoldersFromFilesStructure bool   `yaml:"foldersFromFilesStructure,omitempty"`
+}
+
+// ProvisionedBoard is the dashboard of the provisioning tree with its
+// folder. Empty FolderTitle means the General folder. Path and Provider
+// are set on reading the tree: the path of the file relative to the
+// dashboards directory and the name of the provider it belongs to.
+type ProvisionedBoard struct {
+	Board       *Board
+	FolderTitle string
+	FolderUID   string
+	Path        string
+	Provider    string
+}
+
+// WriteDashboardProvisioning writes the boards with the provider config
+// to the provisioning tree in dir. Options.Path of the provider is the
+// path Grafana reads the dashboards directory from, the absolute path of
+// dir/dashboards is used if it is empty.
+//
+// If the provider makes folders from the files structure the boards are
+// written to the directories named after their folders and boards of the
+// General folder to the root of the dashboards directory. Grafana creates
+// these folders by their titles, so FolderUID of the boards is not kept.
+// Otherwise the provider is repeated for each folder with its own
+// subdirectory and the boards keep both folder titles and UIDs.
+//
+// IDs of the boards are not written as Grafana assigns its own ones.
+func WriteDashboardProvisioning(dir string, provider DashboardProvider, boards []ProvisionedBoard) (*DashboardProvisioning, error) {
+	dashboardsDir := filepath.Join(dir, DashboardsDir)
+	if provider.Options.Path == "" {
+		abs, err := filepath.Abs(dashboardsDir)
+		if err != nil {
+			return nil, err
+		}
+		provider.Options.Path = filepath.ToSlash(abs)
+	}
+	if provider.Type == "" {
+		provider.Type = "file"
+	}
+	if provider.Name == "" {
+		provider.Name = "default"
+	}
+	p := &DashboardProvisioning{APIVersion: 1}
+	if provider.Options.FoldersFromFilesStructure {
+		provider.Folder, provider.FolderUID = "", ""
+		p.Providers = append(p.Providers, provider)
+	}
+	var (
+		providers = make(map[string]bool)
+		files     = make(map[string]bool)
+	)
+	for _, pb := range boards {
+		if pb.Board == nil {
+			continue
+		}
+		folderDir := folderDirName(pb.FolderTitle)
+		if !provider.Options.FoldersFromFilesStructure {
+			if folderDir == "" {
+				folderDir = generalFolderDir
+			}
+			if !providers[folderDir] {
+				providers[folderDir] = true
+				fp := provider
+				fp.Folder, fp.FolderUID = pb.FolderTitle, pb.FolderUID
+				if folderDir != generalFolderDir {
+					fp.Name = provider.Name + "-" + strings.ToLower(slug.Make(pb.FolderTitle))
+				}
+				fp.Options.Path = path.Join(provider.Options.Path, folderDir)
+				p.Providers = append(p.Providers, fp)
+			}
+		}
+		name := boardFileName(pb.Board, folderDir, files)
+		board := *pb.Board
+		board.ID = 0
+		raw, err := json.MarshalIndent(board, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("board %q: %w", pb.Board.Title, err)
+		}
+		file := filepath.Join(dashboardsDir, folderDir, name)
+		if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, err
+		}
+		if err = ioutil.WriteFile(file, raw, 0644); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(p.Providers, func(i, j int) bool { return p.Providers[i].Name < p.Providers[j].Name })
+	raw, err := marshalYAML(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return p, ioutil.WriteFile(filepath.Join(dir, DashboardsYAML), raw, 0644)
+}
+
+// ReadDashboardProvisioning reads the provisioning tree in dir back into
+// the boards with their folders. Files of each provider are read from
+// its Options.Path if it exists locally or else from the directory of
+// the tree in the same way as WriteDashboardProvisioning() lays them out.
+// Folders of the boards are resolved as Grafana does: by the provider
+// folder or, with folders from the files structure, by the name of the
+// directory of the file.
+func ReadDashboardProvisioning(dir string) (*DashboardProvisioning, []ProvisionedBoard, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, DashboardsYAML))
+	if err != nil {
+		return nil, nil, err
+	}
+	var p DashboardProvisioning
+	if err = yaml.Unmarshal(raw, &p); err != nil {
+		return nil, nil, err
+	}
+	if p.APIVersion != 1 {
+		return nil, nil, fmt.Errorf("unsupported apiVersion %d of dashboard provisioning", p.APIVersion)
+	}
+	var boards []ProvisionedBoard
+	for _, provider := range p.Providers {
+		if provider.Type != "" && provider.Type != "file" {
+			continue
+		}
+		root := filepath.FromSlash(provider.Options.Path)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			root = filepath.Join(dir, DashboardsDir)
+			if !provider.Options.FoldersFromFilesStructure {
+				root = filepath.Join(root, path.Base(provider.Options.Path))
+			}
+		}
+		err = filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(file, ".json") {
+				return err
+			}
+			raw, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var board Board
+			if err = json.Unmarshal(raw, &board); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				return err
+			}
+			pb := ProvisionedBoard{Board: &board, FolderTitle: provider.Folder, FolderUID: provider.FolderUID, Path: filepath.ToSlash(rel), Provider: provider.Name}
+			if provider.Options.FoldersFromFilesStructure {
+				if folder := filepath.Dir(rel); folder != "." {
+					pb.FolderTitle, pb.FolderUID = filepath.Base(folder), ""
+				}
+			}
+			boards = append(boards, pb)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return &p, boards, nil
+}
+
+// folderDirName makes the directory name of the folder title. Grafana
+// takes the folder title from the directory name so only the characters
+// not allowed in file names are replaced.
+func folderDirName(title string) string {
+	name := strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-").Replace(title))
+	if name == "." || name == ".." {
+		return "_" + name
+	}
+	return name
+}
+
+// boardFileName returns the unique name of the board file in the folder
+// directory: the slug of the title with the UID added on clash.
+func boardFileName(b *Board, folderDir string, files map[string]bool) string {
+	base := strings.ToLower(slug.Make(b.Title))
+	if base == "" {
+		base = "dashboard"
+	}
+	name := base + ".json"
+	for i := 1; files[path.Join(folderDir, name)]; i++ {
+		switch {
+		case i == 1 && b.UID != "":
+			name = fmt.Sprintf("%s-%s.json", base, b.UID)
+		default:
+			name = fmt.Sprintf("%s-%d.json", base, i)
+		}
+	}
+	files[path.Join(folderDir, name)] = true
+	return name
+}
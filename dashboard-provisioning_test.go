@@ -0,0 +1,172 @@
+package sdk_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func provisionedSample() []sdk.ProvisionedBoard {
+	home := sdk.NewBoard("Home")
+	home.ID = 1
+	home.UID = "home"
+	nodes := sdk.NewBoard("Nodes")
+	nodes.ID = 2
+	nodes.UID = "nodes"
+	nodesCopy := sdk.NewBoard("Nodes")
+	nodesCopy.UID = "nodes-copy"
+	return []sdk.ProvisionedBoard{
+		{Board: home},
+		{Board: nodes, FolderTitle: "Infra/Linux", FolderUID: "infra"},
+		{Board: nodesCopy, FolderTitle: "Infra/Linux", FolderUID: "infra"},
+	}
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, file)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestDashboardProvisioning_FoldersFromFilesStructure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provisioning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	provider := sdk.DashboardProvider{Name: "sdk", Options: sdk.DashboardProviderOptions{Path: "/var/lib/grafana/dashboards", FoldersFromFilesStructure: true}}
+	if _, err = sdk.WriteDashboardProvisioning(dir, provider, provisionedSample()); err != nil {
+		t.Fatal(err)
+	}
+	exp := "dashboards.yaml,dashboards/Infra-Linux/nodes-nodes-copy.json,dashboards/Infra-Linux/nodes.json,dashboards/home.json"
+	if files := strings.Join(listFiles(t, dir), ","); files != exp {
+		t.Errorf("expected files %s, got %s", exp, files)
+	}
+	raw, _ := ioutil.ReadFile(filepath.Join(dir, "dashboards.yaml"))
+	expYAML := `apiVersion: 1
+providers:
+  - name: sdk
+    type: file
+    options:
+      path: /var/lib/grafana/dashboards
+      foldersFromFilesStructure: true
+`
+	if string(raw) != expYAML {
+		t.Errorf("expected\n%s\ngot\n%s", expYAML, raw)
+	}
+	raw, _ = ioutil.ReadFile(filepath.Join(dir, "dashboards", "home.json"))
+	if strings.Contains(string(raw), `"id"`) {
+		t.Errorf("board ID should not be written:\n%s", raw)
+	}
+
+	p, boards, err := sdk.ReadDashboardProvisioning(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Providers) != 1 || len(boards) != 3 {
+		t.Fatalf("unexpected providers %+v and boards %+v", p.Providers, boards)
+	}
+	for _, pb := range boards {
+		folder := ""
+		if pb.Board.UID != "home" {
+			folder = "Infra-Linux"
+		}
+		if pb.FolderTitle != folder || pb.Provider != "sdk" {
+			t.Errorf("unexpected folder %q of %s", pb.FolderTitle, pb.Path)
+		}
+	}
+}
+
+func TestDashboardProvisioning_ProviderPerFolder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provisioning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	provider := sdk.DashboardProvider{Name: "sdk", OrgID: 1, AllowUIUpdates: true, Options: sdk.DashboardProviderOptions{Path: "/var/lib/grafana/dashboards"}}
+	p, err := sdk.WriteDashboardProvisioning(dir, provider, provisionedSample())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Providers) != 2 {
+		t.Fatalf("expected a provider per folder, got %+v", p.Providers)
+	}
+	raw, _ := ioutil.ReadFile(filepath.Join(dir, "dashboards.yaml"))
+	expYAML := `apiVersion: 1
+providers:
+  - name: sdk
+    orgId: 1
+    type: file
+    allowUiUpdates: true
+    options:
+      path: /var/lib/grafana/dashboards/General
+  - name: sdk-infra-linux
+    orgId: 1
+    folder: Infra/Linux
+    folderUid: infra
+    type: file
+    allowUiUpdates: true
+    options:
+      path: /var/lib/grafana/dashboards/Infra-Linux
+`
+	if string(raw) != expYAML {
+		t.Errorf("expected\n%s\ngot\n%s", expYAML, raw)
+	}
+
+	_, boards, err := sdk.ReadDashboardProvisioning(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 3 {
+		t.Fatalf("expected 3 boards, got %d", len(boards))
+	}
+	for _, pb := range boards {
+		if pb.Board.UID == "home" {
+			if pb.FolderTitle != "" || pb.Provider != "sdk" {
+				t.Errorf("unexpected general board %+v", pb)
+			}
+			continue
+		}
+		if pb.FolderTitle != "Infra/Linux" || pb.FolderUID != "infra" || pb.Provider != "sdk-infra-linux" || pb.Board.Title != "Nodes" {
+			t.Errorf("unexpected board %+v", pb)
+		}
+	}
+}
+
+func TestDashboardProvisioning_InPlace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provisioning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := sdk.WriteDashboardProvisioning(dir, sdk.DashboardProvider{}, provisionedSample()[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs(filepath.Join(dir, "dashboards", "General"))
+	if p.Providers[0].Name != "default" || p.Providers[0].Options.Path != filepath.ToSlash(abs) {
+		t.Errorf("unexpected provider %+v", p.Providers[0])
+	}
+	if _, boards, err := sdk.ReadDashboardProvisioning(dir); err != nil || len(boards) != 1 || boards[0].Path != "home.json" {
+		t.Errorf("unexpected boards %+v: %v", boards, err)
+	}
+}
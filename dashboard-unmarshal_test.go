@@ -22,6 +22,8 @@ package sdk_test
 import (
 	"encoding/json"
 	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/grafana-tools/sdk"
@@ -101,8 +103,8 @@ func TestUnmarshal_DashboardWithGraphWithTargets26(t *testing.T) {
 	if panel.OfType != sdk.GraphType {
 		t.Errorf("panel type should be %d (\"graph\") type but got %d", sdk.GraphType, panel.OfType)
 	}
-	if panel.Datasource != sdk.MixedSource {
-		t.Errorf("panel Datasource should be \"%s\" but got \"%s\"", sdk.MixedSource, panel.Datasource)
+	if panel.Datasource == nil || panel.Datasource.Name != sdk.MixedSource {
+		t.Errorf("panel Datasource should be \"%s\" but got \"%v\"", sdk.MixedSource, panel.Datasource)
 	}
 	if len(panel.GraphPanel.Targets) != 2 {
 		t.Errorf("panel has 2 targets but got %d", len(panel.GraphPanel.Targets))
@@ -216,12 +218,50 @@ func TestUnmarshal_DashboardWithGraphWithTargets83(t *testing.T) {
 	}
 
 	target := panel.GraphPanel.Targets[0]
-	datasource, ok := target.Datasource.(map[string]interface{})
-	if !ok {
-		t.Fatalf("target Datasource should be a map but got %T", panel.Datasource)
+	if target.Datasource == nil || target.Datasource.IsLegacy() {
+		t.Fatalf("target Datasource should be an object but got %v", target.Datasource)
 	}
-	if datasource["type"] != "prometheus" {
-		t.Errorf("target datasource should be of type \"prometheus\" but got %s", datasource["type"])
+	if target.Datasource.Type != "prometheus" {
+		t.Errorf("target datasource should be of type \"prometheus\" but got %s", target.Datasource.Type)
 	}
 
 }
+
+// TestRoundTrip_AllFixtures decodes each dashboard from testdata to Board
+// and checks that marshalled board is semantically equal to the source
+// JSON: no keys lost, no keys added and no values changed.
+func TestRoundTrip_AllFixtures(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var board sdk.Board
+		if err = json.Unmarshal(raw, &board); err != nil {
+			t.Errorf("%s: %s", file, err)
+			continue
+		}
+		out, err := json.Marshal(board)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			continue
+		}
+		var expected, got interface{}
+		if err = json.Unmarshal(raw, &expected); err != nil {
+			t.Fatal(err)
+		}
+		if err = json.Unmarshal(out, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("%s: dashboard changed on round trip:\n%s", file, out)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+// DashboardURLParams are the query parameters of a link to the dashboard.
+// From and To take relative time ("now-6h") or Unix milliseconds, see
+// URLTime(). Variables are passed as "var-name" parameters, several values
+// select several options of multi-value variables.
+type DashboardURLParams struct {
+	OrgID     uint
+	From      string
+	To        string
+	Variables map[string][]string
+	ViewPanel uint
+	Refresh   string
+}
+
+// URLTime formats the time for From and To of DashboardURLParams.
+func URLTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// URLPath returns the path of the dashboard relative to Grafana root URL,
+// e.g. "d/uid/slug?from=now-6h&to=now". It is the path short URLs are
+// created for with Client.CreateShortURL().
+func (b *Board) URLPath(params DashboardURLParams) string {
+	return dashboardURLPath(b.UID, strings.ToLower(slug.Make(b.Title)), params)
+}
+
+// URLPath returns the path of the found dashboard relative to Grafana root
+// URL in the same way as Board.URLPath() does.
+func (f FoundBoard) URLPath(params DashboardURLParams) string {
+	s := f.Slug
+	if parts := strings.Split(strings.Trim(f.URL, "/"), "/"); s == "" && len(parts) == 3 {
+		s = parts[2]
+	}
+	if s == "" {
+		s = strings.ToLower(slug.Make(f.Title))
+	}
+	return dashboardURLPath(f.UID, s, params)
+}
+
+func dashboardURLPath(uid, s string, params DashboardURLParams) string {
+	var (
+		query = url.Values{}
+		path  = "d/" + url.PathEscape(uid) + "/" + s
+	)
+	if params.OrgID > 0 {
+		query.Set("orgId", strconv.FormatUint(uint64(params.OrgID), 10))
+	}
+	if params.From != "" {
+		query.Set("from", params.From)
+	}
+	if params.To != "" {
+		query.Set("to", params.To)
+	}
+	names := make([]string, 0, len(params.Variables))
+	for name := range params.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range params.Variables[name] {
+			query.Add("var-"+name, value)
+		}
+	}
+	if params.ViewPanel > 0 {
+		query.Set("viewPanel", strconv.FormatUint(uint64(params.ViewPanel), 10))
+	}
+	if params.Refresh != "" {
+		query.Set("refresh", params.Refresh)
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,39 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestBoard_URLPath(t *testing.T) {
+	b := sdk.NewBoard("Service Overview")
+	b.UID = "svc"
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := b.URLPath(sdk.DashboardURLParams{
+		OrgID:     1,
+		From:      sdk.URLTime(from),
+		To:        sdk.URLTime(from.Add(time.Hour)),
+		Variables: map[string][]string{"job": {"api", "web"}, "env": {"prod"}},
+		ViewPanel: 4,
+	})
+	exp := "d/svc/service-overview?from=1577836800000&orgId=1&to=1577840400000&var-env=prod&var-job=api&var-job=web&viewPanel=4"
+	if path != exp {
+		t.Errorf("expected %s, got %s", exp, path)
+	}
+	if path = b.URLPath(sdk.DashboardURLParams{}); path != "d/svc/service-overview" {
+		t.Errorf("unexpected path without params %s", path)
+	}
+}
+
+func TestFoundBoard_URLPath(t *testing.T) {
+	found := sdk.FoundBoard{UID: "svc", Title: "Service Overview", URL: "/d/svc/service-overview-renamed"}
+	if path := found.URLPath(sdk.DashboardURLParams{From: "now-1h", To: "now"}); path != "d/svc/service-overview-renamed?from=now-1h&to=now" {
+		t.Errorf("unexpected path %s", path)
+	}
+	found.URL = ""
+	if path := found.URLPath(sdk.DashboardURLParams{}); path != "d/svc/service-overview" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
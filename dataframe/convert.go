@@ -0,0 +1,191 @@
+package dataframe
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Rows returns the values of the frame row by row in the order of the
+// fields.
+func (f *Frame) Rows() [][]interface{} {
+	rows := make([][]interface{}, f.Len())
+	for i := range rows {
+		rows[i] = make([]interface{}, len(f.Fields))
+		for j, field := range f.Fields {
+			if i < len(field.Values) {
+				rows[i][j] = field.Values[i]
+			}
+		}
+	}
+	return rows
+}
+
+// WriteCSV writes the frame as CSV with the header of the field display
+// names. Times are in RFC 3339 format with nanoseconds, enum values are
+// their texts, nulls are empty cells.
+func (f *Frame) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(f.Fields))
+	formats := make([]func(interface{}) string, len(f.Fields))
+	for i, field := range f.Fields {
+		header[i] = field.DisplayName()
+		formats[i] = field.valueFormatter()
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range f.Rows() {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = formats[i](v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// LongRow is a single value of the long form table.
+type LongRow struct {
+	// Time is zero for frames without time.
+	Time time.Time
+	// Name is the name of the number field.
+	Name string
+	// Labels are labels of the number field merged with values of the
+	// string and enum fields of the same row.
+	Labels map[string]string
+	Value  interface{}
+}
+
+// ToLong converts the frames to the long form with a row for each value
+// of the number and boolean fields in the same way as Grafana does: the
+// first time field of the frame gives the time of the row and the string
+// and enum fields become the labels. It suits both the series returned
+// as a frame per series (Prometheus, Loki metric queries) and the wide or
+// long tables (SQL). Frames without such fields, e.g. Loki logs, give no
+// rows.
+func ToLong(frames ...*Frame) []LongRow {
+	var rows []LongRow
+	for _, f := range frames {
+		timeField := f.TimeField()
+		var (
+			dims    []*Field
+			formats []func(interface{}) string
+		)
+		for _, field := range f.Fields {
+			if field.Type == FieldTypeString || field.Type == FieldTypeEnum {
+				dims = append(dims, field)
+				formats = append(formats, field.valueFormatter())
+			}
+		}
+		for _, field := range f.Fields {
+			if field.Type != FieldTypeNumber && field.Type != FieldTypeBoolean {
+				continue
+			}
+			for i, v := range field.Values {
+				row := LongRow{Name: field.Name, Labels: make(map[string]string, len(field.Labels)+len(dims)), Value: v}
+				if timeField != nil && i < len(timeField.Values) {
+					row.Time, _ = timeField.Values[i].(time.Time)
+				}
+				for name, value := range field.Labels {
+					row.Labels[name] = value
+				}
+				for j, dim := range dims {
+					if i < len(dim.Values) && dim.Values[i] != nil {
+						row.Labels[dim.Name] = formats[j](dim.Values[i])
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// WriteLongCSV writes the long form rows as CSV with the "time" column,
+// a column for each label name in the alphabetical order, "name" and
+// "value" columns.
+func WriteLongCSV(w io.Writer, rows []LongRow) error {
+	seen := make(map[string]bool)
+	var labels []string
+	for _, row := range rows {
+		for name := range row.Labels {
+			if !seen[name] {
+				seen[name] = true
+				labels = append(labels, name)
+			}
+		}
+	}
+	sort.Strings(labels)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string{"time"}, labels...), "name", "value")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, 0, len(labels)+3)
+		if row.Time.IsZero() {
+			record = append(record, "")
+		} else {
+			record = append(record, FormatValue(row.Time))
+		}
+		for _, name := range labels {
+			record = append(record, row.Labels[name])
+		}
+		record = append(record, row.Name, FormatValue(row.Value))
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatValue formats the field value for the text output: times in RFC
+// 3339 format with nanoseconds, numbers in the shortest exact form, null
+// as the empty string and values of other fields as JSON.
+func FormatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	if raw, err := json.Marshal(v); err == nil {
+		return string(raw)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,85 @@
+package dataframe_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/grafana-tools/sdk/dataframe"
+)
+
+func TestFrame_WriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := loadFrames(t, "sql.json", "A")[0].WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	exp := `time,host,state,cpu,requests,healthy
+2023-11-14T22:13:20Z,db-1,ok,0.25,120,true
+2023-11-14T22:13:20Z,db-2,down,,98,false
+2023-11-14T22:15:00Z,db-1,,0.5,,true
+`
+	if buf.String() != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := loadFrames(t, "loki.json", "A")[0].WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	exp = `labels,Time,Line,tsNs,id
+"{""app"":""api"",""level"":""error""}",2023-11-14T22:13:21.234567891Z,"level=error msg=""upstream timeout"" duration=30.2s",1700000001234567891,1700000001234567891_1b2c3d4e
+"{""app"":""api"",""level"":""error"",""pod"":""api-7d9f""}",2023-11-14T22:13:20.001000002Z,"level=error msg=""connection reset""",1700000000001000002,1700000000001000002_5f6a7b8c
+`
+	if buf.String() != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, buf.String())
+	}
+}
+
+func TestToLong_Prometheus(t *testing.T) {
+	rows := dataframe.ToLong(loadFrames(t, "prometheus.json", "A")...)
+	if len(rows) != 6 {
+		t.Fatalf("expected 6 rows, got %d", len(rows))
+	}
+	if rows[3].Labels["instance"] != "node-2:9100" || rows[3].Name != "Value" || rows[3].Time.Unix() != 1700000000 {
+		t.Errorf("unexpected row %+v", rows[3])
+	}
+	var buf bytes.Buffer
+	if err := dataframe.WriteLongCSV(&buf, rows[:4]); err != nil {
+		t.Fatal(err)
+	}
+	exp := `time,__name__,instance,job,name,value
+2023-11-14T22:13:20Z,up,node-1:9100,node,Value,1
+2023-11-14T22:13:35Z,up,node-1:9100,node,Value,1
+2023-11-14T22:13:50Z,up,node-1:9100,node,Value,0
+2023-11-14T22:13:20Z,up,node-2:9100,node,Value,NaN
+`
+	if buf.String() != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, buf.String())
+	}
+}
+
+func TestToLong_SQL(t *testing.T) {
+	rows := dataframe.ToLong(loadFrames(t, "sql.json", "A")...)
+	// cpu, requests and healthy values of 3 rows
+	if len(rows) != 9 {
+		t.Fatalf("expected 9 rows, got %d", len(rows))
+	}
+	if rows[1].Name != "cpu" || rows[1].Labels["host"] != "db-2" || rows[1].Labels["state"] != "down" || rows[1].Value != nil {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+	if _, ok := rows[2].Labels["state"]; ok {
+		t.Errorf("null state should not be a label, got %+v", rows[2])
+	}
+	if rows[3].Name != "requests" || rows[3].Value != int64(120) {
+		t.Errorf("unexpected row %+v", rows[3])
+	}
+}
+
+func TestToLong_Loki(t *testing.T) {
+	if rows := dataframe.ToLong(loadFrames(t, "loki.json", "A")...); len(rows) != 0 {
+		t.Errorf("expected no rows for logs, got %v", rows)
+	}
+	rows := dataframe.ToLong(loadFrames(t, "loki.json", "B")...)
+	if len(rows) != 2 || rows[1].Value != 7.0 || rows[1].Labels["level"] != "error" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
@@ -0,0 +1,238 @@
+package dataframe
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field looks up the field of the frame by name. It returns nil if the
+// frame has no such field.
+func (f *Frame) Field(name string) *Field {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// TimeField returns the first time field of the frame or nil for frames
+// without time, e.g. results of table queries.
+func (f *Frame) TimeField() *Field {
+	for _, field := range f.Fields {
+		if field.Type == FieldTypeTime {
+			return field
+		}
+	}
+	return nil
+}
+
+// DisplayName returns the name Grafana shows for the field: the display
+// name of the field config if it is set or the name with the labels, e.g.
+// `Value {instance="host:9100", job="node"}`.
+func (f *Field) DisplayName() string {
+	if len(f.Config) > 0 {
+		var cfg struct {
+			DisplayName       string `json:"displayName"`
+			DisplayNameFromDS string `json:"displayNameFromDS"`
+		}
+		if err := json.Unmarshal(f.Config, &cfg); err == nil {
+			if cfg.DisplayName != "" {
+				return cfg.DisplayName
+			}
+			if cfg.DisplayNameFromDS != "" {
+				return cfg.DisplayNameFromDS
+			}
+		}
+	}
+	if len(f.Labels) == 0 {
+		return f.Name
+	}
+	return strings.TrimSpace(f.Name + " " + formatLabels(f.Labels))
+}
+
+// Float64s returns values of the number field. Null values are NaN.
+func (f *Field) Float64s() ([]float64, error) {
+	if f.Type != FieldTypeNumber {
+		return nil, f.typeError(FieldTypeNumber)
+	}
+	result := make([]float64, len(f.Values))
+	for i, v := range f.Values {
+		switch x := v.(type) {
+		case float64:
+			result[i] = x
+		case int64:
+			result[i] = float64(x)
+		case nil:
+			result[i] = math.NaN()
+		default:
+			return nil, fmt.Errorf("field %q: unexpected value %v at %d", f.Name, v, i)
+		}
+	}
+	return result, nil
+}
+
+// Int64s returns values of the number field of an integer type. Null
+// values are zeroes.
+func (f *Field) Int64s() ([]int64, error) {
+	if f.Type != FieldTypeNumber {
+		return nil, f.typeError(FieldTypeNumber)
+	}
+	result := make([]int64, len(f.Values))
+	for i, v := range f.Values {
+		switch x := v.(type) {
+		case int64:
+			result[i] = x
+		case nil:
+		default:
+			return nil, fmt.Errorf("field %q: value %v at %d is not an integer", f.Name, v, i)
+		}
+	}
+	return result, nil
+}
+
+// Times returns values of the time field. Null values are zero times.
+func (f *Field) Times() ([]time.Time, error) {
+	if f.Type != FieldTypeTime {
+		return nil, f.typeError(FieldTypeTime)
+	}
+	result := make([]time.Time, len(f.Values))
+	for i, v := range f.Values {
+		if t, ok := v.(time.Time); ok {
+			result[i] = t
+		}
+	}
+	return result, nil
+}
+
+// Strings returns values of the string or enum field. Values of the enum
+// field are resolved to the texts of its config. Null values are empty
+// strings.
+func (f *Field) Strings() ([]string, error) {
+	if f.Type != FieldTypeString && f.Type != FieldTypeEnum {
+		return nil, f.typeError(FieldTypeString)
+	}
+	texts := f.Enum()
+	result := make([]string, len(f.Values))
+	for i, v := range f.Values {
+		switch x := v.(type) {
+		case string:
+			result[i] = x
+		case nil:
+		default:
+			if text, ok := enumText(texts, x); ok {
+				result[i] = text
+				continue
+			}
+			result[i] = fmt.Sprint(x)
+		}
+	}
+	return result, nil
+}
+
+// Enum returns the texts of the enum field from its config, values of the
+// field are the indices of these texts. It returns nil for other fields.
+func (f *Field) Enum() []string {
+	if f.Type != FieldTypeEnum || len(f.Config) == 0 {
+		return nil
+	}
+	var cfg struct {
+		Type struct {
+			Enum struct {
+				Text []string `json:"text"`
+			} `json:"enum"`
+		} `json:"type"`
+	}
+	if err := json.Unmarshal(f.Config, &cfg); err != nil {
+		return nil
+	}
+	return cfg.Type.Enum.Text
+}
+
+// valueFormatter returns the function formatting values of the field with
+// FormatValue() that resolves values of enum fields to their texts.
+func (f *Field) valueFormatter() func(interface{}) string {
+	texts := f.Enum()
+	if texts == nil {
+		return FormatValue
+	}
+	return func(v interface{}) string {
+		if text, ok := enumText(texts, v); ok {
+			return text
+		}
+		return FormatValue(v)
+	}
+}
+
+// enumText returns the text of the enum value given by its index.
+func enumText(texts []string, v interface{}) (string, bool) {
+	var i int
+	switch x := v.(type) {
+	case int64:
+		i = int(x)
+	case float64:
+		if x != math.Trunc(x) {
+			return "", false
+		}
+		i = int(x)
+	default:
+		return "", false
+	}
+	if i < 0 || i >= len(texts) {
+		return "", false
+	}
+	return texts[i], true
+}
+
+// Bools returns values of the boolean field. Null values are false.
+func (f *Field) Bools() ([]bool, error) {
+	if f.Type != FieldTypeBoolean {
+		return nil, f.typeError(FieldTypeBoolean)
+	}
+	result := make([]bool, len(f.Values))
+	for i, v := range f.Values {
+		result[i], _ = v.(bool)
+	}
+	return result, nil
+}
+
+func (f *Field) typeError(expected FieldType) error {
+	return fmt.Errorf("field %q is of %s type, not %s", f.Name, f.Type, expected)
+}
+
+// formatLabels formats labels in the Prometheus way sorted by names.
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
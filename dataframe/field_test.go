@@ -0,0 +1,125 @@
+package dataframe_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk/dataframe"
+)
+
+// loadFrames reads frames of the query from the /api/ds/query response.
+func loadFrames(t *testing.T, fixture, refID string) []*dataframe.Frame {
+	t.Helper()
+	raw, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Results map[string]struct {
+			Frames []*dataframe.Frame `json:"frames"`
+		} `json:"results"`
+	}
+	if err = json.Unmarshal(raw, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Results[refID].Frames
+}
+
+func TestField_Prometheus(t *testing.T) {
+	frames := loadFrames(t, "prometheus.json", "A")
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	times, err := frames[0].TimeField().Times()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 3 || !times[1].Equal(time.Unix(1700000015, 0)) {
+		t.Errorf("unexpected times %v", times)
+	}
+	value := frames[0].Field("Value")
+	if value.DisplayName() != "node-1" {
+		t.Errorf("expected display name from datasource, got %q", value.DisplayName())
+	}
+	if name := frames[1].Field("Value").DisplayName(); name != `Value {__name__="up", instance="node-2:9100", job="node"}` {
+		t.Errorf("unexpected display name %q", name)
+	}
+	values, err := frames[1].Field("Value").Float64s()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(values[0]) || values[1] != 1 || !math.IsInf(values[2], 1) {
+		t.Errorf("unexpected values %v", values)
+	}
+	if _, err = value.Times(); err == nil {
+		t.Error("expected error for times of the number field")
+	}
+	if frames[0].Field("missing") != nil {
+		t.Error("expected nil for missing field")
+	}
+}
+
+func TestField_Loki(t *testing.T) {
+	logs := loadFrames(t, "loki.json", "A")[0]
+	times, err := logs.Field("Time").Times()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if times[0].UnixNano() != 1700000001234567891 || times[1].UnixNano() != 1700000000001000002 {
+		t.Errorf("expected nanosecond precision, got %d and %d", times[0].UnixNano(), times[1].UnixNano())
+	}
+	lines, err := logs.Field("Line").Strings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines[1] != `level=error msg="connection reset"` {
+		t.Errorf("unexpected lines %q", lines)
+	}
+	labels, ok := logs.Field("labels").Values[1].(map[string]interface{})
+	if !ok || labels["pod"] != "api-7d9f" {
+		t.Errorf("unexpected labels %v", logs.Field("labels").Values[1])
+	}
+}
+
+func TestField_SQL(t *testing.T) {
+	table := loadFrames(t, "sql.json", "A")[0]
+	cpu, err := table.Field("cpu").Float64s()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu[0] != 0.25 || !math.IsNaN(cpu[1]) {
+		t.Errorf("expected NaN for null, got %v", cpu)
+	}
+	requests, err := table.Field("requests").Int64s()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests[0] != 120 || requests[2] != 0 {
+		t.Errorf("unexpected requests %v", requests)
+	}
+	if _, err = table.Field("cpu").Int64s(); err == nil {
+		t.Error("expected error for integers of the float field")
+	}
+	state := table.Field("state")
+	if texts := state.Enum(); len(texts) != 3 || texts[1] != "degraded" {
+		t.Errorf("unexpected enum texts %v", texts)
+	}
+	states, err := state.Strings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[0] != "ok" || states[1] != "down" || states[2] != "" {
+		t.Errorf("expected enum texts, got %q", states)
+	}
+	healthy, err := table.Field("healthy").Bools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !healthy[0] || healthy[1] {
+		t.Errorf("unexpected booleans %v", healthy)
+	}
+}
@@ -0,0 +1,281 @@
+// Package dataframe decodes data frames returned by Grafana datasources,
+// e.g. by /api/ds/query API call. On the wire a frame is split into the
+// schema with names and types of the fields and the data with the columns
+// of values, nanoseconds of time values and special float values that
+// JSON can't keep. Frames are decoded into fields with Go values.
+package dataframe
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of the field values.
+type FieldType string
+
+// Types of the fields as Grafana names them.
+const (
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeString  FieldType = "string"
+	FieldTypeTime    FieldType = "time"
+	FieldTypeBoolean FieldType = "boolean"
+	FieldTypeEnum    FieldType = "enum"
+	FieldTypeOther   FieldType = "other"
+)
+
+// Frame is a table of named typed columns, the fields.
+type Frame struct {
+	Name  string
+	RefID string
+	// Meta keeps the frame metadata as is, e.g. the executed query.
+	Meta   json.RawMessage
+	Fields []*Field
+}
+
+// Field is a column of the frame. Values are float64 for numbers, int64
+// for numbers of integer Go types, time.Time for times, string and bool.
+// Values of other fields are decoded as encoding/json does. Null values
+// are nil.
+type Field struct {
+	Name string
+	Type FieldType
+	// FrameType is the Go type of the values in the Grafana backend,
+	// e.g. "*float64" or "time.Time".
+	FrameType string
+	Labels    map[string]string
+	// Config keeps the field config as is, e.g. the display name.
+	Config json.RawMessage
+	Values []interface{}
+}
+
+// Len returns the number of the rows in the frame.
+func (f *Frame) Len() int {
+	if len(f.Fields) == 0 {
+		return 0
+	}
+	return len(f.Fields[0].Values)
+}
+
+type (
+	wireFrame struct {
+		Schema wireSchema `json:"schema"`
+		Data   *wireData  `json:"data"`
+	}
+	wireSchema struct {
+		Name   string          `json:"name,omitempty"`
+		RefID  string          `json:"refId,omitempty"`
+		Meta   json.RawMessage `json:"meta,omitempty"`
+		Fields []wireField     `json:"fields"`
+	}
+	wireField struct {
+		Name     string            `json:"name"`
+		Type     FieldType         `json:"type,omitempty"`
+		TypeInfo *wireTypeInfo     `json:"typeInfo,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Config   json.RawMessage   `json:"config,omitempty"`
+	}
+	wireTypeInfo struct {
+		Frame    string `json:"frame"`
+		Nullable bool   `json:"nullable,omitempty"`
+	}
+	wireData struct {
+		Values   []json.RawMessage `json:"values"`
+		Entities []*wireEntities   `json:"entities,omitempty"`
+		Nanos    [][]int64         `json:"nanos,omitempty"`
+	}
+	// wireEntities lists indexes of special float values of a column.
+	wireEntities struct {
+		NaN    []int `json:"NaN,omitempty"`
+		Inf    []int `json:"Inf,omitempty"`
+		NegInf []int `json:"NegInf,omitempty"`
+	}
+)
+
+// UnmarshalJSON decodes the frame from the wire format of Grafana.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	var w wireFrame
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*f = Frame{Name: w.Schema.Name, RefID: w.Schema.RefID, Meta: w.Schema.Meta}
+	for i, wf := range w.Schema.Fields {
+		field := &Field{Name: wf.Name, Type: wf.Type, Labels: wf.Labels, Config: wf.Config}
+		if wf.TypeInfo != nil {
+			field.FrameType = wf.TypeInfo.Frame
+		}
+		if w.Data != nil && i < len(w.Data.Values) {
+			if err := field.decodeValues(w.Data, i); err != nil {
+				return fmt.Errorf("field %q: %w", wf.Name, err)
+			}
+		}
+		f.Fields = append(f.Fields, field)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the frame to the wire format of Grafana.
+func (f Frame) MarshalJSON() ([]byte, error) {
+	w := wireFrame{
+		Schema: wireSchema{Name: f.Name, RefID: f.RefID, Meta: f.Meta, Fields: []wireField{}},
+		Data:   &wireData{Values: []json.RawMessage{}},
+	}
+	var hasEntities, hasNanos bool
+	for _, field := range f.Fields {
+		wf := wireField{Name: field.Name, Type: field.Type, Labels: field.Labels, Config: field.Config}
+		if field.FrameType != "" {
+			wf.TypeInfo = &wireTypeInfo{Frame: field.FrameType, Nullable: strings.HasPrefix(field.FrameType, "*")}
+		}
+		w.Schema.Fields = append(w.Schema.Fields, wf)
+		values, entities, nanos := field.encodeValues()
+		raw, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+		w.Data.Values = append(w.Data.Values, raw)
+		w.Data.Entities = append(w.Data.Entities, entities)
+		w.Data.Nanos = append(w.Data.Nanos, nanos)
+		hasEntities = hasEntities || entities != nil
+		hasNanos = hasNanos || nanos != nil
+	}
+	if !hasEntities {
+		w.Data.Entities = nil
+	}
+	if !hasNanos {
+		w.Data.Nanos = nil
+	}
+	return json.Marshal(w)
+}
+
+func (f *Field) decodeValues(data *wireData, i int) error {
+	var values []interface{}
+	dec := json.NewDecoder(bytes.NewReader(data.Values[i]))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return err
+	}
+	var nanos []int64
+	if i < len(data.Nanos) {
+		nanos = data.Nanos[i]
+	}
+	for j, v := range values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		switch {
+		case f.Type == FieldTypeTime:
+			ms, err := n.Int64()
+			if err != nil {
+				// fractional milliseconds
+				x, err := n.Float64()
+				if err != nil {
+					return err
+				}
+				values[j] = time.Unix(0, int64(x*float64(time.Millisecond))).UTC()
+				continue
+			}
+			ns := ms * int64(time.Millisecond)
+			if j < len(nanos) {
+				ns += nanos[j]
+			}
+			values[j] = time.Unix(0, ns).UTC()
+		case f.isInteger():
+			if x, err := n.Int64(); err == nil {
+				values[j] = x
+				continue
+			}
+			fallthrough
+		default:
+			x, err := n.Float64()
+			if err != nil {
+				return err
+			}
+			values[j] = x
+		}
+	}
+	if i < len(data.Entities) && data.Entities[i] != nil {
+		e := data.Entities[i]
+		for _, special := range []struct {
+			idx   []int
+			value float64
+		}{{e.NaN, math.NaN()}, {e.Inf, math.Inf(1)}, {e.NegInf, math.Inf(-1)}} {
+			for _, j := range special.idx {
+				if j < len(values) {
+					values[j] = special.value
+				}
+			}
+		}
+	}
+	f.Values = values
+	return nil
+}
+
+func (f *Field) encodeValues() ([]interface{}, *wireEntities, []int64) {
+	var (
+		values   = make([]interface{}, len(f.Values))
+		entities wireEntities
+		nanos    []int64
+		special  bool
+	)
+	for j, v := range f.Values {
+		switch x := v.(type) {
+		case time.Time:
+			ns := x.UnixNano()
+			values[j] = ns / int64(time.Millisecond)
+			if rest := ns % int64(time.Millisecond); rest != 0 {
+				if nanos == nil {
+					nanos = make([]int64, len(f.Values))
+				}
+				nanos[j] = rest
+			}
+			continue
+		case float64:
+			switch {
+			case math.IsNaN(x):
+				entities.NaN = append(entities.NaN, j)
+			case math.IsInf(x, 1):
+				entities.Inf = append(entities.Inf, j)
+			case math.IsInf(x, -1):
+				entities.NegInf = append(entities.NegInf, j)
+			default:
+				values[j] = x
+				continue
+			}
+			special = true
+			continue
+		}
+		values[j] = v
+	}
+	if !special {
+		return values, nil, nanos
+	}
+	return values, &entities, nanos
+}
+
+func (f *Field) isInteger() bool {
+	t := strings.TrimPrefix(f.FrameType, "*")
+	return strings.HasPrefix(t, "int") || strings.HasPrefix(t, "uint")
+}
@@ -0,0 +1,64 @@
+package dataframe_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk/dataframe"
+)
+
+const sampleFrame = `{
+	"schema": {"name": "sample", "refId": "A", "fields": [
+		{"name": "Time", "type": "time", "typeInfo": {"frame": "time.Time"}},
+		{"name": "Count", "type": "number", "typeInfo": {"frame": "*int64", "nullable": true}},
+		{"name": "Value", "type": "number", "typeInfo": {"frame": "float64"}},
+		{"name": "Host", "type": "string", "typeInfo": {"frame": "string"}}]},
+	"data": {
+		"values": [[1700000000000, 1700000000001, 1700000000002], [9007199254740993, null, 2], [null, null, 1.5], ["a", "b", "c"]],
+		"entities": [null, null, {"NaN": [0], "Inf": [1]}, null],
+		"nanos": [[0, 500, 0], null, null, null]}}`
+
+func TestFrame_UnmarshalJSON(t *testing.T) {
+	var f dataframe.Frame
+	if err := json.Unmarshal([]byte(sampleFrame), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "sample" || f.RefID != "A" || len(f.Fields) != 4 || f.Len() != 3 {
+		t.Fatalf("unexpected frame %+v", f)
+	}
+	if ts := f.Fields[0].Values[1].(time.Time); ts.UnixNano() != 1700000000001000500 {
+		t.Errorf("expected nanoseconds of time, got %d", ts.UnixNano())
+	}
+	if f.Fields[1].Values[0] != int64(9007199254740993) || f.Fields[1].Values[1] != nil {
+		t.Errorf("unexpected integer values %v", f.Fields[1].Values)
+	}
+	values := f.Fields[2].Values
+	if !math.IsNaN(values[0].(float64)) || !math.IsInf(values[1].(float64), 1) || values[2] != 1.5 {
+		t.Errorf("unexpected float values %v", values)
+	}
+	if f.Fields[3].Values[2] != "c" {
+		t.Errorf("unexpected string values %v", f.Fields[3].Values)
+	}
+}
+
+func TestFrame_MarshalJSON(t *testing.T) {
+	var f, g dataframe.Frame
+	if err := json.Unmarshal([]byte(sampleFrame), &f); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(raw, &g); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Fields[0].Values[1].(time.Time).Equal(f.Fields[0].Values[1].(time.Time)) {
+		t.Errorf("time values differ after round trip: %s", raw)
+	}
+	if g.Fields[1].Values[0] != int64(9007199254740993) || !math.IsNaN(g.Fields[2].Values[0].(float64)) || !math.IsInf(g.Fields[2].Values[1].(float64), 1) {
+		t.Errorf("values differ after round trip: %s", raw)
+	}
+}
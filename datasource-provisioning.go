@@ -0,0 +1,319 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DatasourceProvisioning is the file of the datasources provisioned by
+// Grafana on start, see
+// https://grafana.com/docs/grafana/latest/administration/provisioning/#data-sources
+type DatasourceProvisioning struct {
+	APIVersion        int                     `yaml:"apiVersion"`
+	DeleteDatasources []DeleteDatasource      `yaml:"deleteDatasources,omitempty"`
+	Datasources       []ProvisionedDatasource `yaml:"datasources"`
+}
+
+// DeleteDatasource refers to the datasource removed on provisioning.
+type DeleteDatasource struct {
+	Name  string `yaml:"name"`
+	OrgID uint   `yaml:"orgId,omitempty"`
+}
+
+// ProvisionedDatasource is the datasource of the provisioning file.
+// Secrets of SecureJSONData usually refer to environment variables as
+// "$VAR" or "${VAR}", Grafana expands them on provisioning.
+type ProvisionedDatasource struct {
+	Name           string                 `yaml:"name"`
+	Type           string                 `yaml:"type"`
+	UID            string                 `yaml:"uid,omitempty"`
+	OrgID          uint                   `yaml:"orgId,omitempty"`
+	Access         string                 `yaml:"access,omitempty"`
+	URL            string                 `yaml:"url,omitempty"`
+	User           string                 `yaml:"user,omitempty"`
+	Database       string                 `yaml:"database,omitempty"`
+	BasicAuth      bool                   `yaml:"basicAuth,omitempty"`
+	BasicAuthUser  string                 `yaml:"basicAuthUser,omitempty"`
+	IsDefault      bool                   `yaml:"isDefault,omitempty"`
+	JSONData       map[string]interface{} `yaml:"jsonData,omitempty"`
+	SecureJSONData map[string]string      `yaml:"secureJsonData,omitempty"`
+	Version        int                    `yaml:"version,omitempty"`
+	Editable       bool                   `yaml:"editable,omitempty"`
+}
+
+// NewDatasourceProvisioning converts the datasources to the provisioning
+// file. Secrets are never exported: each secret set for the datasource,
+// as SecureJSONFields of datasources got from Grafana report them, is
+// replaced by the reference to the environment variable named after the
+// datasource and the secret, e.g. "${PROMETHEUS_BASIC_AUTH_PASSWORD}".
+// Datasources editable in Grafana UI stay editable.
+func NewDatasourceProvisioning(datasources []Datasource) (*DatasourceProvisioning, error) {
+	p := &DatasourceProvisioning{APIVersion: 1, Datasources: []ProvisionedDatasource{}}
+	for _, ds := range datasources {
+		pds := ProvisionedDatasource{
+			Name:          ds.Name,
+			Type:          ds.Type,
+			UID:           ds.UID,
+			OrgID:         ds.OrgID,
+			Access:        ds.Access,
+			URL:           ds.URL,
+			User:          stringValue(ds.User),
+			Database:      stringValue(ds.Database),
+			BasicAuth:     ds.BasicAuth != nil && *ds.BasicAuth,
+			BasicAuthUser: stringValue(ds.BasicAuthUser),
+			IsDefault:     ds.IsDefault,
+			Version:       ds.Version,
+			Editable:      ds.ReadOnly == nil || !*ds.ReadOnly,
+		}
+		var err error
+		if pds.JSONData, err = settingsMap(ds.JSONData); err != nil {
+			return nil, fmt.Errorf("datasource %q: %w", ds.Name, err)
+		}
+		secrets, err := settingsMap(ds.SecureJSONData)
+		if err != nil {
+			return nil, fmt.Errorf("datasource %q: %w", ds.Name, err)
+		}
+		keys := make(map[string]bool)
+		for key := range secrets {
+			keys[key] = true
+		}
+		for key, set := range ds.SecureJSONFields {
+			keys[key] = keys[key] || set
+		}
+		// legacy secrets of the datasource itself
+		if ds.Password != nil && *ds.Password != "" {
+			keys["password"] = true
+		}
+		if ds.BasicAuthPassword != nil && *ds.BasicAuthPassword != "" {
+			keys["basicAuthPassword"] = true
+		}
+		for key, set := range keys {
+			if !set {
+				continue
+			}
+			if pds.SecureJSONData == nil {
+				pds.SecureJSONData = make(map[string]string)
+			}
+			pds.SecureJSONData[key] = "${" + envName(ds.Name, key) + "}"
+		}
+		p.Datasources = append(p.Datasources, pds)
+	}
+	return p, nil
+}
+
+// ParseDatasourceProvisioning parses the provisioning file. Only version
+// 1 of the file format is supported.
+func ParseDatasourceProvisioning(data []byte) (*DatasourceProvisioning, error) {
+	var p DatasourceProvisioning
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.APIVersion != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion %d of datasource provisioning", p.APIVersion)
+	}
+	return &p, nil
+}
+
+// YAML marshals the provisioning file with two spaces indentation as in
+// Grafana samples.
+func (p *DatasourceProvisioning) YAML() ([]byte, error) {
+	return marshalYAML(p)
+}
+
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SecretEnv returns sorted names of the environment variables referred
+// by the secrets of the datasources.
+func (p *DatasourceProvisioning) SecretEnv() []string {
+	seen := make(map[string]bool)
+	for _, ds := range p.Datasources {
+		for _, value := range ds.SecureJSONData {
+			for _, m := range envRefRe.FindAllStringSubmatch(value, -1) {
+				seen[m[1]+m[2]] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// ToDatasources converts the provisioned datasources to the datasources
+// for Client.CreateDatasource(). References to environment variables in
+// all the values are expanded with getenv in the same way as Grafana
+// does, e.g. os.Getenv could be passed. The references are kept if getenv
+// is nil. The settings are decoded like the ones got from Grafana, e.g.
+// JSONData of Prometheus datasources is *PrometheusJSONData.
+func (p *DatasourceProvisioning) ToDatasources(getenv func(string) string) ([]Datasource, error) {
+	expand := func(s string) string {
+		if getenv == nil {
+			return s
+		}
+		return os.Expand(s, getenv)
+	}
+	var result []Datasource
+	for _, pds := range p.Datasources {
+		readOnly := !pds.Editable
+		ds := Datasource{
+			Name:          expand(pds.Name),
+			Type:          expand(pds.Type),
+			UID:           expand(pds.UID),
+			OrgID:         pds.OrgID,
+			Access:        expand(pds.Access),
+			URL:           expand(pds.URL),
+			User:          stringPtr(expand(pds.User)),
+			Database:      stringPtr(expand(pds.Database)),
+			BasicAuthUser: stringPtr(expand(pds.BasicAuthUser)),
+			IsDefault:     pds.IsDefault,
+			Version:       pds.Version,
+			ReadOnly:      &readOnly,
+		}
+		if pds.BasicAuth {
+			basicAuth := true
+			ds.BasicAuth = &basicAuth
+		}
+		jsonData, secureJSONData := newDatasourceSettings(ds.Type)
+		var err error
+		if ds.JSONData, err = settingsOf(expandValues(pds.JSONData, expand), jsonData); err != nil {
+			return nil, fmt.Errorf("datasource %q: %w", pds.Name, err)
+		}
+		secrets := make(map[string]interface{}, len(pds.SecureJSONData))
+		for key, value := range pds.SecureJSONData {
+			secrets[key] = expand(value)
+		}
+		if ds.SecureJSONData, err = settingsOf(secrets, secureJSONData); err != nil {
+			return nil, fmt.Errorf("datasource %q: %w", pds.Name, err)
+		}
+		result = append(result, ds)
+	}
+	return result, nil
+}
+
+// envRefRe matches references to environment variables: $VAR and ${VAR}.
+var envRefRe = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// envName builds the name of the environment variable from the datasource
+// name and the camel case key, e.g. "Prometheus" and "basicAuthPassword"
+// give "PROMETHEUS_BASIC_AUTH_PASSWORD".
+func envName(dsName, key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			prev := key[i-1]
+			if prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9' {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(r)
+	}
+	name := nonWordRe.ReplaceAllString(dsName+"_"+b.String(), "_")
+	return strings.ToUpper(strings.Trim(name, "_"))
+}
+
+// settingsMap converts the settings of any type to the map.
+func settingsMap(settings interface{}) (map[string]interface{}, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// settingsOf converts the settings map to the typed settings if typed is
+// not nil.
+func settingsOf(m map[string]interface{}, typed json.Unmarshaler) (interface{}, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSettings(raw, typed)
+}
+
+// expandValues applies expand to all strings of the decoded YAML value.
+func expandValues(m map[string]interface{}, expand func(string) string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = expandValue(v, expand)
+	}
+	return result
+}
+
+func expandValue(v interface{}, expand func(string) string) interface{} {
+	switch x := v.(type) {
+	case string:
+		return expand(x)
+	case map[string]interface{}:
+		return expandValues(x, expand)
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i := range x {
+			result[i] = expandValue(x[i], expand)
+		}
+		return result
+	}
+	return v
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,166 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestNewDatasourceProvisioning(t *testing.T) {
+	var datasources []sdk.Datasource
+	raw := `[{"id": 1, "orgId": 1, "uid": "prom", "name": "Prometheus", "type": "prometheus", "access": "proxy",
+		"url": "http://prometheus:9090", "basicAuth": true, "basicAuthUser": "grafana", "isDefault": true, "readOnly": false,
+		"jsonData": {"httpMethod": "POST", "timeInterval": "30s"}, "secureJsonFields": {"basicAuthPassword": true}},
+		{"id": 2, "orgId": 1, "uid": "pg", "name": "Postgres main", "type": "postgres", "url": "db:5432", "user": "grafana",
+		"readOnly": true, "jsonData": {"database": "metrics", "sslmode": "disable"}, "secureJsonFields": {"password": true, "tlsCACert": false}}]`
+	if err := json.Unmarshal([]byte(raw), &datasources); err != nil {
+		t.Fatal(err)
+	}
+	p, err := sdk.NewDatasourceProvisioning(datasources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `apiVersion: 1
+datasources:
+  - name: Prometheus
+    type: prometheus
+    uid: prom
+    orgId: 1
+    access: proxy
+    url: http://prometheus:9090
+    basicAuth: true
+    basicAuthUser: grafana
+    isDefault: true
+    jsonData:
+      httpMethod: POST
+      timeInterval: 30s
+    secureJsonData:
+      basicAuthPassword: ${PROMETHEUS_BASIC_AUTH_PASSWORD}
+    editable: true
+  - name: Postgres main
+    type: postgres
+    uid: pg
+    orgId: 1
+    url: db:5432
+    user: grafana
+    jsonData:
+      database: metrics
+      sslmode: disable
+    secureJsonData:
+      password: ${POSTGRES_MAIN_PASSWORD}
+`
+	if string(b) != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, b)
+	}
+	if env := p.SecretEnv(); strings.Join(env, ",") != "POSTGRES_MAIN_PASSWORD,PROMETHEUS_BASIC_AUTH_PASSWORD" {
+		t.Errorf("unexpected secret env %v", env)
+	}
+}
+
+func TestParseDatasourceProvisioning(t *testing.T) {
+	raw := `apiVersion: 1
+deleteDatasources:
+  - name: Graphite
+    orgId: 1
+datasources:
+  - name: Loki
+    type: loki
+    uid: loki
+    access: proxy
+    url: http://$LOKI_HOST:3100
+    jsonData:
+      maxLines: "1000"
+      derivedFields:
+        - name: traceID
+          matcherRegex: "traceID=(\\w+)"
+          datasourceUid: tempo
+    secureJsonData:
+      basicAuthPassword: ${LOKI_PASSWORD}
+  - name: Graphite
+    type: graphite
+    url: http://graphite
+    editable: true
+    jsonData:
+      graphiteVersion: "1.1"
+`
+	p, err := sdk.ParseDatasourceProvisioning([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.DeleteDatasources) != 1 || p.DeleteDatasources[0].Name != "Graphite" {
+		t.Errorf("unexpected datasources to delete %+v", p.DeleteDatasources)
+	}
+	env := map[string]string{"LOKI_HOST": "loki", "LOKI_PASSWORD": "secret"}
+	datasources, err := p.ToDatasources(func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasources) != 2 {
+		t.Fatalf("expected 2 datasources, got %d", len(datasources))
+	}
+	loki := datasources[0]
+	if loki.URL != "http://loki:3100" || loki.ReadOnly == nil || !*loki.ReadOnly {
+		t.Errorf("unexpected datasource %+v", loki)
+	}
+	settings, ok := loki.JSONData.(*sdk.LokiJSONData)
+	if !ok || settings.MaxLines != "1000" || settings.DerivedFields[0].MatcherRegex != `traceID=(\w+)` {
+		t.Errorf("unexpected settings %#v", loki.JSONData)
+	}
+	if secrets, ok := loki.SecureJSONData.(*sdk.HTTPSecureJSONData); !ok || secrets.BasicAuthPassword != "secret" {
+		t.Errorf("unexpected secrets %#v", loki.SecureJSONData)
+	}
+	if settings, ok := datasources[1].JSONData.(map[string]interface{}); !ok || settings["graphiteVersion"] != "1.1" || *datasources[1].ReadOnly {
+		t.Errorf("unexpected datasource %+v", datasources[1])
+	}
+
+	// references are kept without getenv
+	if datasources, err = p.ToDatasources(nil); err != nil {
+		t.Fatal(err)
+	}
+	if datasources[0].URL != "http://$LOKI_HOST:3100" {
+		t.Errorf("expected unexpanded URL, got %q", datasources[0].URL)
+	}
+
+	if _, err = sdk.ParseDatasourceProvisioning([]byte("apiVersion: 2\ndatasources: []\n")); err == nil {
+		t.Error("expected error for unsupported apiVersion")
+	}
+}
+
+func TestDatasourceProvisioning_RoundTrip(t *testing.T) {
+	user := "grafana"
+	ds := sdk.Datasource{Name: "Influx", Type: "influxdb", UID: "influx", URL: "http://influx:8086", User: &user,
+		JSONData:       &sdk.InfluxDBJSONData{Version: "Flux", Organization: "ops", DefaultBucket: "metrics"},
+		SecureJSONData: &sdk.InfluxDBSecureJSONData{Token: "secret"}}
+	p, err := sdk.NewDatasourceProvisioning([]sdk.Datasource{ds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("secrets should not be exported:\n%s", b)
+	}
+	if p, err = sdk.ParseDatasourceProvisioning(b); err != nil {
+		t.Fatal(err)
+	}
+	datasources, err := p.ToDatasources(func(string) string { return "token" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := datasources[0]
+	settings := got.JSONData.(*sdk.InfluxDBJSONData)
+	if got.UID != "influx" || *got.User != "grafana" || settings.Organization != "ops" || settings.DefaultBucket != "metrics" {
+		t.Errorf("unexpected datasource %+v with settings %+v", got, settings)
+	}
+	if secrets := got.SecureJSONData.(*sdk.InfluxDBSecureJSONData); secrets.Token != "token" {
+		t.Errorf("unexpected secrets %+v", secrets)
+	}
+}
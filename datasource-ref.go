@@ -0,0 +1,306 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema versions of the dashboards since which Grafana references
+// datasources by objects with type and UID instead of names. Panels and
+// targets were migrated in Grafana 8.3, annotations and variables in
+// Grafana 9.0.
+const (
+	DatasourceRefSchemaVersion           = 33
+	DatasourceRefAnnotationSchemaVersion = 36
+)
+
+// Names of the special datasources in the legacy references.
+const (
+	GrafanaSource   = "-- Grafana --"
+	DashboardSource = "-- Dashboard --"
+	DefaultSource   = "default"
+)
+
+// DatasourceRef references a datasource from panels, targets, variables
+// and annotations. Grafana before 8.3 references datasources by name (the
+// legacy form), later versions by an object with the plugin type and the
+// datasource UID. Both forms are decoded, Name is set for the legacy
+// form only. Reference is marshalled as a name string while it has no
+// type and UID, as an object otherwise, the empty object is kept as it
+// was. A nil reference stands for the default datasource.
+type DatasourceRef struct {
+	Type string `json:"type,omitempty"`
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"-"`
+	// emptyObject is set for the reference decoded from the object
+	// without type and UID
+	emptyObject bool
+}
+
+// NewDatasourceRef references the datasource by its plugin type and UID.
+func NewDatasourceRef(pluginType, uid string) *DatasourceRef {
+	return &DatasourceRef{Type: pluginType, UID: uid}
+}
+
+// NewDatasourceRefByName references the datasource by name in the legacy
+// form, the name may be a variable like "${datasource}".
+func NewDatasourceRefByName(name string) *DatasourceRef {
+	return &DatasourceRef{Name: name}
+}
+
+// IsLegacy reports whether the datasource is referenced by name.
+func (r *DatasourceRef) IsLegacy() bool {
+	return r.Type == "" && r.UID == "" && r.Name != ""
+}
+
+// IsVariable reports whether the datasource is selected by a variable.
+func (r *DatasourceRef) IsVariable() bool {
+	return strings.Contains(r.String(), "$")
+}
+
+// String returns the UID of the datasource or the name for the legacy
+// reference.
+func (r *DatasourceRef) String() string {
+	if r.UID != "" {
+		return r.UID
+	}
+	return r.Name
+}
+
+// UnmarshalJSON decodes both the name and the object forms.
+func (r *DatasourceRef) UnmarshalJSON(raw []byte) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		*r = DatasourceRef{}
+		return json.Unmarshal(raw, &r.Name)
+	}
+	type plain DatasourceRef
+	var ref plain
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return fmt.Errorf("datasource should be a name or an object with type and uid: %w", err)
+	}
+	*r = DatasourceRef(ref)
+	r.emptyObject = r.Type == "" && r.UID == ""
+	return nil
+}
+
+// MarshalJSON encodes the reference in the legacy form if it has no type
+// and UID, in the object form otherwise or if it was decoded from the
+// empty object.
+func (r DatasourceRef) MarshalJSON() ([]byte, error) {
+	if r.Type == "" && r.UID == "" && (!r.emptyObject || r.Name != "") {
+		return json.Marshal(r.Name)
+	}
+	type plain DatasourceRef
+	return json.Marshal(plain(r))
+}
+
+// upgraded converts the legacy reference to the object form in the same
+// way as Grafana does on the dashboard migration when the datasource is
+// unknown: the name becomes the UID.
+func (r DatasourceRef) upgraded() DatasourceRef {
+	if !r.IsLegacy() {
+		return r
+	}
+	switch r.Name {
+	case MixedSource, DashboardSource:
+		return DatasourceRef{Type: "datasource", UID: r.Name, Name: r.Name}
+	case GrafanaSource:
+		return DatasourceRef{Type: "datasource", UID: "grafana", Name: r.Name}
+	}
+	return DatasourceRef{UID: r.Name, Name: r.Name}
+}
+
+// datasourceRefs calls fn for every datasource reference of the board.
+// Panels and targets are visited before annotations and variables, the
+// latter are marked with the late flag.
+func (b *Board) datasourceRefs(fn func(ref **DatasourceRef, late bool)) {
+	var visitPanel func(p *Panel)
+	visitPanel = func(p *Panel) {
+		fn(&p.Datasource, false)
+		if targets := p.GetTargets(); targets != nil {
+			for i := range *targets {
+				fn(&(*targets)[i].Datasource, false)
+			}
+		}
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				visitPanel(&p.RowPanel.Panels[i])
+			}
+		}
+	}
+	for _, p := range b.Panels {
+		visitPanel(p)
+	}
+	for _, r := range b.Rows {
+		for i := range r.Panels {
+			visitPanel(&r.Panels[i])
+		}
+	}
+	for i := range b.Annotations.List {
+		fn(&b.Annotations.List[i].Datasource, true)
+	}
+	for i := range b.Templating.List {
+		fn(&b.Templating.List[i].Datasource, true)
+	}
+}
+
+// ResolveDatasourceRefs rewrites the legacy references by name to the
+// references by type and UID of the datasources from the list, e.g. got
+// with Client.GetAllDatasources(). References to the default datasource
+// and the special datasources are converted as Grafana does, references
+// by variables keep the variable as the UID. Object references without
+// type get the type of the datasource with the same UID. When all
+// references are resolved the schema version is raised to the one
+// Grafana uses for the object references. Unknown names are left as is
+// and reported by the error.
+func (b *Board) ResolveDatasourceRefs(datasources []Datasource) error {
+	var (
+		byName  = make(map[string]Datasource, len(datasources))
+		byUID   = make(map[string]Datasource, len(datasources))
+		unknown = make(map[string]bool)
+	)
+	for _, ds := range datasources {
+		byName[ds.Name] = ds
+		byUID[ds.UID] = ds
+		if ds.IsDefault {
+			byName[DefaultSource] = ds
+		}
+	}
+	b.datasourceRefs(func(ref **DatasourceRef, _ bool) {
+		r := *ref
+		if r == nil {
+			return
+		}
+		if !r.IsLegacy() {
+			if ds, ok := byUID[r.UID]; ok && r.Type == "" {
+				*ref = &DatasourceRef{Type: ds.Type, UID: r.UID, Name: r.Name}
+			}
+			return
+		}
+		if ds, ok := byName[r.Name]; ok {
+			*ref = &DatasourceRef{Type: ds.Type, UID: ds.UID, Name: r.Name}
+			return
+		}
+		switch r.Name {
+		case MixedSource, DashboardSource, GrafanaSource:
+			upgraded := r.upgraded()
+			*ref = &upgraded
+		case DefaultSource:
+			// Grafana uses null for the default datasource
+			*ref = nil
+		default:
+			if r.IsVariable() {
+				upgraded := r.upgraded()
+				*ref = &upgraded
+				return
+			}
+			unknown[r.Name] = true
+		}
+	})
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+		for name := range unknown {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown datasources: %s", strings.Join(names, ", "))
+	}
+	if b.SchemaVersion < DatasourceRefAnnotationSchemaVersion {
+		b.SchemaVersion = DatasourceRefAnnotationSchemaVersion
+	}
+	return nil
+}
+
+// hasLegacyDatasourceRefs reports whether the board keeps references by
+// name where its schema version requires the object references.
+func (b *Board) hasLegacyDatasourceRefs() bool {
+	var found bool
+	if b.SchemaVersion < DatasourceRefSchemaVersion {
+		return false
+	}
+	b.datasourceRefs(func(ref **DatasourceRef, late bool) {
+		if *ref != nil && (*ref).IsLegacy() && (!late || b.SchemaVersion >= DatasourceRefAnnotationSchemaVersion) {
+			found = true
+		}
+	})
+	return found
+}
+
+// upgradeDatasourceRefsJSON rewrites references by name in the encoded
+// board to the object form required by its schema version. The board
+// itself is not changed by marshalling so the rewrite is done on JSON.
+func upgradeDatasourceRefsJSON(raw []byte, schemaVersion uint) ([]byte, error) {
+	var board map[string]interface{}
+	if err := decodeWithNumbers(raw, &board); err != nil {
+		return nil, err
+	}
+	upgrade := func(obj interface{}) {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if name, ok := m["datasource"].(string); ok {
+			ref := DatasourceRef{Name: name}.upgraded()
+			upgradedRef := map[string]interface{}{"uid": ref.UID}
+			if ref.Type != "" {
+				upgradedRef["type"] = ref.Type
+			}
+			m["datasource"] = upgradedRef
+		}
+	}
+	list := func(v interface{}) []interface{} {
+		l, _ := v.([]interface{})
+		return l
+	}
+	var visitPanels func(panels []interface{})
+	visitPanels = func(panels []interface{}) {
+		for _, p := range panels {
+			upgrade(p)
+			if m, ok := p.(map[string]interface{}); ok {
+				for _, t := range list(m["targets"]) {
+					upgrade(t)
+				}
+				visitPanels(list(m["panels"]))
+			}
+		}
+	}
+	visitPanels(list(board["panels"]))
+	for _, r := range list(board["rows"]) {
+		if m, ok := r.(map[string]interface{}); ok {
+			visitPanels(list(m["panels"]))
+		}
+	}
+	if schemaVersion >= DatasourceRefAnnotationSchemaVersion {
+		for _, key := range []string{"annotations", "templating"} {
+			if m, ok := board[key].(map[string]interface{}); ok {
+				for _, item := range list(m["list"]) {
+					upgrade(item)
+				}
+			}
+		}
+	}
+	return json.Marshal(board)
+}
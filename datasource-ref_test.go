@@ -0,0 +1,175 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestDatasourceRef_Unmarshal(t *testing.T) {
+	var target struct {
+		A *sdk.DatasourceRef `json:"a"`
+		B *sdk.DatasourceRef `json:"b"`
+		C *sdk.DatasourceRef `json:"c"`
+	}
+	raw := `{"a": "Prometheus", "b": {"type": "loki", "uid": "P8E80F9AEF21F6940"}, "c": null}`
+	if err := json.Unmarshal([]byte(raw), &target); err != nil {
+		t.Fatal(err)
+	}
+	if !target.A.IsLegacy() || target.A.Name != "Prometheus" {
+		t.Errorf("unexpected legacy reference %+v", target.A)
+	}
+	if target.B.IsLegacy() || target.B.Type != "loki" || target.B.UID != "P8E80F9AEF21F6940" {
+		t.Errorf("unexpected object reference %+v", target.B)
+	}
+	if target.C != nil {
+		t.Errorf("expected nil reference, got %+v", target.C)
+	}
+	if err := json.Unmarshal([]byte(`{"a": 1}`), &target); err == nil {
+		t.Error("expected error for a number")
+	}
+}
+
+func TestDatasourceRef_Marshal(t *testing.T) {
+	for _, tc := range []struct {
+		ref *sdk.DatasourceRef
+		exp string
+	}{
+		{sdk.NewDatasourceRefByName("Prometheus"), `"Prometheus"`},
+		{sdk.NewDatasourceRef("prometheus", "abc"), `{"type":"prometheus","uid":"abc"}`},
+		{&sdk.DatasourceRef{UID: "${ds}", Name: "${ds}"}, `{"uid":"${ds}"}`},
+	} {
+		raw, err := json.Marshal(tc.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(raw) != tc.exp {
+			t.Errorf("expected %s, got %s", tc.exp, raw)
+		}
+	}
+}
+
+func TestDatasourceRef_EmptyObject(t *testing.T) {
+	var ref sdk.DatasourceRef
+	if err := json.Unmarshal([]byte(`{}`), &ref); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{}` {
+		t.Errorf("expected the empty object kept, got %s", raw)
+	}
+	if raw, _ = json.Marshal(sdk.DatasourceRef{}); string(raw) != `""` {
+		t.Errorf("expected the empty name for the zero reference, got %s", raw)
+	}
+}
+
+func legacyRefsBoard(schemaVersion uint) *sdk.Board {
+	b := sdk.NewBoard("Datasources")
+	b.SchemaVersion = schemaVersion
+	p := sdk.NewTimeseries("Requests")
+	p.Datasource = sdk.NewDatasourceRefByName(sdk.MixedSource)
+	p.AddTarget(&sdk.Target{RefID: "A", Datasource: sdk.NewDatasourceRefByName("Prometheus")})
+	p.AddTarget(&sdk.Target{RefID: "B", Datasource: sdk.NewDatasourceRefByName("$logs")})
+	b.AddPanel(p)
+	b.Annotations.List = append(b.Annotations.List, sdk.Annotation{Name: "Deploys", Datasource: sdk.NewDatasourceRefByName(sdk.GrafanaSource)})
+	_ = b.AddVariable(sdk.NewQueryVariable("job", sdk.NewDatasourceRefByName(sdk.DefaultSource), "label_values(job)"))
+	return b
+}
+
+func marshalGeneric(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestBoard_MarshalDatasourceRefsBySchema(t *testing.T) {
+	panelDS := func(m map[string]interface{}) (interface{}, interface{}) {
+		panel := m["panels"].([]interface{})[0].(map[string]interface{})
+		return panel["datasource"], panel["targets"].([]interface{})[0].(map[string]interface{})["datasource"]
+	}
+	annotationDS := func(m map[string]interface{}) interface{} {
+		return m["annotations"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})["datasource"]
+	}
+
+	old := marshalGeneric(t, legacyRefsBoard(27))
+	if p, tg := panelDS(old); p != sdk.MixedSource || tg != "Prometheus" {
+		t.Errorf("expected names for schema 27, got %v and %v", p, tg)
+	}
+
+	b := legacyRefsBoard(sdk.DatasourceRefSchemaVersion)
+	m := marshalGeneric(t, b)
+	p, tg := panelDS(m)
+	if p.(map[string]interface{})["type"] != "datasource" || p.(map[string]interface{})["uid"] != sdk.MixedSource {
+		t.Errorf("unexpected mixed datasource %v", p)
+	}
+	if tg.(map[string]interface{})["uid"] != "Prometheus" {
+		t.Errorf("unexpected target datasource %v", tg)
+	}
+	if annotationDS(m) != sdk.GrafanaSource {
+		t.Errorf("annotations should keep names before schema 36, got %v", annotationDS(m))
+	}
+	if !b.Panels[0].Datasource.IsLegacy() {
+		t.Error("marshalling should not change the board")
+	}
+
+	m = marshalGeneric(t, legacyRefsBoard(sdk.DatasourceRefAnnotationSchemaVersion))
+	if ds, ok := annotationDS(m).(map[string]interface{}); !ok || ds["uid"] != "grafana" {
+		t.Errorf("unexpected annotation datasource %v", annotationDS(m))
+	}
+}
+
+func TestBoard_ResolveDatasourceRefs(t *testing.T) {
+	datasources := []sdk.Datasource{
+		{UID: "prom1", Name: "Prometheus", Type: "prometheus", IsDefault: true},
+		{UID: "loki1", Name: "Loki", Type: "loki"},
+	}
+	b := legacyRefsBoard(27)
+	b.Panels[0].AddTarget(&sdk.Target{RefID: "C", Datasource: sdk.NewDatasourceRef("", "loki1")})
+	if err := b.ResolveDatasourceRefs(datasources); err != nil {
+		t.Fatal(err)
+	}
+	targets := *b.Panels[0].GetTargets()
+	if ds := targets[0].Datasource; ds.Type != "prometheus" || ds.UID != "prom1" || ds.Name != "Prometheus" {
+		t.Errorf("unexpected resolved datasource %+v", ds)
+	}
+	if ds := targets[1].Datasource; ds.UID != "$logs" || ds.IsLegacy() {
+		t.Errorf("unexpected variable datasource %+v", ds)
+	}
+	if ds := targets[2].Datasource; ds.Type != "loki" {
+		t.Errorf("expected type by UID, got %+v", ds)
+	}
+	if ds := b.Panels[0].Datasource; ds.Type != "datasource" || ds.UID != sdk.MixedSource {
+		t.Errorf("unexpected mixed datasource %+v", ds)
+	}
+	if ds := b.Templating.List[0].Datasource; ds == nil || ds.UID != "prom1" {
+		t.Errorf("expected default datasource resolved, got %+v", ds)
+	}
+	if b.SchemaVersion != sdk.DatasourceRefAnnotationSchemaVersion {
+		t.Errorf("expected schema version raised, got %d", b.SchemaVersion)
+	}
+
+	b = legacyRefsBoard(27)
+	b.Panels[0].AddTarget(&sdk.Target{RefID: "D", Datasource: sdk.NewDatasourceRefByName("Graphite")})
+	err := b.ResolveDatasourceRefs(datasources[1:])
+	if err == nil || !strings.Contains(err.Error(), "Graphite") || !strings.Contains(err.Error(), "Prometheus") {
+		t.Errorf("expected unknown datasources reported, got %v", err)
+	}
+	if b.Templating.List[0].Datasource != nil {
+		t.Error("expected default datasource to become null without the default one in the list")
+	}
+	if b.SchemaVersion != 27 {
+		t.Errorf("schema version should not change on error, got %d", b.SchemaVersion)
+	}
+}
@@ -0,0 +1,438 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+)
+
+// Types of the datasource plugins with typed settings.
+const (
+	DatasourceTypePrometheus    = "prometheus"
+	DatasourceTypeLoki          = "loki"
+	DatasourceTypeElasticsearch = "elasticsearch"
+	DatasourceTypePostgres      = "postgres"
+	DatasourceTypeMySQL         = "mysql"
+	DatasourceTypeInfluxDB      = "influxdb"
+	DatasourceTypeCloudWatch    = "cloudwatch"
+)
+
+// Settings of the datasources differ between the plugins. Datasources of
+// the types above are decoded with their JSONData and SecureJSONData of
+// the structures below, e.g. *PrometheusJSONData, other datasources keep
+// the settings as map[string]interface{}. Any value marshalling to JSON
+// object is accepted on creating and updating datasources. Like targets
+// the structures keep the JSON they were decoded from so settings unknown
+// to the SDK and values that don't fit the field types are kept as they
+// were.
+
+// DatasourceHTTPSettings are the settings shared by the datasources
+// querying their backends over HTTP.
+type DatasourceHTTPSettings struct {
+	HTTPMethod        string   `json:"httpMethod,omitempty"`
+	Timeout           int      `json:"timeout,omitempty"` // seconds
+	KeepCookies       []string `json:"keepCookies,omitempty"`
+	TLSAuth           bool     `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert bool     `json:"tlsAuthWithCACert,omitempty"`
+	TLSSkipVerify     bool     `json:"tlsSkipVerify,omitempty"`
+	ServerName        string   `json:"serverName,omitempty"`
+	OauthPassThru     bool     `json:"oauthPassThru,omitempty"`
+}
+
+// PrometheusJSONData are the settings of the Prometheus datasource.
+type PrometheusJSONData struct {
+	DatasourceHTTPSettings
+	TimeInterval                string                       `json:"timeInterval,omitempty"` // scrape interval
+	QueryTimeout                string                       `json:"queryTimeout,omitempty"`
+	PrometheusType              string                       `json:"prometheusType,omitempty"` // Prometheus, Cortex, Mimir or Thanos
+	PrometheusVersion           string                       `json:"prometheusVersion,omitempty"`
+	CacheLevel                  string                       `json:"cacheLevel,omitempty"`
+	IncrementalQuerying         bool                         `json:"incrementalQuerying,omitempty"`
+	DisableMetricsLookup        bool                         `json:"disableMetricsLookup,omitempty"`
+	CustomQueryParameters       string                       `json:"customQueryParameters,omitempty"`
+	ExemplarTraceIDDestinations []ExemplarTraceIDDestination `json:"exemplarTraceIdDestinations,omitempty"`
+
+	keptSource
+}
+
+// ExemplarTraceIDDestination links trace IDs of Prometheus exemplars to
+// the tracing datasource by its UID or to the external URL.
+type ExemplarTraceIDDestination struct {
+	Name          string `json:"name"`
+	DatasourceUID string `json:"datasourceUid,omitempty"`
+	URL           string `json:"url,omitempty"`
+}
+
+// LokiJSONData are the settings of the Loki datasource.
+type LokiJSONData struct {
+	DatasourceHTTPSettings
+	MaxLines      string             `json:"maxLines,omitempty"`
+	DerivedFields []LokiDerivedField `json:"derivedFields,omitempty"`
+
+	keptSource
+}
+
+// LokiDerivedField extracts a value from the log line, e.g. trace ID, and
+// links it to the datasource by its UID or to the URL.
+type LokiDerivedField struct {
+	Name          string `json:"name"`
+	MatcherRegex  string `json:"matcherRegex"`
+	MatcherType   string `json:"matcherType,omitempty"` // regex or label
+	URL           string `json:"url,omitempty"`
+	URLDisplay    string `json:"urlDisplayLabel,omitempty"`
+	DatasourceUID string `json:"datasourceUid,omitempty"`
+}
+
+// ElasticsearchJSONData are the settings of the Elasticsearch datasource.
+type ElasticsearchJSONData struct {
+	DatasourceHTTPSettings
+	Index                      string `json:"index,omitempty"`
+	TimeField                  string `json:"timeField,omitempty"`
+	Interval                   string `json:"interval,omitempty"` // Hourly, Daily, Weekly, Monthly or Yearly
+	TimeInterval               string `json:"timeInterval,omitempty"`
+	MaxConcurrentShardRequests int    `json:"maxConcurrentShardRequests,omitempty"`
+	LogMessageField            string `json:"logMessageField,omitempty"`
+	LogLevelField              string `json:"logLevelField,omitempty"`
+	IncludeFrozen              bool   `json:"includeFrozen,omitempty"`
+
+	keptSource
+}
+
+// SQLJSONData are the connection settings shared by the SQL datasources.
+type SQLJSONData struct {
+	Database         string `json:"database,omitempty"`
+	TimeInterval     string `json:"timeInterval,omitempty"`
+	MaxOpenConns     int    `json:"maxOpenConns,omitempty"`
+	MaxIdleConns     int    `json:"maxIdleConns,omitempty"`
+	MaxIdleConnsAuto bool   `json:"maxIdleConnsAuto,omitempty"`
+	ConnMaxLifetime  int    `json:"connMaxLifetime,omitempty"` // seconds
+}
+
+// PostgresJSONData are the settings of the PostgreSQL datasource.
+type PostgresJSONData struct {
+	SQLJSONData
+	SSLMode                string `json:"sslmode,omitempty"`
+	PostgresVersion        int    `json:"postgresVersion,omitempty"` // e.g. 1200 for 12
+	TimescaleDB            bool   `json:"timescaledb,omitempty"`
+	TLSConfigurationMethod string `json:"tlsConfigurationMethod,omitempty"` // file-path or file-content
+	SSLRootCertFile        string `json:"sslRootCertFile,omitempty"`
+	SSLCertFile            string `json:"sslCertFile,omitempty"`
+	SSLKeyFile             string `json:"sslKeyFile,omitempty"`
+
+	keptSource
+}
+
+// MySQLJSONData are the settings of the MySQL datasource.
+type MySQLJSONData struct {
+	SQLJSONData
+	TLSAuth           bool   `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert bool   `json:"tlsAuthWithCACert,omitempty"`
+	TLSSkipVerify     bool   `json:"tlsSkipVerify,omitempty"`
+	Timezone          string `json:"timezone,omitempty"`
+
+	keptSource
+}
+
+// InfluxDBJSONData are the settings of the InfluxDB datasource. Version
+// is the query language: InfluxQL, Flux or SQL. DBName is used by
+// InfluxQL, Organization and DefaultBucket are used by Flux.
+type InfluxDBJSONData struct {
+	DatasourceHTTPSettings
+	Version       string `json:"version,omitempty"`
+	DBName        string `json:"dbName,omitempty"`
+	Organization  string `json:"organization,omitempty"`
+	DefaultBucket string `json:"defaultBucket,omitempty"`
+	TimeInterval  string `json:"timeInterval,omitempty"`
+	MaxSeries     int    `json:"maxSeries,omitempty"`
+
+	keptSource
+}
+
+// CloudWatchJSONData are the settings of the CloudWatch datasource.
+type CloudWatchJSONData struct {
+	AuthType                string `json:"authType,omitempty"` // default, keys, credentials or ec2_iam_role
+	DefaultRegion           string `json:"defaultRegion,omitempty"`
+	AssumeRoleARN           string `json:"assumeRoleArn,omitempty"`
+	ExternalID              string `json:"externalId,omitempty"`
+	Profile                 string `json:"profile,omitempty"`
+	Endpoint                string `json:"endpoint,omitempty"`
+	CustomMetricsNamespaces string `json:"customMetricsNamespaces,omitempty"`
+	LogsTimeout             string `json:"logsTimeout,omitempty"`
+	TracingDatasourceUID    string `json:"tracingDatasourceUid,omitempty"`
+
+	keptSource
+}
+
+// HTTPSecureJSONData are the secrets of the Prometheus, Loki and
+// Elasticsearch datasources. Grafana never returns the secrets, only
+// their presence in Datasource.SecureJSONFields.
+type HTTPSecureJSONData struct {
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"`
+	TLSCACert         string `json:"tlsCACert,omitempty"`
+	TLSClientCert     string `json:"tlsClientCert,omitempty"`
+	TLSClientKey      string `json:"tlsClientKey,omitempty"`
+
+	keptSource
+}
+
+// SQLSecureJSONData are the secrets of the PostgreSQL and MySQL
+// datasources.
+type SQLSecureJSONData struct {
+	Password      string `json:"password,omitempty"`
+	TLSCACert     string `json:"tlsCACert,omitempty"`
+	TLSClientCert string `json:"tlsClientCert,omitempty"`
+	TLSClientKey  string `json:"tlsClientKey,omitempty"`
+
+	keptSource
+}
+
+// InfluxDBSecureJSONData are the secrets of the InfluxDB datasource: the
+// password for InfluxQL and the token for Flux and SQL.
+type InfluxDBSecureJSONData struct {
+	Password          string `json:"password,omitempty"`
+	Token             string `json:"token,omitempty"`
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"`
+	TLSCACert         string `json:"tlsCACert,omitempty"`
+	TLSClientCert     string `json:"tlsClientCert,omitempty"`
+	TLSClientKey      string `json:"tlsClientKey,omitempty"`
+
+	keptSource
+}
+
+// CloudWatchSecureJSONData are the access keys of the CloudWatch
+// datasource with "keys" authentication.
+type CloudWatchSecureJSONData struct {
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+
+	keptSource
+}
+
+// newDatasourceSettings returns the typed JSONData and SecureJSONData of
+// the datasource type or nils for types without them.
+func newDatasourceSettings(dsType string) (json.Unmarshaler, json.Unmarshaler) {
+	switch dsType {
+	case DatasourceTypePrometheus:
+		return &PrometheusJSONData{}, &HTTPSecureJSONData{}
+	case DatasourceTypeLoki:
+		return &LokiJSONData{}, &HTTPSecureJSONData{}
+	case DatasourceTypeElasticsearch:
+		return &ElasticsearchJSONData{}, &HTTPSecureJSONData{}
+	case DatasourceTypePostgres, "grafana-postgresql-datasource":
+		return &PostgresJSONData{}, &SQLSecureJSONData{}
+	case DatasourceTypeMySQL:
+		return &MySQLJSONData{}, &SQLSecureJSONData{}
+	case DatasourceTypeInfluxDB:
+		return &InfluxDBJSONData{}, &InfluxDBSecureJSONData{}
+	case DatasourceTypeCloudWatch:
+		return &CloudWatchJSONData{}, &CloudWatchSecureJSONData{}
+	}
+	return nil, nil
+}
+
+// UnmarshalJSON decodes the datasource with the typed settings for the
+// known datasource types.
+func (ds *Datasource) UnmarshalJSON(data []byte) error {
+	type plain Datasource
+	var d struct {
+		plain
+		JSONData       json.RawMessage `json:"jsonData"`
+		SecureJSONData json.RawMessage `json:"secureJsonData"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	*ds = Datasource(d.plain)
+	var err error
+	jsonData, secureJSONData := newDatasourceSettings(ds.Type)
+	if ds.JSONData, err = decodeSettings(d.JSONData, jsonData); err != nil {
+		return err
+	}
+	ds.SecureJSONData, err = decodeSettings(d.SecureJSONData, secureJSONData)
+	return err
+}
+
+// decodeSettings decodes the settings object into the typed value or
+// into the map if typed is nil. Null and absent settings are nil.
+func decodeSettings(raw json.RawMessage, typed json.Unmarshaler) (interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if typed == nil {
+		var settings map[string]interface{}
+		err := json.Unmarshal(raw, &settings)
+		return settings, err
+	}
+	return typed, typed.UnmarshalJSON(raw)
+}
+
+// UnmarshalJSON decodes the Prometheus settings and keeps the source JSON.
+func (d *PrometheusJSONData) UnmarshalJSON(data []byte) error {
+	type plain PrometheusJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the Prometheus settings with keys of the source JSON preserved.
+func (d PrometheusJSONData) MarshalJSON() ([]byte, error) {
+	type plain PrometheusJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the Loki settings and keeps the source JSON.
+func (d *LokiJSONData) UnmarshalJSON(data []byte) error {
+	type plain LokiJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the Loki settings with keys of the source JSON preserved.
+func (d LokiJSONData) MarshalJSON() ([]byte, error) {
+	type plain LokiJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the Elasticsearch settings and keeps the source JSON.
+func (d *ElasticsearchJSONData) UnmarshalJSON(data []byte) error {
+	type plain ElasticsearchJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the Elasticsearch settings with keys of the source JSON preserved.
+func (d ElasticsearchJSONData) MarshalJSON() ([]byte, error) {
+	type plain ElasticsearchJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the PostgreSQL settings and keeps the source JSON.
+func (d *PostgresJSONData) UnmarshalJSON(data []byte) error {
+	type plain PostgresJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the PostgreSQL settings with keys of the source JSON preserved.
+func (d PostgresJSONData) MarshalJSON() ([]byte, error) {
+	type plain PostgresJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the MySQL settings and keeps the source JSON.
+func (d *MySQLJSONData) UnmarshalJSON(data []byte) error {
+	type plain MySQLJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the MySQL settings with keys of the source JSON preserved.
+func (d MySQLJSONData) MarshalJSON() ([]byte, error) {
+	type plain MySQLJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the InfluxDB settings and keeps the source JSON.
+func (d *InfluxDBJSONData) UnmarshalJSON(data []byte) error {
+	type plain InfluxDBJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the InfluxDB settings with keys of the source JSON preserved.
+func (d InfluxDBJSONData) MarshalJSON() ([]byte, error) {
+	type plain InfluxDBJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the CloudWatch settings and keeps the source JSON.
+func (d *CloudWatchJSONData) UnmarshalJSON(data []byte) error {
+	type plain CloudWatchJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the CloudWatch settings with keys of the source JSON preserved.
+func (d CloudWatchJSONData) MarshalJSON() ([]byte, error) {
+	type plain CloudWatchJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the secrets and keeps the source JSON.
+func (d *HTTPSecureJSONData) UnmarshalJSON(data []byte) error {
+	type plain HTTPSecureJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the secrets with keys of the source JSON preserved.
+func (d HTTPSecureJSONData) MarshalJSON() ([]byte, error) {
+	type plain HTTPSecureJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the secrets and keeps the source JSON.
+func (d *SQLSecureJSONData) UnmarshalJSON(data []byte) error {
+	type plain SQLSecureJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the secrets with keys of the source JSON preserved.
+func (d SQLSecureJSONData) MarshalJSON() ([]byte, error) {
+	type plain SQLSecureJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the secrets and keeps the source JSON.
+func (d *InfluxDBSecureJSONData) UnmarshalJSON(data []byte) error {
+	type plain InfluxDBSecureJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the secrets with keys of the source JSON preserved.
+func (d InfluxDBSecureJSONData) MarshalJSON() ([]byte, error) {
+	type plain InfluxDBSecureJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
+
+// UnmarshalJSON decodes the secrets and keeps the source JSON.
+func (d *CloudWatchSecureJSONData) UnmarshalJSON(data []byte) error {
+	type plain CloudWatchSecureJSONData
+	var err error
+	d.source, d.skipped, err = decodeLossless(data, (*plain)(d))
+	return err
+}
+
+// MarshalJSON marshals the secrets with keys of the source JSON preserved.
+func (d CloudWatchSecureJSONData) MarshalJSON() ([]byte, error) {
+	type plain CloudWatchSecureJSONData
+	return encodeLossless(plain(d), d.source, d.skipped)
+}
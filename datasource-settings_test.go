@@ -0,0 +1,89 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestDatasource_UnmarshalJSON_TypedSettings(t *testing.T) {
+	raw := `{"id": 1, "uid": "prom", "name": "Prometheus", "type": "prometheus", "url": "http://prometheus:9090",
+		"jsonData": {"httpMethod": "POST", "timeInterval": "30s", "timeout": "60", "manageAlerts": true,
+			"exemplarTraceIdDestinations": [{"name": "traceID", "datasourceUid": "tempo"}]},
+		"secureJsonFields": {"basicAuthPassword": true}, "version": 3}`
+	var ds sdk.Datasource
+	if err := json.Unmarshal([]byte(raw), &ds); err != nil {
+		t.Fatal(err)
+	}
+	settings, ok := ds.JSONData.(*sdk.PrometheusJSONData)
+	if !ok {
+		t.Fatalf("expected Prometheus settings, got %T", ds.JSONData)
+	}
+	if settings.HTTPMethod != "POST" || settings.TimeInterval != "30s" || settings.ExemplarTraceIDDestinations[0].DatasourceUID != "tempo" {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if skipped := settings.Skipped(); len(skipped) != 1 || skipped[0] != "timeout" {
+		t.Errorf("timeout of the wrong type should be skipped, got %v", skipped)
+	}
+	if ds.SecureJSONData != nil || !ds.SecureJSONFields["basicAuthPassword"] || ds.Version != 3 {
+		t.Errorf("unexpected datasource %+v", ds)
+	}
+
+	settings.TimeInterval = "15s"
+	b, err := json.Marshal(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		JSONData map[string]interface{} `json:"jsonData"`
+	}
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	// the timeout of the wrong type and unknown keys are kept
+	if got.JSONData["timeInterval"] != "15s" || got.JSONData["timeout"] != "60" || got.JSONData["manageAlerts"] != true {
+		t.Errorf("unexpected settings after marshalling %s", b)
+	}
+}
+
+func TestDatasource_UnmarshalJSON_SettingsByType(t *testing.T) {
+	for dsType, exp := range map[string]interface{}{
+		sdk.DatasourceTypeLoki:          &sdk.LokiJSONData{},
+		sdk.DatasourceTypeElasticsearch: &sdk.ElasticsearchJSONData{},
+		sdk.DatasourceTypePostgres:      &sdk.PostgresJSONData{},
+		sdk.DatasourceTypeMySQL:         &sdk.MySQLJSONData{},
+		sdk.DatasourceTypeInfluxDB:      &sdk.InfluxDBJSONData{},
+		sdk.DatasourceTypeCloudWatch:    &sdk.CloudWatchJSONData{},
+		"graphite":                      map[string]interface{}{},
+	} {
+		var ds sdk.Datasource
+		if err := json.Unmarshal([]byte(`{"type": "`+dsType+`", "jsonData": {}}`), &ds); err != nil {
+			t.Fatal(err)
+		}
+		if gotType, expType := fmt.Sprintf("%T", ds.JSONData), fmt.Sprintf("%T", exp); gotType != expType {
+			t.Errorf("%s: expected %s, got %s", dsType, expType, gotType)
+		}
+	}
+
+	var ds sdk.Datasource
+	raw := `{"type": "postgres", "jsonData": {"database": "metrics", "sslmode": "disable", "postgresVersion": 1500},
+		"secureJsonData": {"password": "secret"}}`
+	if err := json.Unmarshal([]byte(raw), &ds); err != nil {
+		t.Fatal(err)
+	}
+	pg := ds.JSONData.(*sdk.PostgresJSONData)
+	if pg.Database != "metrics" || pg.SSLMode != "disable" || pg.PostgresVersion != 1500 {
+		t.Errorf("unexpected settings %+v", pg)
+	}
+	if secrets := ds.SecureJSONData.(*sdk.SQLSecureJSONData); secrets.Password != "secret" {
+		t.Errorf("unexpected secrets %+v", secrets)
+	}
+	if err := json.Unmarshal([]byte(`{"type": "loki"}`), &ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.JSONData != nil || ds.SecureJSONData != nil {
+		t.Errorf("expected nil settings, got %v and %v", ds.JSONData, ds.SecureJSONData)
+	}
+}
@@ -19,27 +19,60 @@ package sdk
    ॐ तारे तुत्तारे तुरे स्व
 */
 
+import "encoding/json"
+
 // Datasource as described in the doc
 // http://docs.grafana.org/reference/http_api/#get-all-datasources
+//
+// Incompatible change: JSONData and SecureJSONData of the datasource types
+// with typed settings are no longer decoded as map[string]interface{}, see
+// the type switch example in README.
 type Datasource struct {
-	ID                uint        `json:"id"`
-	OrgID             uint        `json:"orgId"`
-	UID               string      `json:"uid"`
-	Name              string      `json:"name"`
-	Type              string      `json:"type"`
-	TypeLogoURL       string      `json:"typeLogoUrl"`
-	Access            string      `json:"access"` // direct or proxy
-	URL               string      `json:"url"`
-	Password          *string     `json:"password,omitempty"`
-	User              *string     `json:"user,omitempty"`
-	Database          *string     `json:"database,omitempty"`
-	BasicAuth         *bool       `json:"basicAuth,omitempty"`
-	ReadOnly          *bool       `json:"readOnly,omitempty"`
-	BasicAuthUser     *string     `json:"basicAuthUser,omitempty"`
-	BasicAuthPassword *string     `json:"basicAuthPassword,omitempty"`
-	IsDefault         bool        `json:"isDefault"`
-	JSONData          interface{} `json:"jsonData"`
-	SecureJSONData    interface{} `json:"secureJsonData"`
+	ID                uint    `json:"id"`
+	OrgID             uint    `json:"orgId"`
+	UID               string  `json:"uid"`
+	Name              string  `json:"name"`
+	Type              string  `json:"type"`
+	TypeLogoURL       string  `json:"typeLogoUrl"`
+	Access            string  `json:"access"` // direct or proxy
+	URL               string  `json:"url"`
+	Password          *string `json:"password,omitempty"`
+	User              *string `json:"user,omitempty"`
+	Database          *string `json:"database,omitempty"`
+	BasicAuth         *bool   `json:"basicAuth,omitempty"`
+	ReadOnly          *bool   `json:"readOnly,omitempty"`
+	BasicAuthUser     *string `json:"basicAuthUser,omitempty"`
+	BasicAuthPassword *string `json:"basicAuthPassword,omitempty"`
+	IsDefault         bool    `json:"isDefault"`
+	Version           int     `json:"version,omitempty"`
+	// JSONData and SecureJSONData are decoded to the typed settings for
+	// the known datasource types, see DatasourceTypePrometheus, and to
+	// map[string]interface{} for the others.
+	JSONData       interface{} `json:"jsonData"`
+	SecureJSONData interface{} `json:"secureJsonData"`
+	// SecureJSONFields reports which secrets are set, Grafana never
+	// returns SecureJSONData itself.
+	SecureJSONFields map[string]bool `json:"secureJsonFields,omitempty"`
+}
+
+// Statuses of the datasource health check.
+const (
+	DatasourceHealthOK      = "OK"
+	DatasourceHealthError   = "ERROR"
+	DatasourceHealthUnknown = "UNKNOWN"
+)
+
+// DatasourceHealth is the result of the datasource health check. Details
+// are specific for the datasource plugin.
+type DatasourceHealth struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+// IsOK reports whether the datasource passed the health check.
+func (h DatasourceHealth) IsOK() bool {
+	return h.Status == DatasourceHealthOK
 }
 
 // Datasource type as described in
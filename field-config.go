@@ -0,0 +1,291 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Matcher IDs used in field config overrides.
+const (
+	FieldMatcherByName       = "byName"
+	FieldMatcherByRegexp     = "byRegexp"
+	FieldMatcherByType       = "byType"
+	FieldMatcherByFrameRefID = "byFrameRefID"
+	FieldMatcherByValue      = "byValue"
+)
+
+// Types of value mappings.
+const (
+	ValueMappingTypeValue   = "value"
+	ValueMappingTypeRange   = "range"
+	ValueMappingTypeRegex   = "regex"
+	ValueMappingTypeSpecial = "special"
+)
+
+// Values matched by a special value mapping.
+const (
+	SpecialMatchNull       = "null"
+	SpecialMatchNaN        = "nan"
+	SpecialMatchNullAndNaN = "null+nan"
+	SpecialMatchTrue       = "true"
+	SpecialMatchFalse      = "false"
+	SpecialMatchEmpty      = "empty"
+)
+
+type (
+	// ByValueMatcherOptions selects fields by reducing their values and
+	// comparing the result with Value, e.g. {"reducer": "max", "op": "gt", "value": 10}.
+	ByValueMatcherOptions struct {
+		Reducer string  `json:"reducer"`
+		Op      string  `json:"op"`
+		Value   float64 `json:"value"`
+	}
+
+	// ValueMapping represents a single mapping of the field config.
+	// Only one of options fields is used, the Type defines which one.
+	// Mappings of unknown types (and the numeric types of Grafana 7)
+	// are kept as is.
+	ValueMapping struct {
+		Type           string
+		ValueOptions   map[string]ValueMappingResult
+		RangeOptions   *RangeMappingOptions
+		RegexOptions   *RegexMappingOptions
+		SpecialOptions *SpecialMappingOptions
+		raw            json.RawMessage
+	}
+	ValueMappingResult struct {
+		Text  string `json:"text,omitempty"`
+		Color string `json:"color,omitempty"`
+		Icon  string `json:"icon,omitempty"`
+		Index int    `json:"index"`
+	}
+	RangeMappingOptions struct {
+		From   *float64           `json:"from"`
+		To     *float64           `json:"to"`
+		Result ValueMappingResult `json:"result"`
+	}
+	RegexMappingOptions struct {
+		Pattern string             `json:"pattern"`
+		Result  ValueMappingResult `json:"result"`
+	}
+	SpecialMappingOptions struct {
+		Match  string             `json:"match"`
+		Result ValueMappingResult `json:"result"`
+	}
+)
+
+// MatchFieldsByName selects fields by their display name.
+func MatchFieldsByName(name string) FieldMatcher {
+	return FieldMatcher{ID: FieldMatcherByName, Options: name}
+}
+
+// MatchFieldsByRegexp selects fields which display names match the regexp.
+func MatchFieldsByRegexp(re string) FieldMatcher {
+	return FieldMatcher{ID: FieldMatcherByRegexp, Options: re}
+}
+
+// MatchFieldsByType selects fields of the type (number, string, time, boolean).
+func MatchFieldsByType(fieldType string) FieldMatcher {
+	return FieldMatcher{ID: FieldMatcherByType, Options: fieldType}
+}
+
+// MatchFieldsByFrameRefID selects fields of frames returned by the query with refID.
+func MatchFieldsByFrameRefID(refID string) FieldMatcher {
+	return FieldMatcher{ID: FieldMatcherByFrameRefID, Options: refID}
+}
+
+// MatchFieldsByValue selects fields which reduced values satisfy the condition.
+func MatchFieldsByValue(opts ByValueMatcherOptions) FieldMatcher {
+	return FieldMatcher{ID: FieldMatcherByValue, Options: opts}
+}
+
+// UnmarshalJSON decodes options of known matchers to their types:
+// string for most of matchers and ByValueMatcherOptions for byValue.
+func (m *FieldMatcher) UnmarshalJSON(b []byte) error {
+	var probe struct {
+		ID      string          `json:"id"`
+		Options json.RawMessage `json:"options"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+	m.ID = probe.ID
+	m.Options = nil
+	if len(probe.Options) == 0 || string(probe.Options) == "null" {
+		return nil
+	}
+	switch probe.ID {
+	case FieldMatcherByName, FieldMatcherByRegexp, FieldMatcherByType, FieldMatcherByFrameRefID:
+		var opt string
+		if err := json.Unmarshal(probe.Options, &opt); err == nil {
+			m.Options = opt
+			return nil
+		}
+	case FieldMatcherByValue:
+		var opt ByValueMatcherOptions
+		if err := json.Unmarshal(probe.Options, &opt); err == nil {
+			m.Options = opt
+			return nil
+		}
+	}
+	return json.Unmarshal(probe.Options, &m.Options)
+}
+
+// NewFieldOverrideProperty makes a property for an override. The id is
+// the property path as Grafana names it, e.g. "unit" or "custom.width".
+func NewFieldOverrideProperty(id string, value interface{}) FieldOverrideProperty {
+	return FieldOverrideProperty{ID: id, Value: value}
+}
+
+// UnitProperty overrides the unit of the matched fields.
+func UnitProperty(unit string) FieldOverrideProperty {
+	return NewFieldOverrideProperty("unit", unit)
+}
+
+// DecimalsProperty overrides the decimals of the matched fields.
+func DecimalsProperty(decimals int) FieldOverrideProperty {
+	return NewFieldOverrideProperty("decimals", decimals)
+}
+
+// DisplayNameProperty overrides the display name of the matched fields.
+func DisplayNameProperty(name string) FieldOverrideProperty {
+	return NewFieldOverrideProperty("displayName", name)
+}
+
+// ColorProperty overrides the color scheme of the matched fields.
+func ColorProperty(color FieldConfigColor) FieldOverrideProperty {
+	return NewFieldOverrideProperty("color", color)
+}
+
+// ThresholdsProperty overrides the thresholds of the matched fields.
+func ThresholdsProperty(thresholds Thresholds) FieldOverrideProperty {
+	return NewFieldOverrideProperty("thresholds", thresholds)
+}
+
+// MappingsProperty overrides the value mappings of the matched fields.
+func MappingsProperty(mappings ...ValueMapping) FieldOverrideProperty {
+	return NewFieldOverrideProperty("mappings", mappings)
+}
+
+// LinksProperty overrides the data links of the matched fields.
+func LinksProperty(links ...Link) FieldOverrideProperty {
+	return NewFieldOverrideProperty("links", links)
+}
+
+// CustomProperty overrides the panel specific option, name is the path
+// inside of "custom" object, e.g. "width" or "hideFrom.legend".
+func CustomProperty(name string, value interface{}) FieldOverrideProperty {
+	return NewFieldOverrideProperty("custom."+name, value)
+}
+
+// AddOverride appends an override with the properties for the fields
+// selected by the matcher.
+func (fc *FieldConfig) AddOverride(matcher FieldMatcher, props ...FieldOverrideProperty) {
+	fc.Overrides = append(fc.Overrides, FieldConfigOverride{Matcher: matcher, Properties: props})
+}
+
+// NewValueMapping maps exact values to the results.
+func NewValueMapping(values map[string]ValueMappingResult) ValueMapping {
+	return ValueMapping{Type: ValueMappingTypeValue, ValueOptions: values}
+}
+
+// NewRangeMapping maps values in the range to the result. Nil bound
+// means the range is open from that side.
+func NewRangeMapping(from, to *float64, result ValueMappingResult) ValueMapping {
+	return ValueMapping{
+		Type:         ValueMappingTypeRange,
+		RangeOptions: &RangeMappingOptions{From: from, To: to, Result: result},
+	}
+}
+
+// NewRegexMapping maps values matching the pattern to the result.
+func NewRegexMapping(pattern string, result ValueMappingResult) ValueMapping {
+	return ValueMapping{
+		Type:         ValueMappingTypeRegex,
+		RegexOptions: &RegexMappingOptions{Pattern: pattern, Result: result},
+	}
+}
+
+// NewSpecialMapping maps special values (see SpecialMatch constants)
+// to the result.
+func NewSpecialMapping(match string, result ValueMappingResult) ValueMapping {
+	return ValueMapping{
+		Type:           ValueMappingTypeSpecial,
+		SpecialOptions: &SpecialMappingOptions{Match: match, Result: result},
+	}
+}
+
+func (m *ValueMapping) UnmarshalJSON(b []byte) error {
+	var probe struct {
+		Type    interface{}     `json:"type"`
+		Options json.RawMessage `json:"options"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+	*m = ValueMapping{}
+	mappingType, ok := probe.Type.(string)
+	if !ok {
+		m.raw = append(json.RawMessage{}, b...)
+		return nil
+	}
+	m.Type = mappingType
+	var err error
+	switch mappingType {
+	case ValueMappingTypeValue:
+		err = json.Unmarshal(probe.Options, &m.ValueOptions)
+	case ValueMappingTypeRange:
+		err = json.Unmarshal(probe.Options, &m.RangeOptions)
+	case ValueMappingTypeRegex:
+		err = json.Unmarshal(probe.Options, &m.RegexOptions)
+	case ValueMappingTypeSpecial:
+		err = json.Unmarshal(probe.Options, &m.SpecialOptions)
+	default:
+		m.raw = append(json.RawMessage{}, b...)
+	}
+	if err != nil {
+		return fmt.Errorf("%w (value mapping of type %q)", err, mappingType)
+	}
+	return nil
+}
+
+func (m ValueMapping) MarshalJSON() ([]byte, error) {
+	var options interface{}
+	switch m.Type {
+	case ValueMappingTypeValue:
+		options = m.ValueOptions
+	case ValueMappingTypeRange:
+		options = m.RangeOptions
+	case ValueMappingTypeRegex:
+		options = m.RegexOptions
+	case ValueMappingTypeSpecial:
+		options = m.SpecialOptions
+	default:
+		if m.raw != nil {
+			return m.raw, nil
+		}
+	}
+	return json.Marshal(struct {
+		Type    string      `json:"type"`
+		Options interface{} `json:"options"`
+	}{m.Type, options})
+}
@@ -0,0 +1,148 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestFieldConfig_RoundTrip(t *testing.T) {
+	var rawFieldConfig = []byte(`{
+		"defaults": {
+			"unit": "short",
+			"color": {"mode": "thresholds"},
+			"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}]},
+			"custom": {},
+			"noValue": "n/a",
+			"displayName": "${__field.labels.instance}",
+			"filterable": true,
+			"mappings": [
+				{"type": "value", "options": {"0": {"text": "Down", "color": "red", "index": 0}, "1": {"text": "Up", "color": "green", "index": 1}}},
+				{"type": "range", "options": {"from": 10, "to": null, "result": {"text": "High", "index": 2}}},
+				{"type": "regex", "options": {"pattern": "^err.*", "result": {"text": "Error", "index": 3}}},
+				{"type": "special", "options": {"match": "null+nan", "result": {"text": "No data", "index": 4}}},
+				{"id": 1, "type": 1, "op": "=", "text": "Legacy", "value": "5"}
+			]
+		},
+		"overrides": [
+			{"matcher": {"id": "byName", "options": "cpu"}, "properties": [{"id": "unit", "value": "percent"}]},
+			{"matcher": {"id": "byRegexp", "options": "/mem.*/"}, "properties": [{"id": "custom.width", "value": 100}]},
+			{"matcher": {"id": "byType", "options": "time"}, "properties": [{"id": "custom.align", "value": "left"}]},
+			{"matcher": {"id": "byFrameRefID", "options": "B"}, "properties": [{"id": "custom.axisPlacement", "value": "right"}]},
+			{"matcher": {"id": "byValue", "options": {"reducer": "max", "op": "gte", "value": 90}}, "properties": [{"id": "color", "value": {"mode": "fixed", "fixedColor": "red"}}]}
+		]
+	}`)
+	var fc sdk.FieldConfig
+	if err := json.Unmarshal(rawFieldConfig, &fc); err != nil {
+		t.Fatal(err)
+	}
+
+	if fc.Defaults.NoValue != "n/a" || fc.Defaults.DisplayName == "" || fc.Defaults.Filterable == nil || !*fc.Defaults.Filterable {
+		t.Errorf("defaults are not decoded: %+v", fc.Defaults)
+	}
+	mappings := fc.Defaults.Mappings
+	if len(mappings) != 5 {
+		t.Fatalf("should be 5 mappings but got %d", len(mappings))
+	}
+	if mappings[0].ValueOptions["1"].Text != "Up" {
+		t.Errorf("value mapping is not decoded: %+v", mappings[0])
+	}
+	if mappings[1].RangeOptions == nil || *mappings[1].RangeOptions.From != 10 || mappings[1].RangeOptions.To != nil {
+		t.Errorf("range mapping is not decoded: %+v", mappings[1])
+	}
+	if mappings[2].RegexOptions == nil || mappings[2].RegexOptions.Pattern != "^err.*" {
+		t.Errorf("regex mapping is not decoded: %+v", mappings[2])
+	}
+	if mappings[3].SpecialOptions == nil || mappings[3].SpecialOptions.Match != sdk.SpecialMatchNullAndNaN {
+		t.Errorf("special mapping is not decoded: %+v", mappings[3])
+	}
+
+	if len(fc.Overrides) != 5 {
+		t.Fatalf("should be 5 overrides but got %d", len(fc.Overrides))
+	}
+	if fc.Overrides[0].Matcher != sdk.MatchFieldsByName("cpu") {
+		t.Errorf("unexpected matcher %+v", fc.Overrides[0].Matcher)
+	}
+	byValue := sdk.MatchFieldsByValue(sdk.ByValueMatcherOptions{Reducer: "max", Op: "gte", Value: 90})
+	if fc.Overrides[4].Matcher != byValue {
+		t.Errorf("unexpected matcher %+v", fc.Overrides[4].Matcher)
+	}
+
+	out, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, got map[string]interface{}
+	if err = json.Unmarshal(rawFieldConfig, &expected); err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected["overrides"], got["overrides"]) {
+		t.Errorf("overrides changed on round trip:\n%s", out)
+	}
+	expectedDefaults := expected["defaults"].(map[string]interface{})
+	gotDefaults := got["defaults"].(map[string]interface{})
+	for _, key := range []string{"mappings", "noValue", "displayName", "filterable"} {
+		if !reflect.DeepEqual(expectedDefaults[key], gotDefaults[key]) {
+			t.Errorf("%s changed on round trip: %v != %v", key, expectedDefaults[key], gotDefaults[key])
+		}
+	}
+}
+
+func TestFieldConfig_AddOverride(t *testing.T) {
+	var fc sdk.FieldConfig
+	from, to := 0.0, 50.0
+	fc.AddOverride(sdk.MatchFieldsByName("latency"),
+		sdk.UnitProperty("ms"),
+		sdk.DecimalsProperty(1),
+		sdk.CustomProperty("width", 80),
+		sdk.MappingsProperty(sdk.NewRangeMapping(&from, &to, sdk.ValueMappingResult{Text: "fast", Color: "green"})),
+	)
+	fc.AddOverride(sdk.MatchFieldsByFrameRefID("A"), sdk.DisplayNameProperty("Requests"))
+
+	out, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back sdk.FieldConfig
+	if err = json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Overrides) != 2 {
+		t.Fatalf("should be 2 overrides but got %d", len(back.Overrides))
+	}
+	props := back.Overrides[0].Properties
+	if len(props) != 4 || props[0].ID != "unit" || props[0].Value != "ms" || props[2].ID != "custom.width" {
+		t.Errorf("unexpected properties %+v", props)
+	}
+	mappings, ok := props[3].Value.([]interface{})
+	if !ok || len(mappings) != 1 || mappings[0].(map[string]interface{})["type"] != sdk.ValueMappingTypeRange {
+		t.Errorf("unexpected mappings property %s", out)
+	}
+	if back.Overrides[1].Matcher.Options != "A" {
+		t.Errorf("unexpected matcher %+v", back.Overrides[1].Matcher)
+	}
+}
@@ -0,0 +1,366 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the variable values supported by ${var:format} syntax.
+const (
+	FormatCSV         = "csv"
+	FormatPipe        = "pipe"
+	FormatRegex       = "regex"
+	FormatGlob        = "glob"
+	FormatJSON        = "json"
+	FormatLucene      = "lucene"
+	FormatQueryParam  = "queryparam"
+	FormatRaw         = "raw"
+	FormatSingleQuote = "singlequote"
+	FormatDoubleQuote = "doublequote"
+	FormatSQLString   = "sqlstring"
+)
+
+// allValue is the value Grafana stores in the current value of the
+// variable when the "All" option is selected.
+const allValue = "$__all"
+
+// The same expression Grafana uses for matching $var, [[var]],
+// [[var:format]], ${var} and ${var:format}.
+var variableRe = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^\}]+))?\}`)
+
+// InterpolateOptions defines the context of a query rendering. Values
+// override the current values of the variables saved with the dashboard.
+// Built-in variables $__interval and $__interval_ms are expanded only when
+// Interval is set, $__range, $__range_s, $__range_ms, $__from and $__to only
+// when both From and To are set. DefaultFormat is applied to the variables
+// referenced without a format, Grafana uses "glob" unless the datasource
+// defines its own, e.g. Prometheus uses "regex".
+type InterpolateOptions struct {
+	Values        map[string][]string
+	Interval      time.Duration
+	From          time.Time
+	To            time.Time
+	DefaultFormat string
+}
+
+// Interpolator expands template variables in the queries.
+type Interpolator struct {
+	vars     map[string]*TemplateVar
+	opts     InterpolateOptions
+	builtins map[string]string
+}
+
+// NewInterpolator creates the interpolator for the list of dashboard
+// variables.
+func NewInterpolator(vars []TemplateVar, opts InterpolateOptions) *Interpolator {
+	i := &Interpolator{
+		vars:     make(map[string]*TemplateVar, len(vars)),
+		opts:     opts,
+		builtins: make(map[string]string),
+	}
+	for n := range vars {
+		i.vars[vars[n].Name] = &vars[n]
+	}
+	if opts.Interval > 0 {
+		i.builtins["__interval"] = formatInterval(opts.Interval)
+		i.builtins["__interval_ms"] = strconv.FormatInt(int64(opts.Interval/time.Millisecond), 10)
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() {
+		r := opts.To.Sub(opts.From)
+		i.builtins["__range"] = formatInterval(r.Round(time.Second))
+		i.builtins["__range_s"] = strconv.FormatInt(int64(r/time.Second), 10)
+		i.builtins["__range_ms"] = strconv.FormatInt(int64(r/time.Millisecond), 10)
+		i.builtins["__from"] = strconv.FormatInt(opts.From.UnixNano()/int64(time.Millisecond), 10)
+		i.builtins["__to"] = strconv.FormatInt(opts.To.UnixNano()/int64(time.Millisecond), 10)
+	}
+	return i
+}
+
+// Interpolator creates the interpolator for the board variables.
+func (b *Board) Interpolator(opts InterpolateOptions) *Interpolator {
+	return NewInterpolator(b.Templating.List, opts)
+}
+
+// Interpolate expands the variables in the text. It is a shortcut for
+// NewInterpolator(vars, opts).Interpolate(text).
+func Interpolate(text string, vars []TemplateVar, opts InterpolateOptions) string {
+	return NewInterpolator(vars, opts).Interpolate(text)
+}
+
+// Interpolate expands the variables in the text. References to unknown
+// variables are left as is like Grafana does.
+func (i *Interpolator) Interpolate(text string) string {
+	return variableRe.ReplaceAllStringFunc(text, func(match string) string {
+		m := variableRe.FindStringSubmatch(match)
+		name, format := m[1], ""
+		switch {
+		case m[2] != "":
+			name, format = m[2], m[3]
+		case m[4] != "":
+			name, format = m[4], m[5]
+		}
+		if v, ok := i.builtins[name]; ok {
+			return v
+		}
+		values, multi, verbatim, ok := i.values(name)
+		if !ok {
+			return match
+		}
+		if verbatim {
+			return values[0]
+		}
+		if format == "" {
+			format = i.opts.DefaultFormat
+		}
+		return formatValues(name, values, multi, format)
+	})
+}
+
+// ReferencedVariables returns names of the variables referenced in the
+// text in order of the first appearance. Built-in variables like
+// $__interval are included too, they all start with "__".
+func ReferencedVariables(text string) []string {
+	var (
+		names []string
+		seen  = make(map[string]bool)
+	)
+	for _, m := range variableRe.FindAllStringSubmatch(text, -1) {
+		name := m[1] + m[2] + m[4]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Target returns the copy of the target with the variables expanded in
+// the query fields: Expr, Query, RawSql, Target, LegendFormat and Alias.
+func (i *Interpolator) Target(t Target) Target {
+	t.Expr = i.Interpolate(t.Expr)
+	t.Query = i.Interpolate(t.Query)
+	t.RawSql = i.Interpolate(t.RawSql)
+	t.Target = i.Interpolate(t.Target)
+	t.LegendFormat = i.Interpolate(t.LegendFormat)
+	t.Alias = i.Interpolate(t.Alias)
+	return t
+}
+
+// PanelTargets returns the panel targets with the variables expanded.
+func (i *Interpolator) PanelTargets(p *Panel) []Target {
+	targets := p.GetTargets()
+	if targets == nil {
+		return nil
+	}
+	result := make([]Target, 0, len(*targets))
+	for _, t := range *targets {
+		result = append(result, i.Target(t))
+	}
+	return result
+}
+
+// values resolves the selected values of the variable. The custom "All"
+// value is reported as verbatim because Grafana does not format it.
+func (i *Interpolator) values(name string) (values []string, multi, verbatim, ok bool) {
+	v, ok := i.vars[name]
+	if !ok {
+		if values, ok = i.opts.Values[name]; ok {
+			return values, len(values) > 1, false, true
+		}
+		return nil, false, false, false
+	}
+	if selected, ok := i.opts.Values[name]; ok {
+		values, multi = selected, v.Multi || len(selected) > 1
+	} else {
+		values, multi = currentValues(v)
+	}
+	if v.Type == VariableTypeInterval && len(values) == 1 && values[0] == AutoIntervalValue(v.Name) {
+		if interval, ok := i.builtins["__interval"]; ok {
+			return []string{interval}, false, false, true
+		}
+	}
+	for _, value := range values {
+		if value == allValue {
+			if v.AllValue != "" {
+				return []string{v.AllValue}, false, true, true
+			}
+			return optionValues(v), true, false, true
+		}
+	}
+	return values, multi, false, true
+}
+
+// currentValues returns the values saved with the dashboard.
+func currentValues(v *TemplateVar) ([]string, bool) {
+	switch val := v.Current.Value.(type) {
+	case string:
+		return []string{val}, false
+	case []interface{}:
+		values := make([]string, 0, len(val))
+		for _, item := range val {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, true
+	case []string:
+		return val, true
+	case nil:
+		if v.Current.Text != nil && v.Current.Text.Valid {
+			return v.Current.Text.Value, len(v.Current.Text.Value) > 1
+		}
+	default:
+		return []string{fmt.Sprint(val)}, false
+	}
+	// constant and textbox variables may have no current value saved
+	if query, ok := v.Query.(string); ok && (v.Type == VariableTypeConstant || v.Type == VariableTypeTextbox) {
+		return []string{query}, false
+	}
+	return nil, false
+}
+
+// optionValues returns values of all the variable options except "All".
+func optionValues(v *TemplateVar) []string {
+	values := make([]string, 0, len(v.Options))
+	for _, o := range v.Options {
+		if o.Value != allValue {
+			values = append(values, o.Value)
+		}
+	}
+	return values
+}
+
+func formatValues(name string, values []string, multi bool, format string) string {
+	if len(values) == 1 && !multi && format != FormatQueryParam {
+		return formatValue(values[0], format)
+	}
+	switch format {
+	case FormatCSV, FormatRaw:
+		return strings.Join(values, ",")
+	case FormatPipe:
+		return strings.Join(values, "|")
+	case FormatRegex:
+		if len(values) == 1 {
+			return regexEscape(values[0])
+		}
+		return "(" + joinMapped(values, "|", regexEscape) + ")"
+	case FormatJSON:
+		raw, _ := json.Marshal(values)
+		return string(raw)
+	case FormatLucene:
+		if len(values) == 1 {
+			return luceneEscape(values[0])
+		}
+		return "(" + joinMapped(values, " OR ", func(s string) string { return `"` + luceneEscape(s) + `"` }) + ")"
+	case FormatQueryParam:
+		return joinMapped(values, "&", func(s string) string {
+			return "var-" + url.QueryEscape(name) + "=" + url.QueryEscape(s)
+		})
+	case FormatSingleQuote:
+		return joinMapped(values, ",", func(s string) string { return "'" + strings.Replace(s, "'", `\'`, -1) + "'" })
+	case FormatDoubleQuote:
+		return joinMapped(values, ",", func(s string) string { return `"` + strings.Replace(s, `"`, `\"`, -1) + `"` })
+	case FormatSQLString:
+		return joinMapped(values, ",", sqlString)
+	default: // glob is the Grafana fallback for unknown formats
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+func formatValue(value, format string) string {
+	switch format {
+	case FormatRegex:
+		return regexEscape(value)
+	case FormatJSON:
+		raw, _ := json.Marshal(value)
+		return string(raw)
+	case FormatLucene:
+		return luceneEscape(value)
+	case FormatSingleQuote:
+		return "'" + strings.Replace(value, "'", `\'`, -1) + "'"
+	case FormatDoubleQuote:
+		return `"` + strings.Replace(value, `"`, `\"`, -1) + `"`
+	case FormatSQLString:
+		return sqlString(value)
+	default:
+		return value
+	}
+}
+
+func joinMapped(values []string, sep string, fn func(string) string) string {
+	mapped := make([]string, len(values))
+	for i, v := range values {
+		mapped[i] = fn(v)
+	}
+	return strings.Join(mapped, sep)
+}
+
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// regexEscape escapes the same characters as Grafana kbn.regexEscape().
+func regexEscape(s string) string {
+	return escapeChars(s, `\^$*+?.()|[]{}/`)
+}
+
+// luceneEscape escapes the same characters as Grafana luceneEscape().
+func luceneEscape(s string) string {
+	return escapeChars(s, "!*+-=<>&|()[]{}^~?:\\/\" \t\n\r")
+}
+
+func escapeChars(s, chars string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formatInterval formats the duration the way Grafana shows intervals,
+// e.g. "30s", "5m", "1h", "1d" or "500ms".
+func formatInterval(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d <= 0:
+		return "0s"
+	case d%day == 0:
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	default:
+		return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+	}
+}
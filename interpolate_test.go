@@ -0,0 +1,134 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func interpolateVars() []sdk.TemplateVar {
+	job := sdk.NewCustomVariable("job", "api", "web.1", "db")
+	job.SetMulti(true, "")
+	job.Current.Value = []interface{}{"api", "web.1"}
+
+	all := sdk.NewCustomVariable("all", "a", "b")
+	all.SetMulti(true, "")
+	all.Current.Value = []interface{}{"$__all"}
+
+	custom := sdk.NewCustomVariable("customall", "a", "b")
+	custom.SetMulti(true, ".*")
+	custom.Current.Value = "$__all"
+
+	interval := sdk.NewIntervalVariable("step", "1m", "5m")
+	interval.Current.Value = sdk.AutoIntervalValue("step")
+
+	return []sdk.TemplateVar{
+		job,
+		all,
+		custom,
+		interval,
+		sdk.NewCustomVariable("env", "prod's"),
+		sdk.NewConstantVariable("cluster", "eu-west-1"),
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	opts := sdk.InterpolateOptions{
+		Interval: time.Minute,
+		From:     time.Unix(1600000000, 0),
+		To:       time.Unix(1600000000+6*3600, 0),
+	}
+	for _, tc := range []struct {
+		in, exp string
+	}{
+		{"$cluster", "eu-west-1"},
+		{"${cluster}-x", "eu-west-1-x"},
+		{"[[cluster]]", "eu-west-1"},
+		{"$unknown ${unknown:csv}", "$unknown ${unknown:csv}"},
+		{"$job", "{api,web.1}"},
+		{"${job:csv}", "api,web.1"},
+		{"[[job:pipe]]", "api|web.1"},
+		{"${job:regex}", `(api|web\.1)`},
+		{"${job:glob}", "{api,web.1}"},
+		{"${job:json}", `["api","web.1"]`},
+		{"${job:lucene}", `("api" OR "web.1")`},
+		{"${job:queryparam}", "var-job=api&var-job=web.1"},
+		{"${job:raw}", "api,web.1"},
+		{"${job:singlequote}", "'api','web.1'"},
+		{"${job:doublequote}", `"api","web.1"`},
+		{"${job:sqlstring}", "'api','web.1'"},
+		{"${job:unknown}", "{api,web.1}"},
+		{"${env:sqlstring}", "'prod''s'"},
+		{"${env:singlequote}", `'prod\'s'`},
+		{"${env:json}", `"prod's"`},
+		{"${env:queryparam}", "var-env=prod%27s"},
+		{"${cluster:lucene}", `eu\-west\-1`},
+		{"${all:csv}", "a,b"},
+		{"${customall:regex}", ".*"},
+		{"rate(x[$step])", "rate(x[1m])"},
+		{"$__interval $__interval_ms", "1m 60000"},
+		{"$__range $__range_s $__range_ms", "6h 21600 21600000"},
+		{"${__from}-${__to}", "1600000000000-1600021600000"},
+	} {
+		if got := sdk.Interpolate(tc.in, interpolateVars(), opts); got != tc.exp {
+			t.Errorf("%s: expected %q, got %q", tc.in, tc.exp, got)
+		}
+	}
+}
+
+func TestInterpolate_SelectedValues(t *testing.T) {
+	opts := sdk.InterpolateOptions{
+		Values:        map[string][]string{"job": {"db"}, "instance": {"a:1", "b:2"}},
+		DefaultFormat: sdk.FormatRegex,
+	}
+	got := sdk.Interpolate(`up{job=~"$job",instance=~"$instance"}`, interpolateVars(), opts)
+	exp := `up{job=~"db",instance=~"(a:1|b:2)"}`
+	if got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+	// built-ins are not expanded without the time range
+	if got := sdk.Interpolate("$__range", nil, opts); got != "$__range" {
+		t.Errorf("expected $__range to be kept, got %q", got)
+	}
+}
+
+func TestInterpolator_PanelTargets(t *testing.T) {
+	b := sdk.NewBoard("Sample")
+	if err := b.AddVariable(sdk.NewConstantVariable("cluster", "eu")); err != nil {
+		t.Fatal(err)
+	}
+	p := sdk.NewTimeseries("Requests")
+	p.AddTarget(&sdk.Target{Expr: `sum(rate(http_requests{cluster="$cluster"}[$__interval]))`, LegendFormat: "[[cluster]]"})
+	p.AddTarget(&sdk.Target{RawSql: "SELECT * FROM t WHERE c = ${cluster:sqlstring}"})
+
+	targets := b.Interpolator(sdk.InterpolateOptions{Interval: 30 * time.Second}).PanelTargets(p)
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targets))
+	}
+	if exp := `sum(rate(http_requests{cluster="eu"}[30s]))`; targets[0].Expr != exp {
+		t.Errorf("expected %q, got %q", exp, targets[0].Expr)
+	}
+	if targets[0].LegendFormat != "eu" {
+		t.Errorf("expected legend eu, got %q", targets[0].LegendFormat)
+	}
+	if exp := "SELECT * FROM t WHERE c = 'eu'"; targets[1].RawSql != exp {
+		t.Errorf("expected %q, got %q", exp, targets[1].RawSql)
+	}
+	if (*p.GetTargets())[0].Expr == targets[0].Expr {
+		t.Error("panel targets should not be changed")
+	}
+}
+
+func TestReferencedVariables(t *testing.T) {
+	got := sdk.ReferencedVariables(`sum(rate(x{a="$a",b=~"${b:regex}"}[$__interval])) by ([[c]]) $a`)
+	exp := []string{"a", "b", "__interval", "c"}
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("expected %v, got %v", exp, got)
+		}
+	}
+}
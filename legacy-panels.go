@@ -0,0 +1,669 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PanelMigration describes a legacy panel converted by
+// Board.MigrateLegacyPanels(). Lossy lists the settings of the legacy
+// panel that have no equivalent in the new panel type and were dropped.
+type PanelMigration struct {
+	PanelID uint
+	Title   string
+	From    string
+	To      string
+	Lossy   []string
+}
+
+// MigrateLegacyPanels converts all legacy Angular panels of the board in
+// place: graphs to timeseries, singlestats to stats or gauges and legacy
+// tables to tables. It walks the panels of rows too. The returned list
+// reports each converted panel with the settings lost on conversion.
+func (b *Board) MigrateLegacyPanels() []PanelMigration {
+	var migrations []PanelMigration
+	migrate := func(p *Panel) {
+		var (
+			from  = p.Type
+			lossy []string
+		)
+		switch {
+		case p.OfType == GraphType:
+			lossy, _ = p.MigrateGraph()
+		case p.OfType == SinglestatType:
+			lossy, _ = p.MigrateSinglestat()
+		case p.IsLegacyTable():
+			// legacy tables are reported as "table-old" even if they
+			// were saved with "table" type by old Grafana versions
+			_ = p.MigrateLegacyTable()
+			from = "table-old"
+		default:
+			return
+		}
+		migrations = append(migrations, PanelMigration{
+			PanelID: p.ID,
+			Title:   p.Title,
+			From:    from,
+			To:      p.Type,
+			Lossy:   lossy,
+		})
+	}
+	for _, p := range b.Panels {
+		migrate(p)
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				migrate(&p.RowPanel.Panels[i])
+			}
+		}
+	}
+	for _, r := range b.Rows {
+		for i := range r.Panels {
+			migrate(&r.Panels[i])
+		}
+	}
+	return migrations
+}
+
+// MigrateGraph converts the graph panel to the timeseries panel in place
+// in the same way as Grafana does on the panel type change. The first Y
+// axis, thresholds and the panel draw options become the field config
+// defaults, the second Y axis, series overrides and alias colors become
+// the field config overrides. It returns the list of graph settings that
+// could not be converted.
+func (p *Panel) MigrateGraph() ([]string, error) {
+	if p.OfType != GraphType || p.GraphPanel == nil {
+		return nil, errors.New("panel is not a graph")
+	}
+	var (
+		g     = p.GraphPanel
+		lossy []string
+		fc    FieldConfig
+	)
+	if g.FieldConfig != nil {
+		fc = *g.FieldConfig
+	}
+	d := &fc.Defaults
+	if d.Color.Mode == "" {
+		d.Color.Mode = "palette-classic"
+	}
+	c := &d.Custom
+	c.AxisPlacement = "auto"
+	c.GradientMode = "none"
+	c.DrawStyle = "line"
+	switch {
+	case g.Bars:
+		c.DrawStyle = "bars"
+		if g.Lines {
+			lossy = append(lossy, "lines drawn together with bars")
+		}
+	case !g.Lines && g.Points:
+		c.DrawStyle = "points"
+	}
+	c.LineWidth = int(g.Linewidth)
+	c.FillOpacity = g.Fill * 10
+	c.ShowPoints = "never"
+	if g.Points {
+		c.ShowPoints = "always"
+		c.PointSize = int(g.Pointradius * 2)
+	}
+	c.LineInterpolation = "linear"
+	if g.SteppedLine {
+		c.LineInterpolation = "stepAfter"
+	}
+	if g.Dashes != nil && *g.Dashes {
+		c.LineStyle.Fill = "dash"
+		c.LineStyle.Dash = graphDashes(g)
+	} else {
+		c.LineStyle.Fill = "solid"
+	}
+	switch g.NullPointMode {
+	case "connected":
+		c.SpanNulls = true
+	case "null as zero":
+		lossy = append(lossy, `null point mode "null as zero"`)
+	}
+	c.Stacking.Mode = "none"
+	c.Stacking.Group = "A"
+	if g.Stack {
+		c.Stacking.Mode = "normal"
+		if g.Percentage {
+			c.Stacking.Mode = "percent"
+		}
+	} else if g.Percentage {
+		lossy = append(lossy, "percentage without stacking")
+	}
+	c.ScaleDistribution.Type = "linear"
+	c.ThresholdsStyle.Mode = "off"
+	if len(g.Yaxes) > 0 {
+		y := g.Yaxes[0]
+		d.Unit = y.Format
+		if y.Decimals != 0 {
+			decimals := y.Decimals
+			d.Decimals = &decimals
+		}
+		d.Min = axisLimit(y.Min)
+		d.Max = axisLimit(y.Max)
+		c.AxisLabel = y.Label
+		if y.LogBase > 1 {
+			c.ScaleDistribution.Type = "log"
+			c.ScaleDistribution.Log = y.LogBase
+		}
+		if !y.Show {
+			c.AxisPlacement = "hidden"
+		}
+	}
+	if g.Decimals != nil {
+		decimals := *g.Decimals
+		d.Decimals = &decimals
+	}
+	if len(g.Thresholds) > 0 {
+		d.Thresholds, c.ThresholdsStyle.Mode = graphThresholds(g.Thresholds)
+	} else if d.Thresholds.Mode == "" {
+		d.Thresholds = Thresholds{Mode: "absolute", Steps: []ThresholdStep{{Color: "green"}}}
+	}
+	if colors, ok := g.AliasColors.(map[string]interface{}); ok {
+		names := make([]string, 0, len(colors))
+		for name := range colors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if color, ok := colors[name].(string); ok {
+				fc.AddOverride(MatchFieldsByName(name), ColorProperty(FieldConfigColor{Mode: "fixed", FixedColor: color}))
+			}
+		}
+	}
+	for _, o := range g.SeriesOverrides {
+		props, dropped := seriesOverrideProperties(o, g)
+		lossy = append(lossy, dropped...)
+		if len(props) == 0 {
+			continue
+		}
+		matcher := MatchFieldsByName(o.Alias)
+		if isRegexPattern(o.Alias) {
+			matcher = MatchFieldsByRegexp(o.Alias)
+		}
+		fc.AddOverride(matcher, props...)
+	}
+
+	var opts TimeseriesOptions
+	opts.Legend.Calcs = []string{}
+	opts.Legend.Placement = "bottom"
+	if g.Legend.RightSide {
+		opts.Legend.Placement = "right"
+	}
+	switch {
+	case !g.Legend.Show:
+		opts.Legend.DisplayMode = "hidden"
+	case g.Legend.AlignAsTable:
+		opts.Legend.DisplayMode = "table"
+	default:
+		opts.Legend.DisplayMode = "list"
+	}
+	if g.Legend.Values {
+		for _, calc := range []struct {
+			on bool
+			id string
+		}{
+			{g.Legend.Min, "min"},
+			{g.Legend.Max, "max"},
+			{g.Legend.Avg, "mean"},
+			{g.Legend.Current, "lastNotNull"},
+			{g.Legend.Total, "sum"},
+		} {
+			if calc.on {
+				opts.Legend.Calcs = append(opts.Legend.Calcs, calc.id)
+			}
+		}
+	}
+	if g.Legend.HideEmpty || g.Legend.HideZero {
+		lossy = append(lossy, "hiding of empty or zero series in the legend")
+	}
+	opts.Tooltip.Mode = "single"
+	if g.Tooltip.Shared {
+		opts.Tooltip.Mode = "multi"
+	}
+	if g.Tooltip.ValueType == "cumulative" {
+		lossy = append(lossy, "cumulative tooltip values")
+	}
+	if g.Xaxis.Mode != "" && g.Xaxis.Mode != "time" {
+		lossy = append(lossy, fmt.Sprintf("x-axis mode %q", g.Xaxis.Mode))
+	}
+
+	p.TimeseriesPanel = &TimeseriesPanel{
+		Targets:     g.Targets,
+		Options:     opts,
+		FieldConfig: fc,
+		TimeFrom:    g.TimeFrom,
+		TimeShift:   g.TimeShift,
+	}
+	p.GraphPanel = nil
+	p.OfType = TimeseriesType
+	p.retype("timeseries")
+	return lossy, nil
+}
+
+// graphDashes returns the dash and the space lengths of the dashed lines.
+func graphDashes(g *GraphPanel) []int {
+	dash, space := 10, 10
+	if g.DashLength != nil {
+		dash = int(*g.DashLength)
+	}
+	if g.SpaceLength != nil {
+		space = int(*g.SpaceLength)
+	}
+	return []int{dash, space}
+}
+
+func axisLimit(v *FloatString) *float64 {
+	if v == nil || !v.Valid {
+		return nil
+	}
+	value := v.Value
+	return &value
+}
+
+// graphThresholds converts the graph thresholds to threshold steps and
+// the thresholds style in the same way as Grafana does.
+func graphThresholds(thresholds []Threshold) (Thresholds, string) {
+	sorted := make([]Threshold, len(thresholds))
+	copy(sorted, thresholds)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Value < sorted[j].Value })
+	var (
+		steps      []ThresholdStep
+		area, line bool
+	)
+	step := func(value *float64, color string) {
+		steps = append(steps, ThresholdStep{Color: color, Value: value})
+	}
+	for i, t := range sorted {
+		value := float64(t.Value)
+		area = area || t.Fill
+		line = line || t.Line
+		switch t.Op {
+		case "gt":
+			step(&value, graphThresholdColor(t))
+		case "lt":
+			if len(steps) == 0 {
+				step(nil, graphThresholdColor(t))
+			}
+			if i+1 < len(sorted) && sorted[i+1].Op == "lt" {
+				step(&value, graphThresholdColor(sorted[i+1]))
+			} else {
+				step(&value, "transparent")
+			}
+		}
+	}
+	if len(steps) > 0 && steps[0].Value != nil {
+		steps = append([]ThresholdStep{{Color: "transparent"}}, steps...)
+	}
+	mode := "line"
+	switch {
+	case area && line:
+		mode = "line+area"
+	case area:
+		mode = "area"
+	}
+	return Thresholds{Mode: "absolute", Steps: steps}, mode
+}
+
+func graphThresholdColor(t Threshold) string {
+	switch t.ColorMode {
+	case "critical":
+		return "red"
+	case "warning":
+		return "orange"
+	case "ok":
+		return "green"
+	case "custom":
+		if t.FillColor != "" {
+			return t.FillColor
+		}
+		if t.LineColor != "" {
+			return t.LineColor
+		}
+	}
+	return "red"
+}
+
+// seriesOverrideProperties converts the graph series override to the
+// field override properties. It returns the list of dropped settings.
+func seriesOverrideProperties(o SeriesOverride, g *GraphPanel) ([]FieldOverrideProperty, []string) {
+	var (
+		props   []FieldOverrideProperty
+		dropped []string
+	)
+	add := func(p FieldOverrideProperty) {
+		props = append(props, p)
+	}
+	drop := func(setting string) {
+		dropped = append(dropped, fmt.Sprintf("%s of series override %q", setting, o.Alias))
+	}
+	switch {
+	case o.Bars != nil && *o.Bars:
+		add(CustomProperty("drawStyle", "bars"))
+	case o.Lines != nil && *o.Lines:
+		add(CustomProperty("drawStyle", "line"))
+	case o.Lines != nil && !*o.Lines:
+		add(CustomProperty("drawStyle", "points"))
+		add(CustomProperty("showPoints", "always"))
+	}
+	if o.Color != nil {
+		add(ColorProperty(FieldConfigColor{Mode: "fixed", FixedColor: *o.Color}))
+	}
+	if o.Fill != nil {
+		add(CustomProperty("fillOpacity", *o.Fill*10))
+	}
+	if o.LineWidth != nil {
+		add(CustomProperty("lineWidth", *o.LineWidth))
+	}
+	if o.Dashes != nil {
+		if *o.Dashes {
+			add(CustomProperty("lineStyle", map[string]interface{}{"fill": "dash", "dash": graphDashes(g)}))
+		} else {
+			add(CustomProperty("lineStyle", map[string]interface{}{"fill": "solid"}))
+		}
+	}
+	if o.Legend != nil && !*o.Legend {
+		add(CustomProperty("hideFrom", map[string]bool{"legend": true, "tooltip": false, "viz": false}))
+	}
+	if o.Stack != nil {
+		switch {
+		case o.Stack.Value != "":
+			add(CustomProperty("stacking", map[string]string{"mode": "normal", "group": o.Stack.Value}))
+		case o.Stack.Flag:
+			add(CustomProperty("stacking", map[string]string{"mode": "normal", "group": "A"}))
+		default:
+			add(CustomProperty("stacking", map[string]string{"mode": "none", "group": "A"}))
+		}
+	}
+	if o.Transform != nil {
+		if *o.Transform == "negative-Y" {
+			add(CustomProperty("transform", "negative-Y"))
+		} else {
+			drop(fmt.Sprintf("transform %q", *o.Transform))
+		}
+	}
+	if o.YAxis != nil && *o.YAxis == 2 {
+		add(CustomProperty("axisPlacement", "right"))
+		if len(g.Yaxes) > 1 {
+			y := g.Yaxes[1]
+			if y.Format != "" {
+				add(UnitProperty(y.Format))
+			}
+			if y.Decimals != 0 {
+				add(DecimalsProperty(y.Decimals))
+			}
+			if min := axisLimit(y.Min); min != nil {
+				add(NewFieldOverrideProperty("min", *min))
+			}
+			if max := axisLimit(y.Max); max != nil {
+				add(NewFieldOverrideProperty("max", *max))
+			}
+			if y.Label != "" {
+				add(CustomProperty("axisLabel", y.Label))
+			}
+		}
+	}
+	if o.ZIndex != nil {
+		drop("zindex")
+	}
+	if o.FillBelowTo != nil {
+		drop("fillBelowTo")
+	}
+	if o.NullPointMode != nil {
+		drop("null point mode")
+	}
+	return props, dropped
+}
+
+// Reducers of the stat panel for the value names of the singlestat panel.
+var singlestatReducers = map[string]string{
+	"avg":     "mean",
+	"current": "lastNotNull",
+	"min":     "min",
+	"max":     "max",
+	"total":   "sum",
+	"first":   "firstNotNull",
+	"delta":   "delta",
+	"diff":    "diff",
+	"range":   "range",
+}
+
+// MigrateSinglestat converts the singlestat panel in place to the stat
+// panel or to the gauge panel if the singlestat shows a gauge, in the
+// same way as Grafana does on the panel type change. It returns the list
+// of singlestat settings that could not be converted.
+func (p *Panel) MigrateSinglestat() ([]string, error) {
+	if p.OfType != SinglestatType || p.SinglestatPanel == nil {
+		return nil, errors.New("panel is not a singlestat")
+	}
+	var (
+		s     = p.SinglestatPanel
+		lossy []string
+		opts  Options
+		fc    FieldConfig
+	)
+	reducer, ok := singlestatReducers[s.ValueName]
+	if !ok {
+		reducer = "mean"
+		if s.ValueName != "" {
+			lossy = append(lossy, fmt.Sprintf("value %q", s.ValueName))
+		}
+	}
+	opts.ReduceOptions.Calcs = []string{reducer}
+	opts.Orientation = "horizontal"
+	opts.JustifyMode = "auto"
+	opts.TextMode = "auto"
+
+	d := &fc.Defaults
+	d.Unit = s.Format
+	if s.Decimals != 0 {
+		decimals := s.Decimals
+		d.Decimals = &decimals
+	}
+	d.Color.Mode = "thresholds"
+	if s.Thresholds != "" && len(s.Colors) > 0 {
+		d.Thresholds = stylesToThresholds(s.Colors, strings.Split(s.Thresholds, ","))
+	} else {
+		d.Thresholds = Thresholds{Mode: "absolute", Steps: []ThresholdStep{{Color: "green"}}}
+	}
+	d.Mappings = singlestatMappings(s)
+	if s.Prefix != nil && *s.Prefix != "" {
+		lossy = append(lossy, fmt.Sprintf("prefix %q", *s.Prefix))
+	}
+	if s.Postfix != nil && *s.Postfix != "" {
+		lossy = append(lossy, fmt.Sprintf("postfix %q", *s.Postfix))
+	}
+
+	if s.Gauge.Show {
+		min, max := float64(s.Gauge.MinValue), float64(s.Gauge.MaxValue)
+		d.Min, d.Max = &min, &max
+		opts.Orientation = "auto"
+		opts.ShowThresholdLabels = s.Gauge.ThresholdLabels
+		opts.ShowThresholdMarkers = s.Gauge.ThresholdMarkers
+		if s.SparkLine.Show {
+			lossy = append(lossy, "sparkline of the gauge")
+		}
+		p.GaugePanel = &GaugePanel{Options: opts, Targets: s.Targets, FieldConfig: fc}
+		p.SinglestatPanel = nil
+		p.OfType = GaugeType
+		p.retype("gauge")
+		return lossy, nil
+	}
+
+	opts.GraphMode = "none"
+	if s.SparkLine.Show {
+		opts.GraphMode = "area"
+		if s.SparkLine.Full {
+			lossy = append(lossy, "full height sparkline")
+		}
+	}
+	switch {
+	case s.ColorBackground:
+		opts.ColorMode = "background"
+	case s.ColorValue:
+		opts.ColorMode = "value"
+	default:
+		opts.ColorMode = "none"
+	}
+	p.StatPanel = &StatPanel{
+		Targets:       s.Targets,
+		MaxDataPoints: s.MaxDataPoints,
+		Options:       opts,
+		FieldConfig:   &fc,
+	}
+	p.SinglestatPanel = nil
+	p.OfType = StatType
+	p.retype("stat")
+	return lossy, nil
+}
+
+// fieldConfigStatPanel is the stat panel of Grafana 7+ as it is
+// marshalled, e.g. the one made by MigrateSinglestat(). Its settings are
+// in Options and FieldConfig so the keys left from singlestat are omitted
+// while they are not set.
+type fieldConfigStatPanel struct {
+	StatPanel
+	Colors          []string   `json:"colors,omitempty"`
+	ColorValue      bool       `json:"colorValue,omitempty"`
+	ColorBackground bool       `json:"colorBackground,omitempty"`
+	Decimals        int        `json:"decimals,omitempty"`
+	Format          string     `json:"format,omitempty"`
+	NullPointMode   string     `json:"nullPointMode,omitempty"`
+	Thresholds      string     `json:"thresholds,omitempty"`
+	ValueFontSize   string     `json:"valueFontSize,omitempty"`
+	ValueMaps       []ValueMap `json:"valueMaps,omitempty"`
+	ValueName       string     `json:"valueName,omitempty"`
+	Gauge           *Gauge     `json:"gauge,omitempty"`
+	SparkLine       *SparkLine `json:"sparkline,omitempty"`
+}
+
+func newFieldConfigStatPanel(s StatPanel) fieldConfigStatPanel {
+	p := fieldConfigStatPanel{
+		StatPanel:       s,
+		Colors:          s.Colors,
+		ColorValue:      s.ColorValue,
+		ColorBackground: s.ColorBackground,
+		Decimals:        s.Decimals,
+		Format:          s.Format,
+		NullPointMode:   s.NullPointMode,
+		Thresholds:      s.Thresholds,
+		ValueFontSize:   s.ValueFontSize,
+		ValueMaps:       s.ValueMaps,
+		ValueName:       s.ValueName,
+	}
+	if s.Gauge != (Gauge{}) {
+		p.Gauge = &s.Gauge
+	}
+	if s.SparkLine != (SparkLine{}) {
+		p.SparkLine = &s.SparkLine
+	}
+	return p
+}
+
+// singlestatMappings converts value maps and range maps of the singlestat
+// panel. The mapping type selects one kind of maps, both kinds are
+// converted if the type is not set.
+func singlestatMappings(s *SinglestatPanel) []ValueMapping {
+	var (
+		mappings    []ValueMapping
+		mappingType uint
+	)
+	if s.MappingType != nil {
+		mappingType = *s.MappingType
+	}
+	if mappingType != 2 {
+		mappings = append(mappings, valueMapsToMappings(s.ValueMaps)...)
+	}
+	if mappingType == 1 {
+		return mappings
+	}
+	for _, rm := range s.RangeMaps {
+		if rm == nil || rm.Text == nil {
+			continue
+		}
+		mappings = append(mappings, NewRangeMapping(parseRangeValue(rm.From), parseRangeValue(rm.To),
+			ValueMappingResult{Text: *rm.Text, Index: len(mappings)}))
+	}
+	return mappings
+}
+
+func parseRangeValue(v *string) *float64 {
+	if v == nil {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(*v), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// Keys of the panel JSON that do not depend on the panel type and are not
+// typed in CommonPanel.
+var genericPanelKeys = map[string]bool{
+	"interval":         true,
+	"maxDataPoints":    true,
+	"timeFrom":         true,
+	"timeShift":        true,
+	"hideTimeOverride": true,
+	"cacheTimeout":     true,
+	"queryCachingTTL":  true,
+	"libraryPanel":     true,
+	"repeatDirection":  true,
+	"maxPerRow":        true,
+}
+
+// retype changes the panel type after a conversion. The source of the
+// panel is reduced to the keys common to all panel types, so the keys of
+// the old type are not resurrected while the unknown generic keys (e.g.
+// "interval") are still preserved on marshalling. Keys of the old type
+// that still apply to the new one could be kept too.
+func (p *Panel) retype(panelType string, keep ...string) {
+	p.Type = panelType
+	p.Renderer = nil
+	if len(p.source) > 0 {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(p.source, &keys); err != nil {
+			p.source = nil
+			return
+		}
+		common := jsonFields(reflect.TypeOf(CommonPanel{}))
+		kept := make(map[string]bool, len(keep))
+		for _, key := range keep {
+			kept[key] = true
+		}
+		for key := range keys {
+			if _, ok := common[key]; !ok && !genericPanelKeys[key] && !kept[key] {
+				delete(keys, key)
+			}
+		}
+		p.source, _ = json.Marshal(keys)
+	}
+	p.sourceType = panelType
+}
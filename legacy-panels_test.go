@@ -0,0 +1,256 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+const legacyGraphJSON = `{
+  "id": 7,
+  "title": "Requests",
+  "type": "graph",
+  "renderer": "flot",
+  "interval": "1m",
+  "pluginVersion": "7.5.0",
+  "datasource": "${datasource}",
+  "aliasColors": {"errors": "#E24D42"},
+  "bars": false,
+  "dashLength": 10,
+  "dashes": false,
+  "fill": 2,
+  "fillGradient": 3,
+  "legend": {"alignAsTable": true, "avg": true, "current": true, "max": false, "min": false, "rightSide": true, "show": true, "total": false, "values": true, "hideZero": true},
+  "lines": true,
+  "linewidth": 2,
+  "nullPointMode": "connected",
+  "percentage": false,
+  "pointradius": 2,
+  "points": false,
+  "seriesOverrides": [
+    {"alias": "errors", "yaxis": 2, "fill": 0, "zindex": 3},
+    {"alias": "/^p9[59]$/", "dashes": true, "legend": false, "stack": "B"}
+  ],
+  "stack": true,
+  "steppedLine": true,
+  "targets": [{"refId": "A", "expr": "rate(http_requests_total[5m])"}],
+  "thresholds": [
+    {"value": 90, "colorMode": "critical", "op": "gt", "fill": true, "line": true},
+    {"value": 50, "colorMode": "warning", "op": "gt", "fill": true, "line": false}
+  ],
+  "timeFrom": "1h",
+  "tooltip": {"shared": true, "sort": 0, "value_type": "individual"},
+  "xaxis": {"mode": "time", "show": true},
+  "yaxes": [
+    {"format": "reqps", "label": "rate", "logBase": 1, "min": "0", "show": true},
+    {"format": "percent", "logBase": 1, "max": 100, "show": true}
+  ]
+}`
+
+func TestPanel_MigrateGraph(t *testing.T) {
+	var p sdk.Panel
+	if err := json.Unmarshal([]byte(legacyGraphJSON), &p); err != nil {
+		t.Fatal(err)
+	}
+	lossy, err := p.MigrateGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.OfType != sdk.TimeseriesType || p.Type != "timeseries" || p.GraphPanel != nil {
+		t.Fatalf("expected timeseries panel, got %q", p.Type)
+	}
+	ts := p.TimeseriesPanel
+	d := ts.FieldConfig.Defaults
+	if d.Unit != "reqps" || d.Min == nil || *d.Min != 0 || d.Max != nil {
+		t.Errorf("unexpected unit and limits: %q %v %v", d.Unit, d.Min, d.Max)
+	}
+	c := d.Custom
+	if c.FillOpacity != 20 || c.LineWidth != 2 || c.LineInterpolation != "stepAfter" || !c.SpanNulls || c.AxisLabel != "rate" {
+		t.Errorf("unexpected draw options %+v", c)
+	}
+	if c.Stacking.Mode != "normal" || c.ShowPoints != "never" || c.DrawStyle != "line" {
+		t.Errorf("unexpected stacking or style %+v", c)
+	}
+	if c.ThresholdsStyle.Mode != "line+area" {
+		t.Errorf("expected thresholds style line+area, got %q", c.ThresholdsStyle.Mode)
+	}
+	steps := d.Thresholds.Steps
+	if len(steps) != 3 || steps[0].Value != nil || steps[0].Color != "transparent" ||
+		*steps[1].Value != 50 || steps[1].Color != "orange" || *steps[2].Value != 90 || steps[2].Color != "red" {
+		t.Errorf("unexpected threshold steps %+v", steps)
+	}
+	legend := ts.Options.Legend
+	if legend.DisplayMode != "table" || legend.Placement != "right" || len(legend.Calcs) != 2 ||
+		legend.Calcs[0] != "mean" || legend.Calcs[1] != "lastNotNull" {
+		t.Errorf("unexpected legend %+v", legend)
+	}
+	if ts.Options.Tooltip.Mode != "multi" {
+		t.Errorf("expected multi tooltip, got %q", ts.Options.Tooltip.Mode)
+	}
+	if len(ts.Targets) != 1 || ts.TimeFrom == nil || *ts.TimeFrom != "1h" {
+		t.Errorf("targets or time override are lost")
+	}
+
+	overrides := ts.FieldConfig.Overrides
+	if len(overrides) != 3 {
+		t.Fatalf("expected 3 overrides, got %d", len(overrides))
+	}
+	if overrides[0].Matcher.ID != sdk.FieldMatcherByName || overrides[0].Properties[0].ID != "color" {
+		t.Errorf("expected alias color override first, got %+v", overrides[0])
+	}
+	props := make(map[string]interface{})
+	for _, p := range overrides[1].Properties {
+		props[p.ID] = p.Value
+	}
+	if props["custom.axisPlacement"] != "right" || props["unit"] != "percent" || props["max"] != 100.0 || props["custom.fillOpacity"] != 0 {
+		t.Errorf("unexpected right axis override %v", props)
+	}
+	if overrides[2].Matcher.ID != sdk.FieldMatcherByRegexp || len(overrides[2].Properties) != 3 {
+		t.Errorf("unexpected regexp override %+v", overrides[2])
+	}
+	if len(lossy) != 2 {
+		t.Errorf("expected zindex and hidden zero series reported, got %v", lossy)
+	}
+
+	raw, err := json.Marshal(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err = json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["interval"] != "1m" || out["datasource"] != "${datasource}" {
+		t.Errorf("generic panel keys are lost: %s", raw)
+	}
+	for _, key := range []string{"fill", "fillGradient", "yaxes", "renderer", "pluginVersion", "seriesOverrides"} {
+		if _, ok := out[key]; ok {
+			t.Errorf("graph key %q should be dropped: %s", key, raw)
+		}
+	}
+}
+
+func TestPanel_MigrateSinglestat(t *testing.T) {
+	p := sdk.NewSinglestat("Uptime")
+	s := p.SinglestatPanel
+	s.Format = "s"
+	s.ValueName = "current"
+	s.Thresholds = "60, 3600"
+	s.Colors = []string{"red", "orange", "green"}
+	s.ColorBackground = true
+	s.SparkLine.Show = true
+	mappingType := uint(1)
+	s.MappingType = &mappingType
+	s.ValueMaps = []sdk.ValueMap{{Op: "=", TextType: "down", Value: "0"}, {Op: "=", TextType: "N/A", Value: "null"}}
+	postfix := " up"
+	s.Postfix = &postfix
+	p.AddTarget(&sdk.Target{RefID: "A", Expr: "time() - process_start_time_seconds"})
+
+	lossy, err := p.MigrateSinglestat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.OfType != sdk.StatType || p.Type != "stat" || p.SinglestatPanel != nil || p.Renderer != nil {
+		t.Fatalf("expected stat panel, got %q", p.Type)
+	}
+	stat := p.StatPanel
+	if stat.Options.ColorMode != "background" || stat.Options.GraphMode != "area" {
+		t.Errorf("unexpected options %+v", stat.Options)
+	}
+	if calcs := stat.Options.ReduceOptions.Calcs; len(calcs) != 1 || calcs[0] != "lastNotNull" {
+		t.Errorf("unexpected calcs %v", calcs)
+	}
+	d := stat.FieldConfig.Defaults
+	if d.Unit != "s" || len(d.Thresholds.Steps) != 3 || *d.Thresholds.Steps[2].Value != 3600 {
+		t.Errorf("unexpected defaults %+v", d)
+	}
+	if len(d.Mappings) != 2 || d.Mappings[0].Type != sdk.ValueMappingTypeValue || d.Mappings[1].Type != sdk.ValueMappingTypeSpecial {
+		t.Errorf("unexpected mappings %+v", d.Mappings)
+	}
+	if len(stat.Targets) != 1 {
+		t.Errorf("targets are lost")
+	}
+	if len(lossy) != 1 {
+		t.Errorf("expected postfix reported, got %v", lossy)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"colors", "colorValue", "colorBackground", "decimals", "format", "nullPointMode", "thresholds", "valueFontSize", "valueMaps", "valueName", "gauge", "sparkline"} {
+		if _, ok := out[key]; ok {
+			t.Errorf("singlestat key %q should be omitted: %s", key, b)
+		}
+	}
+	if _, ok := out["fieldConfig"]; !ok {
+		t.Errorf("field config should be written: %s", b)
+	}
+}
+
+func TestPanel_MigrateSinglestatGauge(t *testing.T) {
+	p := sdk.NewSinglestat("Disk")
+	s := p.SinglestatPanel
+	s.Gauge = sdk.Gauge{Show: true, MinValue: 0, MaxValue: 100, ThresholdMarkers: true}
+	s.RangeMaps = []*sdk.RangeMap{{From: strPtr("0"), To: strPtr("10"), Text: strPtr("low")}}
+
+	if _, err := p.MigrateSinglestat(); err != nil {
+		t.Fatal(err)
+	}
+	if p.OfType != sdk.GaugeType || p.Type != "gauge" {
+		t.Fatalf("expected gauge panel, got %q", p.Type)
+	}
+	g := p.GaugePanel
+	if d := g.FieldConfig.Defaults; d.Min == nil || *d.Min != 0 || d.Max == nil || *d.Max != 100 {
+		t.Errorf("unexpected gauge limits %v %v", d.Min, d.Max)
+	}
+	if !g.Options.ShowThresholdMarkers || g.Options.ShowThresholdLabels {
+		t.Errorf("unexpected gauge options %+v", g.Options)
+	}
+	if m := g.FieldConfig.Defaults.Mappings; len(m) != 1 || m[0].Type != sdk.ValueMappingTypeRange {
+		t.Errorf("unexpected mappings %+v", m)
+	}
+	if _, err := p.MigrateSinglestat(); err == nil {
+		t.Error("expected error on migration of not a singlestat")
+	}
+}
+
+func TestBoard_MigrateLegacyPanels(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/default-panels-all-types-2-rows-dashboard-2.6.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var board sdk.Board
+	if err = json.Unmarshal(raw, &board); err != nil {
+		t.Fatal(err)
+	}
+	migrations := board.MigrateLegacyPanels()
+	counts := make(map[string]int)
+	for _, m := range migrations {
+		counts[m.From+">"+m.To]++
+	}
+	if counts["graph>timeseries"] != 4 || counts["singlestat>stat"] != 1 || counts["table-old>table"] != 1 {
+		t.Errorf("unexpected migrations %v", counts)
+	}
+
+	if raw, err = json.Marshal(board); err != nil {
+		t.Fatal(err)
+	}
+	var migrated sdk.Board
+	if err = json.Unmarshal(raw, &migrated); err != nil {
+		t.Fatal(err)
+	}
+	if again := migrated.MigrateLegacyPanels(); len(again) != 0 {
+		t.Errorf("expected no legacy panels after migration, got %v", again)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,188 @@
+package lint
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+)
+
+// Severity of the problems reported by a rule.
+type Severity int
+
+// Severities in the ascending order. Rules with SeverityOff are not run.
+const (
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityOff:     "off",
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// ParseSeverity converts the severity name ("off", "info", "warning" or
+// "error") to Severity.
+func ParseSeverity(name string) (Severity, error) {
+	for s, n := range severityNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return SeverityOff, fmt.Errorf("unknown severity %q", name)
+}
+
+// Problem found in a dashboard. PanelID and Panel are empty for the
+// problems of the dashboard as a whole.
+type Problem struct {
+	Rule     string
+	Severity Severity
+	PanelID  uint
+	Panel    string
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Panel == "" && p.PanelID == 0 {
+		return fmt.Sprintf("%s: %s [%s]", p.Severity, p.Message, p.Rule)
+	}
+	return fmt.Sprintf("%s: panel %q (id %d): %s [%s]", p.Severity, p.Panel, p.PanelID, p.Message, p.Rule)
+}
+
+// Rule checks one aspect of a dashboard. Check does not need to fill Rule
+// and Severity of the problems, the linter sets them.
+type Rule struct {
+	Name        string
+	Description string
+	// Severity is the default severity of the rule problems.
+	Severity Severity
+	Check    func(b *sdk.Board) []Problem
+}
+
+// Linter runs the set of rules against dashboards.
+type Linter struct {
+	rules    []Rule
+	severity map[string]Severity
+}
+
+// New creates a linter with the rules. Use DefaultRules() to get the
+// rules shipped with the package.
+func New(rules ...Rule) *Linter {
+	l := &Linter{severity: make(map[string]Severity)}
+	for _, r := range rules {
+		l.AddRule(r)
+	}
+	return l
+}
+
+// Default creates a linter with DefaultRules().
+func Default() *Linter {
+	return New(DefaultRules()...)
+}
+
+// AddRule adds the rule or replaces the rule with the same name.
+func (l *Linter) AddRule(r Rule) {
+	for i := range l.rules {
+		if l.rules[i].Name == r.Name {
+			l.rules[i] = r
+			return
+		}
+	}
+	l.rules = append(l.rules, r)
+}
+
+// SetSeverity overrides the default severity of the rule. SeverityOff
+// disables the rule.
+func (l *Linter) SetSeverity(rule string, s Severity) error {
+	for _, r := range l.rules {
+		if r.Name == rule {
+			l.severity[rule] = s
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown rule %q", rule)
+}
+
+// Rules returns the linter rules with their effective severities.
+func (l *Linter) Rules() []Rule {
+	rules := make([]Rule, len(l.rules))
+	for i, r := range l.rules {
+		r.Severity = l.ruleSeverity(r)
+		rules[i] = r
+	}
+	return rules
+}
+
+func (l *Linter) ruleSeverity(r Rule) Severity {
+	if s, ok := l.severity[r.Name]; ok {
+		return s
+	}
+	return r.Severity
+}
+
+// Lint runs all enabled rules against the dashboard. Problems are sorted
+// by severity from errors to infos, then by the panel ID.
+func (l *Linter) Lint(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, r := range l.rules {
+		severity := l.ruleSeverity(r)
+		if severity == SeverityOff || r.Check == nil {
+			continue
+		}
+		for _, p := range r.Check(b) {
+			p.Rule = r.Name
+			p.Severity = severity
+			problems = append(problems, p)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Severity != problems[j].Severity {
+			return problems[i].Severity > problems[j].Severity
+		}
+		return problems[i].PanelID < problems[j].PanelID
+	})
+	return problems
+}
+
+// MaxSeverity returns the highest severity of the problems or SeverityOff
+// if there are no problems.
+func MaxSeverity(problems []Problem) Severity {
+	max := SeverityOff
+	for _, p := range problems {
+		if p.Severity > max {
+			max = p.Severity
+		}
+	}
+	return max
+}
@@ -0,0 +1,162 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+	"github.com/grafana-tools/sdk/lint"
+)
+
+func gridPos(x, y, w, h int) sdk.GridPos {
+	return sdk.GridPos{X: &x, Y: &y, W: &w, H: &h}
+}
+
+func sampleBoard() *sdk.Board {
+	b := sdk.NewBoard("Sample")
+	_ = b.AddVariable(sdk.NewDatasourceVariable("datasource", "prometheus"))
+	_ = b.AddVariable(sdk.NewCustomVariable("job", "api", "web"))
+
+	ts := sdk.NewTimeseries("Requests")
+	ts.Datasource = sdk.NewDatasourceRefByName("${datasource}")
+	ts.TimeseriesPanel.FieldConfig.Defaults.Unit = "reqps"
+	ts.GridPos = gridPos(0, 0, 12, 8)
+	ts.AddTarget(&sdk.Target{Expr: `rate(http_requests{job="$job"}[$__rate_interval])`})
+	b.AddPanel(ts)
+
+	stat := sdk.NewTimeseries("Errors")
+	stat.Datasource = sdk.NewDatasourceRef("prometheus", "${datasource}")
+	stat.TimeseriesPanel.FieldConfig.Defaults.Unit = "percent"
+	stat.GridPos = gridPos(12, 0, 12, 8)
+	stat.AddTarget(&sdk.Target{Expr: `errors{job="$job"}`})
+	b.AddPanel(stat)
+	return b
+}
+
+func rulesOf(problems []lint.Problem) map[string]int {
+	rules := make(map[string]int)
+	for _, p := range problems {
+		rules[p.Rule]++
+	}
+	return rules
+}
+
+func TestLint_CleanBoard(t *testing.T) {
+	if problems := lint.Default().Lint(sampleBoard()); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+func TestLint_ExpressionDatasource(t *testing.T) {
+	b := sampleBoard()
+	expr := sdk.NewDatasourceRef(sdk.ExpressionDatasourceUID, sdk.ExpressionDatasourceUID)
+	b.Panels[1].AddTarget(&sdk.Target{Datasource: expr})
+	if problems := lint.Default().Lint(b); len(problems) != 0 {
+		t.Errorf("expected no problems for the expression datasource, got %v", problems)
+	}
+}
+
+func TestLint_DefaultRules(t *testing.T) {
+	b := sampleBoard()
+
+	graph := sdk.NewGraph("Legacy")
+	graph.Datasource = sdk.NewDatasourceRefByName("Prometheus")
+	graph.GridPos = gridPos(6, 4, 12, 8)
+	graph.AddTarget(&sdk.Target{RefID: "A", Expr: `up{instance="$instance"}`})
+	// AddTarget renames duplicates so the one from an imported dashboard
+	// is appended directly.
+	graph.GraphPanel.Targets = append(graph.GraphPanel.Targets, sdk.Target{RefID: "A", Expr: "up"})
+	b.AddPanel(graph)
+
+	noDS := sdk.NewTimeseries("No datasource")
+	noDS.TimeseriesPanel.FieldConfig.Defaults.Unit = "s"
+	noDS.GridPos = gridPos(0, 20, 24, 8)
+	noDS.AddTarget(&sdk.Target{Expr: "up"})
+	b.AddPanel(noDS)
+	noDS.ID = b.Panels[0].ID
+
+	problems := lint.Default().Lint(b)
+	exp := map[string]int{
+		lint.RuleUndefinedVariable:   1,
+		lint.RuleDuplicateRefID:      1,
+		lint.RuleDuplicatePanelID:    1,
+		lint.RuleLegacyPanel:         1,
+		lint.RulePanelUnit:           1,
+		lint.RuleHardcodedDatasource: 1,
+		lint.RulePanelDatasource:     1,
+		lint.RuleOverlappingPanels:   2,
+	}
+	got := rulesOf(problems)
+	for rule, n := range exp {
+		if got[rule] != n {
+			t.Errorf("expected %d problems of %s, got %d", n, rule, got[rule])
+		}
+	}
+	if len(problems) != 9 {
+		t.Errorf("expected 9 problems, got %d: %v", len(problems), problems)
+	}
+	if problems[0].Severity != lint.SeverityError {
+		t.Errorf("expected errors first, got %v", problems[0])
+	}
+	if lint.MaxSeverity(problems) != lint.SeverityError {
+		t.Errorf("expected max severity error, got %s", lint.MaxSeverity(problems))
+	}
+}
+
+func TestLinter_SetSeverity(t *testing.T) {
+	b := sampleBoard()
+	b.Panels[0].TimeseriesPanel.FieldConfig.Defaults.Unit = ""
+
+	l := lint.Default()
+	if err := l.SetSeverity(lint.RulePanelUnit, lint.SeverityError); err != nil {
+		t.Fatal(err)
+	}
+	problems := l.Lint(b)
+	if len(problems) != 1 || problems[0].Severity != lint.SeverityError {
+		t.Fatalf("expected one error, got %v", problems)
+	}
+	if problems[0].String() != `error: panel "Requests" (id 1): unit is not set [panel-unit]` {
+		t.Errorf("unexpected problem text %q", problems[0].String())
+	}
+
+	if err := l.SetSeverity(lint.RulePanelUnit, lint.SeverityOff); err != nil {
+		t.Fatal(err)
+	}
+	if problems = l.Lint(b); len(problems) != 0 {
+		t.Errorf("expected disabled rule to be skipped, got %v", problems)
+	}
+	if err := l.SetSeverity("no-such-rule", lint.SeverityInfo); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
+
+func TestLinter_CustomRule(t *testing.T) {
+	l := lint.New(lint.Rule{
+		Name:     "title-case",
+		Severity: lint.SeverityWarning,
+		Check: func(b *sdk.Board) []lint.Problem {
+			if b.Title == "sample" {
+				return []lint.Problem{{Message: "title is lowercase"}}
+			}
+			return nil
+		},
+	})
+	problems := l.Lint(sdk.NewBoard("sample"))
+	if len(problems) != 1 || problems[0].Rule != "title-case" || problems[0].Severity != lint.SeverityWarning {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if problems[0].String() != "warning: title is lowercase [title-case]" {
+		t.Errorf("unexpected problem text %q", problems[0].String())
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, exp := range map[string]lint.Severity{"off": lint.SeverityOff, "Info": lint.SeverityInfo, "warning": lint.SeverityWarning, "ERROR": lint.SeverityError} {
+		got, err := lint.ParseSeverity(name)
+		if err != nil || got != exp {
+			t.Errorf("%s: expected %s, got %s (%v)", name, exp, got, err)
+		}
+	}
+	if _, err := lint.ParseSeverity("fatal"); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+}
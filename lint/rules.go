@@ -0,0 +1,303 @@
+package lint
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+)
+
+// Names of the rules shipped with the package.
+const (
+	RulePanelDatasource     = "panel-datasource"
+	RuleUndefinedVariable   = "undefined-variable"
+	RuleDuplicateRefID      = "duplicate-refid"
+	RuleDuplicatePanelID    = "duplicate-panel-id"
+	RuleLegacyPanel         = "legacy-panel"
+	RulePanelUnit           = "panel-unit"
+	RuleHardcodedDatasource = "hardcoded-datasource"
+	RuleOverlappingPanels   = "overlapping-panels"
+)
+
+// DefaultRules returns the rules shipped with the package with their
+// default severities.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:        RulePanelDatasource,
+			Description: "panels with queries should have a datasource",
+			Severity:    SeverityWarning,
+			Check:       checkPanelDatasource,
+		},
+		{
+			Name:        RuleUndefinedVariable,
+			Description: "queries should reference only the dashboard variables",
+			Severity:    SeverityError,
+			Check:       checkUndefinedVariables,
+		},
+		{
+			Name:        RuleDuplicateRefID,
+			Description: "queries of a panel should have unique RefIDs",
+			Severity:    SeverityError,
+			Check:       checkDuplicateRefIDs,
+		},
+		{
+			Name:        RuleDuplicatePanelID,
+			Description: "panels should have unique IDs",
+			Severity:    SeverityError,
+			Check:       checkDuplicatePanelIDs,
+		},
+		{
+			Name:        RuleLegacyPanel,
+			Description: "deprecated panels should be migrated to their replacements",
+			Severity:    SeverityInfo,
+			Check:       checkLegacyPanels,
+		},
+		{
+			Name:        RulePanelUnit,
+			Description: "panels showing values should have a unit",
+			Severity:    SeverityInfo,
+			Check:       checkPanelUnits,
+		},
+		{
+			Name:        RuleHardcodedDatasource,
+			Description: "datasources should be selected by a variable",
+			Severity:    SeverityWarning,
+			Check:       checkHardcodedDatasources,
+		},
+		{
+			Name:        RuleOverlappingPanels,
+			Description: "panels should not overlap each other",
+			Severity:    SeverityWarning,
+			Check:       checkOverlappingPanels,
+		},
+	}
+}
+
+// Datasources that have a special meaning and are not real datasources.
+var specialDatasources = map[string]bool{
+	sdk.MixedSource:     true,
+	sdk.GrafanaSource:   true,
+	sdk.DashboardSource: true,
+	sdk.DefaultSource:   true,
+	"grafana":           true,
+	// Server side expressions
+	sdk.ExpressionDatasourceUID: true,
+}
+
+// Variables that datasources define for their queries besides the
+// built-in ones starting with "__".
+var datasourceVariables = map[string]bool{
+	"interval":    true,
+	"timeFilter":  true,
+	"col":         true,
+	"m":           true,
+	"measurement": true,
+}
+
+func panelProblem(p *sdk.Panel, format string, args ...interface{}) Problem {
+	return Problem{PanelID: p.ID, Panel: p.Title, Message: fmt.Sprintf(format, args...)}
+}
+
+// panels returns all panels of the board including the panels of rows.
+func panels(b *sdk.Board) []*sdk.Panel {
+	var result []*sdk.Panel
+	for _, p := range b.Panels {
+		result = append(result, p)
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				result = append(result, &p.RowPanel.Panels[i])
+			}
+		}
+	}
+	for _, r := range b.Rows {
+		for i := range r.Panels {
+			result = append(result, &r.Panels[i])
+		}
+	}
+	return result
+}
+
+func targets(p *sdk.Panel) []sdk.Target {
+	if t := p.GetTargets(); t != nil {
+		return *t
+	}
+	return nil
+}
+
+func checkPanelDatasource(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, p := range panels(b) {
+		if p.GetTargets() == nil || p.Datasource != nil {
+			continue
+		}
+		for _, t := range targets(p) {
+			if t.Datasource == nil {
+				problems = append(problems, panelProblem(p, "no datasource, the default one is used"))
+				break
+			}
+		}
+	}
+	return problems
+}
+
+func checkUndefinedVariables(b *sdk.Board) []Problem {
+	defined := make(map[string]bool, len(b.Templating.List))
+	for _, v := range b.Templating.List {
+		defined[v.Name] = true
+	}
+	var problems []Problem
+	for _, p := range panels(b) {
+		texts := []string{p.Title, datasourceName(p.Datasource)}
+		if p.Repeat != nil && *p.Repeat != "" {
+			texts = append(texts, "$"+*p.Repeat)
+		}
+		for _, t := range targets(p) {
+			texts = append(texts, datasourceName(t.Datasource), t.Expr, t.Query, t.RawSql, t.Target, t.LegendFormat, t.Alias)
+		}
+		var undefined []string
+		for _, name := range sdk.ReferencedVariables(strings.Join(texts, "\n")) {
+			if defined[name] || p.ScopedVars[name].Value != "" || isBuiltinVariable(name) {
+				continue
+			}
+			undefined = append(undefined, "$"+name)
+		}
+		if len(undefined) > 0 {
+			problems = append(problems, panelProblem(p, "undefined variables %s", strings.Join(undefined, ", ")))
+		}
+	}
+	return problems
+}
+
+func isBuiltinVariable(name string) bool {
+	return strings.HasPrefix(name, "__") || strings.HasPrefix(name, "tag_") || datasourceVariables[name]
+}
+
+func checkDuplicateRefIDs(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, p := range panels(b) {
+		seen := make(map[string]bool)
+		for _, t := range targets(p) {
+			if seen[t.RefID] {
+				problems = append(problems, panelProblem(p, "duplicate query RefID %q", t.RefID))
+			}
+			seen[t.RefID] = true
+		}
+	}
+	return problems
+}
+
+func checkDuplicatePanelIDs(b *sdk.Board) []Problem {
+	var (
+		problems []Problem
+		seen     = make(map[uint]string)
+	)
+	for _, p := range panels(b) {
+		if p.ID == 0 {
+			continue
+		}
+		if title, ok := seen[p.ID]; ok {
+			problems = append(problems, panelProblem(p, "ID is already used by panel %q", title))
+			continue
+		}
+		seen[p.ID] = p.Title
+	}
+	return problems
+}
+
+func checkLegacyPanels(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, p := range panels(b) {
+		switch {
+		case p.OfType == sdk.GraphType:
+			problems = append(problems, panelProblem(p, "graph panel is deprecated, use timeseries"))
+		case p.OfType == sdk.SinglestatType:
+			problems = append(problems, panelProblem(p, "singlestat panel is deprecated, use stat"))
+		case p.IsLegacyTable():
+			problems = append(problems, panelProblem(p, "legacy table panel is deprecated, use table"))
+		}
+	}
+	return problems
+}
+
+func checkPanelUnits(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, p := range panels(b) {
+		var missing bool
+		switch p.OfType {
+		case sdk.TimeseriesType:
+			missing = p.TimeseriesPanel.FieldConfig.Defaults.Unit == ""
+		case sdk.BarGaugeType:
+			missing = p.BarGaugePanel.FieldConfig.Defaults.Unit == ""
+		case sdk.GaugeType:
+			missing = p.GaugePanel.FieldConfig.Defaults.Unit == ""
+		case sdk.StatType:
+			missing = p.StatPanel.FieldConfig != nil && p.StatPanel.FieldConfig.Defaults.Unit == ""
+		case sdk.GraphType:
+			missing = len(p.GraphPanel.Yaxes) == 0 || p.GraphPanel.Yaxes[0].Format == ""
+		case sdk.SinglestatType:
+			missing = p.SinglestatPanel.Format == ""
+		}
+		if missing {
+			problems = append(problems, panelProblem(p, "unit is not set"))
+		}
+	}
+	return problems
+}
+
+func checkHardcodedDatasources(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, p := range panels(b) {
+		names := []string{datasourceName(p.Datasource)}
+		for _, t := range targets(p) {
+			names = append(names, datasourceName(t.Datasource))
+		}
+		reported := make(map[string]bool)
+		for _, name := range names {
+			if name == "" || reported[name] || specialDatasources[name] || strings.Contains(name, "$") {
+				continue
+			}
+			reported[name] = true
+			problems = append(problems, panelProblem(p, "datasource %q is hardcoded, use a datasource variable", name))
+		}
+	}
+	return problems
+}
+
+// datasourceName returns the datasource UID or the name for the legacy
+// reference. It returns "" for the default datasource.
+func datasourceName(ds *sdk.DatasourceRef) string {
+	if ds == nil {
+		return ""
+	}
+	return ds.String()
+}
+
+func checkOverlappingPanels(b *sdk.Board) []Problem {
+	var problems []Problem
+	for _, lp := range b.ValidateLayout() {
+		if lp.OverlapsID != nil {
+			problems = append(problems, Problem{PanelID: lp.PanelID, Panel: lp.Title, Message: lp.Reason})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,506 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Grafana adds new keys to the dashboard model with almost each release
+// and SDK structures never cover all of them. Board, Panel, Target and
+// TemplateVar keep the JSON they were decoded from and marshalLossless()
+// merges it back into the marshalled structure:
+//
+//   - keys unknown to the structure are re-emitted as they were;
+//   - keys known to the structure but absent in the source are not added
+//     while they keep zero values;
+//   - values that are equal to the source ones (e.g. "100" and 100 for
+//     FloatString) are written as they were in the source;
+//   - list elements are merged with the source ones of the same identity
+//     (uid, id, refId, name...) wherever they are in the list, the new
+//     and replaced elements are written as they are.
+//
+// Values changed with the structure fields always win.
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// marshalLossless marshals v and merges the result with the source JSON
+// v was decoded from. It just marshals v if there is no source.
+func marshalLossless(v interface{}, source json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(source) == 0 {
+		return b, err
+	}
+	var original, marshalled interface{}
+	if err = decodeWithNumbers(source, &original); err != nil {
+		// Source is not a valid JSON so there is nothing to preserve.
+		return b, nil
+	}
+	if err = decodeWithNumbers(b, &marshalled); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeJSON(original, marshalled, reflect.TypeOf(v), true))
+}
+
+// keepSource returns a copy of the source JSON because decoder may reuse
+// the buffer.
+func keepSource(b []byte) json.RawMessage {
+	return append(json.RawMessage{}, b...)
+}
+
+// keptSource is embedded into the structures that keep the JSON they
+// were decoded from with decodeLossless().
+type keptSource struct {
+	// JSON the structure was decoded from, see marshalLossless()
+	source json.RawMessage
+	// keys of the source which values don't fit the fields of the same
+	// name and could not be decoded, see decodeTolerant()
+	skipped []string
+}
+
+// Skipped returns the keys of the source JSON which values don't fit the
+// types of the structure fields, e.g. "groupBy" objects of InfluxDB
+// targets. They are not decoded but marshalled back as they were.
+func (s keptSource) Skipped() []string {
+	return s.skipped
+}
+
+// decodeLossless decodes the JSON object into v with decodeTolerant() and
+// returns the source and the skipped keys to keep in the structure. V
+// should point to the type without its own UnmarshalJSON(), it is left
+// untouched on errors.
+func decodeLossless(data []byte, v interface{}) (json.RawMessage, []string, error) {
+	rv := reflect.ValueOf(v).Elem()
+	decoded := reflect.New(rv.Type())
+	skipped, err := decodeTolerant(data, decoded.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+	rv.Set(decoded.Elem())
+	return keepSource(data), skipped, nil
+}
+
+// encodeLossless is the reverse of decodeLossless(): it marshals v with
+// the source JSON merged and the skipped keys restored.
+func encodeLossless(v interface{}, source json.RawMessage, skipped []string) ([]byte, error) {
+	b, err := marshalLossless(v, source)
+	if err != nil {
+		return nil, err
+	}
+	return restoreKeys(b, source, skipped)
+}
+
+// decodeTolerant decodes the JSON object into v skipping the keys which
+// values do not fit types of their fields, e.g. "groupBy" of InfluxDB
+// targets is a list of objects while Target expects Stackdriver strings.
+// It returns the skipped keys so they could be restored with
+// restoreKeys() on marshalling.
+func decodeTolerant(data []byte, v interface{}) ([]string, error) {
+	err := json.Unmarshal(data, v)
+	var typeErr *json.UnmarshalTypeError
+	if err == nil || !errors.As(err, &typeErr) {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(data, &obj) != nil {
+		return nil, err
+	}
+	var (
+		skipped []string
+		rv      = reflect.ValueOf(v).Elem()
+	)
+	for k, raw := range obj {
+		single, _ := json.Marshal(map[string]json.RawMessage{k: raw})
+		if json.Unmarshal(single, reflect.New(rv.Type()).Interface()) != nil {
+			skipped = append(skipped, k)
+			delete(obj, k)
+		}
+	}
+	sort.Strings(skipped)
+	rest, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	rv.Set(reflect.Zero(rv.Type()))
+	return skipped, json.Unmarshal(rest, v)
+}
+
+// restoreKeys puts the keys skipped by decodeTolerant() back from the
+// source JSON unless they were set with the structure fields.
+func restoreKeys(b []byte, source json.RawMessage, keys []string) ([]byte, error) {
+	if len(keys) == 0 {
+		return b, nil
+	}
+	var result, original map[string]json.RawMessage
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(source, &original); err != nil {
+		return b, nil
+	}
+	for _, k := range keys {
+		if _, ok := result[k]; !ok {
+			if v, ok := original[k]; ok {
+				result[k] = v
+			}
+		}
+	}
+	return json.Marshal(result)
+}
+
+func decodeWithNumbers(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// mergeJSON merges the marshalled value with the original one. Type t
+// describes the Go type the marshalled value was made of, it is nil
+// for values of interface{} type.
+func mergeJSON(original, marshalled interface{}, t reflect.Type, root bool) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && !root && (t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType)) {
+		// Types with own marshalling are opaque here, Panel and
+		// Target take care of their own source.
+		return mergeScalar(original, marshalled)
+	}
+	switch m := marshalled.(type) {
+	case map[string]interface{}:
+		o, ok := original.(map[string]interface{})
+		if !ok {
+			return marshalled
+		}
+		return mergeObject(o, m, t)
+	case []interface{}:
+		o, ok := original.([]interface{})
+		if !ok {
+			return marshalled
+		}
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		return mergeList(o, m, elem)
+	}
+	return mergeScalar(original, marshalled)
+}
+
+// identityKeys identify elements of the lists in dashboard JSON, e.g.
+// "name" of annotations, "refId" of targets, "title" and "url" of links.
+var identityKeys = []string{"uid", "id", "refId", "name", "title", "url", "type"}
+
+// mergeList merges the marshalled list elements with the original ones of
+// the same identity wherever they are in the list, so inserted, removed or
+// reordered elements don't shift other elements off their source. An
+// element with some of its identity keys changed (e.g. a renamed link) is
+// merged with the original one that keeps the others. Elements without
+// identity are merged by position if the length of the list is the same.
+// Elements that match no original one are written as they are.
+func mergeList(original, marshalled []interface{}, elem reflect.Type) []interface{} {
+	var (
+		source = make([]int, len(marshalled))
+		used   = make([]bool, len(original))
+	)
+	match := func(i int, same func(o, m map[string]interface{}) bool) {
+		m, ok := marshalled[i].(map[string]interface{})
+		if source[i] >= 0 || !ok || !hasIdentity(m) {
+			return
+		}
+		try := func(j int) bool {
+			o, ok := original[j].(map[string]interface{})
+			if used[j] || !ok || !same(o, m) {
+				return false
+			}
+			source[i], used[j] = j, true
+			return true
+		}
+		if i < len(original) && try(i) {
+			return
+		}
+		for j := range original {
+			if try(j) {
+				return
+			}
+		}
+	}
+	for i := range source {
+		source[i] = -1
+	}
+	for i := range marshalled {
+		match(i, sameIdentity)
+	}
+	for i := range marshalled {
+		match(i, similarIdentity)
+	}
+	result := make([]interface{}, len(marshalled))
+	for i, m := range marshalled {
+		j := source[i]
+		if j < 0 && len(original) == len(marshalled) && !hasIdentity(m) && !hasIdentity(original[i]) {
+			j = i
+		}
+		if j < 0 {
+			// The element is new or replaced, keys of the old one
+			// don't belong to it.
+			result[i] = m
+			continue
+		}
+		result[i] = mergeJSON(original[j], m, elem, false)
+	}
+	return result
+}
+
+// hasIdentity reports whether the value is an object with identity keys.
+func hasIdentity(v interface{}) bool {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, k := range identityKeys {
+		if !isZeroJSON(obj[k]) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameIdentity reports whether the original and the marshalled list
+// elements are the same element: all their identity keys are equal.
+func sameIdentity(original, marshalled map[string]interface{}) bool {
+	for _, k := range identityKeys {
+		if !equalJSON(original[k], marshalled[k]) && !equalJSON(marshalled[k], original[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// similarIdentity reports whether the marshalled list element is the
+// original one with some of identity keys changed: the type is the same
+// and at least one other identity key is set and equal.
+func similarIdentity(original, marshalled map[string]interface{}) bool {
+	if !equalJSON(original["type"], marshalled["type"]) && !equalJSON(marshalled["type"], original["type"]) {
+		return false
+	}
+	for _, k := range identityKeys {
+		if k != "type" && !isZeroJSON(marshalled[k]) && equalJSON(original[k], marshalled[k]) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeObject(original, marshalled map[string]interface{}, t reflect.Type) interface{} {
+	var (
+		fields   map[string]reflect.Type
+		elem     reflect.Type
+		isStruct = t != nil && t.Kind() == reflect.Struct
+	)
+	switch {
+	case isStruct:
+		fields = jsonFields(t)
+	case t != nil && t.Kind() == reflect.Map:
+		elem = t.Elem()
+	}
+	result := make(map[string]interface{}, len(marshalled))
+	for k, v := range marshalled {
+		o, ok := original[k]
+		if !ok {
+			if isStruct && isZeroJSON(v) {
+				continue
+			}
+			result[k] = v
+			continue
+		}
+		ft := elem
+		if isStruct {
+			ft = fields[k]
+		}
+		result[k] = mergeJSON(o, v, ft, false)
+	}
+	if !isStruct {
+		// Maps and values of interface{} type are fully described by
+		// the marshalled value, absent keys were removed.
+		return result
+	}
+	for k, o := range original {
+		if _, ok := marshalled[k]; ok {
+			continue
+		}
+		if _, known := fields[k]; known && !isZeroJSON(o) {
+			// The field was cleared and omitted on marshalling.
+			continue
+		}
+		result[k] = o
+	}
+	return result
+}
+
+func mergeScalar(original, marshalled interface{}) interface{} {
+	if equalJSON(original, marshalled) {
+		return original
+	}
+	return marshalled
+}
+
+// equalJSON compares decoded JSON values loosely: numbers are compared
+// by value, numeric strings are equal to numbers, null is equal to zero
+// values and a string is equal to the slice with this single string.
+func equalJSON(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	switch av := a.(type) {
+	case nil:
+		// Fields of non-pointer types can't keep null.
+		return b == "null" || isZeroJSON(b)
+	case string:
+		if av == "null" && b == nil {
+			return true
+		}
+		switch bv := b.(type) {
+		case json.Number:
+			return equalNumbers(av, string(bv))
+		case []interface{}:
+			// StringSliceString marshals a single string as a slice.
+			return len(bv) == 1 && bv[0] == av
+		}
+	case json.Number:
+		switch bv := b.(type) {
+		case json.Number:
+			return equalNumbers(string(av), string(bv))
+		case string:
+			return equalNumbers(string(av), bv)
+		}
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if !equalJSON(v, bv[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalJSON(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func equalNumbers(a, b string) bool {
+	af, err := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	if err != nil {
+		return false
+	}
+	bf, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	return err == nil && af == bf
+}
+
+func isZeroJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isZeroJSON(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+var jsonFieldsCache sync.Map // map[reflect.Type]map[string]reflect.Type
+
+// jsonFields returns JSON keys of the struct with types of their fields.
+// Fields of embedded structs are promoted as encoding/json does.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if f, ok := jsonFieldsCache.Load(t); ok {
+		return f.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type)
+	collectJSONFields(t, fields)
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectJSONFields(t reflect.Type, fields map[string]reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields[name]; !ok {
+			fields[name] = ft
+		}
+	}
+	// Fields of the struct itself shadow the promoted ones.
+	for _, ft := range embedded {
+		collectJSONFields(ft, fields)
+	}
+}
@@ -0,0 +1,216 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+var rawBoardWithUnknownKeys = []byte(`{
+	"title": "Unknown keys",
+	"uid": "unknown-keys",
+	"fiscalYearStartMonth": 3,
+	"liveNow": true,
+	"weekStart": "monday",
+	"panels": [
+		{
+			"id": 1,
+			"type": "timeseries",
+			"title": "Requests",
+			"pluginVersion": "8.3.0",
+			"description": "to be removed",
+			"targets": [
+				{"refId": "A", "expr": "rate(requests[5m])", "exemplar": true, "editorMode": "code"}
+			],
+			"fieldConfig": {"defaults": {"unit": "reqps", "someFutureOption": {"enabled": true}}}
+		}
+	],
+	"templating": {
+		"list": [
+			{"name": "env", "type": "custom", "query": "prod,dev", "skipUrlSync": true, "definition": "prod,dev"}
+		]
+	}
+}`)
+
+func TestBoard_UnknownKeysPreserved(t *testing.T) {
+	var board sdk.Board
+	if err := json.Unmarshal(rawBoardWithUnknownKeys, &board); err != nil {
+		t.Fatal(err)
+	}
+	board.Title = "Changed title"
+	board.Panels[0].Description = nil
+	board.Panels[0].TimeseriesPanel.Targets[0].Expr = "up"
+	board.Templating.List[0].Label = "Environment"
+
+	out, err := json.Marshal(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Title                string  `json:"title"`
+		FiscalYearStartMonth int     `json:"fiscalYearStartMonth"`
+		LiveNow              bool    `json:"liveNow"`
+		WeekStart            string  `json:"weekStart"`
+		Rows                 *[]bool `json:"rows"`
+		Panels               []map[string]interface{}
+		Templating           struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"templating"`
+	}
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Changed title" {
+		t.Errorf("changed title should be written, got %q", got.Title)
+	}
+	if got.FiscalYearStartMonth != 3 || !got.LiveNow || got.WeekStart != "monday" {
+		t.Errorf("unknown board keys are lost:\n%s", out)
+	}
+	if got.Rows != nil {
+		t.Errorf("empty rows should not be added:\n%s", out)
+	}
+
+	panel := got.Panels[0]
+	if panel["pluginVersion"] != "8.3.0" {
+		t.Errorf("unknown panel keys are lost:\n%s", out)
+	}
+	if _, ok := panel["description"]; ok {
+		t.Errorf("cleared description should not be restored:\n%s", out)
+	}
+	defaults := panel["fieldConfig"].(map[string]interface{})["defaults"].(map[string]interface{})
+	if _, ok := defaults["someFutureOption"]; !ok {
+		t.Errorf("unknown nested keys are lost:\n%s", out)
+	}
+	target := panel["targets"].([]interface{})[0].(map[string]interface{})
+	if target["expr"] != "up" || target["exemplar"] != true || target["editorMode"] != "code" {
+		t.Errorf("unknown target keys are lost:\n%s", out)
+	}
+
+	tv := got.Templating.List[0]
+	if tv["label"] != "Environment" || tv["skipUrlSync"] != true || tv["definition"] != "prod,dev" {
+		t.Errorf("unknown template variable keys are lost:\n%s", out)
+	}
+}
+
+func TestPanel_UnknownKeysDroppedOnTypeChange(t *testing.T) {
+	var panel sdk.Panel
+	if err := json.Unmarshal([]byte(`{"id": 1, "type": "graph", "title": "Old", "pluginVersion": "7.0.0", "yaxes": []}`), &panel); err != nil {
+		t.Fatal(err)
+	}
+	panel.GraphPanel = nil
+	panel.TimeseriesPanel = sdk.NewTimeseries("").TimeseriesPanel
+	panel.Type = "timeseries"
+	panel.OfType = sdk.TimeseriesType
+
+	out, err := json.Marshal(&panel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["yaxes"]; ok {
+		t.Errorf("keys of the graph panel should not be kept:\n%s", out)
+	}
+}
+
+func TestBoard_ReplacedListElementsDropOldKeys(t *testing.T) {
+	raw := []byte(`{
+		"title": "Replaced",
+		"annotations": {"list": [
+			{"builtIn": 1, "name": "Annotations & Alerts", "type": "dashboard", "enable": true,
+			 "target": {"limit": 100, "matchAny": false, "type": "dashboard"}}]},
+		"links": [
+			{"title": "Docs", "type": "link", "url": "https://example.com/docs", "futureKey": true},
+			{"title": "Runbook", "type": "link", "url": "https://example.com/runbook", "futureKey": true}]
+	}`)
+	var board sdk.Board
+	if err := json.Unmarshal(raw, &board); err != nil {
+		t.Fatal(err)
+	}
+	board.Annotations.List[0] = sdk.Annotation{Name: "Deploys", Type: "tags", Tags: []string{"deploy"}, Enable: true}
+	status := "https://status.example.com"
+	board.Links[0] = sdk.Link{Title: "Status", Type: "link", URL: &status}
+	board.Links[1].Title = "Runbook"
+
+	out, err := json.Marshal(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Annotations struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"annotations"`
+		Links []map[string]interface{} `json:"links"`
+	}
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	annotation := got.Annotations.List[0]
+	if _, ok := annotation["builtIn"]; ok || annotation["target"] != nil || annotation["name"] != "Deploys" {
+		t.Errorf("keys of the replaced annotation are kept:\n%s", out)
+	}
+	if _, ok := got.Links[0]["futureKey"]; ok || got.Links[0]["title"] != "Status" {
+		t.Errorf("keys of the replaced link are kept:\n%s", out)
+	}
+	if got.Links[1]["futureKey"] != true {
+		t.Errorf("unknown keys of the unchanged link are lost:\n%s", out)
+	}
+}
+
+func TestBoard_RenamedAndRemovedLinksKeepKeys(t *testing.T) {
+	raw := []byte(`{
+		"title": "Links",
+		"links": [
+			{"title": "Docs", "type": "link", "url": "https://example.com/docs", "futureKey": "docs"},
+			{"title": "Runbook", "type": "link", "url": "https://example.com/runbook", "futureKey": "runbook"},
+			{"title": "Status", "type": "link", "url": "https://status.example.com", "futureKey": "status"}]
+	}`)
+	var board sdk.Board
+	if err := json.Unmarshal(raw, &board); err != nil {
+		t.Fatal(err)
+	}
+	board.Links = board.Links[1:]
+	board.Links[0].Title = "Run book"
+
+	out, err := json.Marshal(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Links []map[string]interface{} `json:"links"`
+	}
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Links) != 2 {
+		t.Fatalf("expected 2 links, got:\n%s", out)
+	}
+	if got.Links[0]["title"] != "Run book" || got.Links[0]["futureKey"] != "runbook" {
+		t.Errorf("the renamed link should keep its own unknown keys:\n%s", out)
+	}
+	if got.Links[1]["title"] != "Status" || got.Links[1]["futureKey"] != "status" {
+		t.Errorf("the link after the removed one should keep its own unknown keys:\n%s", out)
+	}
+}
@@ -42,6 +42,7 @@ const (
 	BarGaugeType
 	HeatmapType
 	TimeseriesType
+	GaugeType
 )
 
 const MixedSource = "-- Mixed --"
@@ -64,28 +65,27 @@ type (
 		*BarGaugePanel
 		*HeatmapPanel
 		*TimeseriesPanel
+		*GaugePanel
 		*CustomPanel
+		// JSON the panel was decoded from, see marshalLossless()
+		source     json.RawMessage
+		sourceType string
 	}
 	panelType   int8
 	CommonPanel struct {
-		Datasource interface{} `json:"datasource,omitempty"` // metrics
-		Editable   bool        `json:"editable"`
-		Error      bool        `json:"error"`
-		GridPos    struct {
-			H *int `json:"h,omitempty"`
-			W *int `json:"w,omitempty"`
-			X *int `json:"x,omitempty"`
-			Y *int `json:"y,omitempty"`
-		} `json:"gridPos,omitempty"`
-		Height           interface{} `json:"height,omitempty"` // general
-		HideTimeOverride *bool       `json:"hideTimeOverride,omitempty"`
-		ID               uint        `json:"id"`
-		IsNew            bool        `json:"isNew"`
-		Links            []Link      `json:"links,omitempty"`    // general
-		MinSpan          *float32    `json:"minSpan,omitempty"`  // templating options
-		OfType           panelType   `json:"-"`                  // it required for defining type of the panel
-		Renderer         *string     `json:"renderer,omitempty"` // display styles
-		Repeat           *string     `json:"repeat,omitempty"`   // templating options
+		Datasource       *DatasourceRef `json:"datasource,omitempty"` // metrics
+		Editable         bool           `json:"editable"`
+		Error            bool           `json:"error"`
+		GridPos          GridPos        `json:"gridPos,omitempty"`
+		Height           interface{}    `json:"height,omitempty"` // general
+		HideTimeOverride *bool          `json:"hideTimeOverride,omitempty"`
+		ID               uint           `json:"id"`
+		IsNew            bool           `json:"isNew"`
+		Links            []Link         `json:"links,omitempty"`    // general
+		MinSpan          *float32       `json:"minSpan,omitempty"`  // templating options
+		OfType           panelType      `json:"-"`                  // it required for defining type of the panel
+		Renderer         *string        `json:"renderer,omitempty"` // display styles
+		Repeat           *string        `json:"repeat,omitempty"`   // templating options
 		// RepeatIteration *int64   `json:"repeatIteration,omitempty"`
 		RepeatPanelID *uint `json:"repeatPanelId,omitempty"`
 		ScopedVars    map[string]struct {
@@ -99,6 +99,18 @@ type (
 		Transparent bool    `json:"transparent"`
 		Type        string  `json:"type"`
 		Alert       *Alert  `json:"alert,omitempty"`
+		// Transformations applied to the query results, from grafana 7.x
+		Transformations []Transformation `json:"transformations,omitempty"`
+		// Query results embedded into snapshots, see Board.MakeSnapshot()
+		SnapshotData []interface{} `json:"snapshotData,omitempty"`
+	}
+	// GridPos defines position and size of the panel on the dashboard grid
+	// of GridColumnCount columns (Grafana 5+).
+	GridPos struct {
+		H *int `json:"h,omitempty"`
+		W *int `json:"w,omitempty"`
+		X *int `json:"x,omitempty"`
+		Y *int `json:"y,omitempty"`
 	}
 	AlertEvaluator struct {
 		Params []float64 `json:"params,omitempty"`
@@ -168,7 +180,24 @@ type (
 		FieldConfig     *FieldConfig     `json:"fieldConfig,omitempty"`
 	}
 	FieldConfig struct {
-		Defaults FieldConfigDefaults `json:"defaults"`
+		Defaults  FieldConfigDefaults   `json:"defaults"`
+		Overrides []FieldConfigOverride `json:"overrides,omitempty"`
+	}
+	// FieldConfigOverride applies the list of properties to the fields
+	// selected by the matcher.
+	FieldConfigOverride struct {
+		Matcher    FieldMatcher            `json:"matcher"`
+		Properties []FieldOverrideProperty `json:"properties"`
+	}
+	FieldMatcher struct {
+		ID      string      `json:"id"`
+		Options interface{} `json:"options,omitempty"`
+	}
+	// FieldOverrideProperty sets a single field config property. ID is the
+	// property path as Grafana names it (e.g. "unit", "custom.width").
+	FieldOverrideProperty struct {
+		ID    string      `json:"id"`
+		Value interface{} `json:"value"`
 	}
 	Options struct {
 		Orientation   string `json:"orientation"`
@@ -184,6 +213,9 @@ type (
 			Fields string   `json:"fields"`
 			Calcs  []string `json:"calcs"`
 		} `json:"reduceOptions"`
+		// For the gauge panel.
+		ShowThresholdLabels  bool `json:"showThresholdLabels,omitempty"`
+		ShowThresholdMarkers bool `json:"showThresholdMarkers,omitempty"`
 	}
 	Threshold struct {
 		// the alert threshold value, we do not omitempty, since 0 is a valid
@@ -209,13 +241,38 @@ type (
 		MsResolution bool   `json:"msResolution,omitempty"` // was added in Grafana 3.x
 		Sort         int    `json:"sort,omitempty"`
 	}
+	// TablePanel keeps both generations of the table panel. Columns,
+	// Sort, Styles, Transform and Scroll belong to the legacy Angular
+	// table (Grafana < 7 or "table-old"), Options and FieldConfig
+	// belong to the table panel introduced in Grafana 7.
 	TablePanel struct {
-		Columns   []Column      `json:"columns"`
-		Sort      *Sort         `json:"sort,omitempty"`
-		Styles    []ColumnStyle `json:"styles"`
-		Transform string        `json:"transform"`
-		Targets   []Target      `json:"targets,omitempty"`
-		Scroll    bool          `json:"scroll"` // from grafana 3.x
+		Columns     []Column      `json:"columns"`
+		Sort        *Sort         `json:"sort,omitempty"`
+		Styles      []ColumnStyle `json:"styles"`
+		Transform   string        `json:"transform"`
+		Targets     []Target      `json:"targets,omitempty"`
+		Scroll      bool          `json:"scroll"`                // from grafana 3.x
+		Options     *TableOptions `json:"options,omitempty"`     // from grafana 7.x
+		FieldConfig *FieldConfig  `json:"fieldConfig,omitempty"` // from grafana 7.x
+	}
+	TableOptions struct {
+		ShowHeader    bool                    `json:"showHeader"`
+		ShowTypeIcons bool                    `json:"showTypeIcons,omitempty"`
+		CellHeight    string                  `json:"cellHeight,omitempty"`
+		FrameIndex    int                     `json:"frameIndex,omitempty"`
+		Footer        *TableFooterOptions     `json:"footer,omitempty"`
+		SortBy        []TableSortByFieldState `json:"sortBy,omitempty"`
+	}
+	TableFooterOptions struct {
+		Show             bool     `json:"show"`
+		Reducer          []string `json:"reducer"`
+		Fields           []string `json:"fields,omitempty"`
+		CountRows        bool     `json:"countRows,omitempty"`
+		EnablePagination bool     `json:"enablePagination,omitempty"`
+	}
+	TableSortByFieldState struct {
+		DisplayName string `json:"displayName"`
+		Desc        bool   `json:"desc,omitempty"`
 	}
 	TextPanel struct {
 		Content     string        `json:"content"`
@@ -254,29 +311,33 @@ type (
 		ValueMaps       []ValueMap  `json:"valueMaps"`
 		ValueName       string      `json:"valueName"`
 	}
+	// StatPanel keeps both generations of the stat panel. Options and
+	// FieldConfig belong to the stat panel of Grafana 7+, other fields
+	// are left from the times it shared the model with singlestat.
 	StatPanel struct {
-		Colors          []string    `json:"colors"`
-		ColorValue      bool        `json:"colorValue"`
-		ColorBackground bool        `json:"colorBackground"`
-		Decimals        int         `json:"decimals"`
-		Format          string      `json:"format"`
-		Gauge           Gauge       `json:"gauge,omitempty"`
-		MappingType     *uint       `json:"mappingType,omitempty"`
-		MappingTypes    []*MapType  `json:"mappingTypes,omitempty"`
-		MaxDataPoints   *IntString  `json:"maxDataPoints,omitempty"`
-		NullPointMode   string      `json:"nullPointMode"`
-		Postfix         *string     `json:"postfix,omitempty"`
-		PostfixFontSize *string     `json:"postfixFontSize,omitempty"`
-		Prefix          *string     `json:"prefix,omitempty"`
-		PrefixFontSize  *string     `json:"prefixFontSize,omitempty"`
-		RangeMaps       []*RangeMap `json:"rangeMaps,omitempty"`
-		SparkLine       SparkLine   `json:"sparkline,omitempty"`
-		Targets         []Target    `json:"targets,omitempty"`
-		Thresholds      string      `json:"thresholds"`
-		ValueFontSize   string      `json:"valueFontSize"`
-		ValueMaps       []ValueMap  `json:"valueMaps"`
-		ValueName       string      `json:"valueName"`
-		Options         Options     `json:"options"`
+		Colors          []string     `json:"colors"`
+		ColorValue      bool         `json:"colorValue"`
+		ColorBackground bool         `json:"colorBackground"`
+		Decimals        int          `json:"decimals"`
+		Format          string       `json:"format"`
+		Gauge           Gauge        `json:"gauge,omitempty"`
+		MappingType     *uint        `json:"mappingType,omitempty"`
+		MappingTypes    []*MapType   `json:"mappingTypes,omitempty"`
+		MaxDataPoints   *IntString   `json:"maxDataPoints,omitempty"`
+		NullPointMode   string       `json:"nullPointMode"`
+		Postfix         *string      `json:"postfix,omitempty"`
+		PostfixFontSize *string      `json:"postfixFontSize,omitempty"`
+		Prefix          *string      `json:"prefix,omitempty"`
+		PrefixFontSize  *string      `json:"prefixFontSize,omitempty"`
+		RangeMaps       []*RangeMap  `json:"rangeMaps,omitempty"`
+		SparkLine       SparkLine    `json:"sparkline,omitempty"`
+		Targets         []Target     `json:"targets,omitempty"`
+		Thresholds      string       `json:"thresholds"`
+		ValueFontSize   string       `json:"valueFontSize"`
+		ValueMaps       []ValueMap   `json:"valueMaps"`
+		ValueName       string       `json:"valueName"`
+		Options         Options      `json:"options"`
+		FieldConfig     *FieldConfig `json:"fieldConfig,omitempty"` // from grafana 7.x
 	}
 	DashlistPanel struct {
 		Mode     string   `json:"mode"`
@@ -359,6 +420,13 @@ type (
 		Targets     []Target          `json:"targets,omitempty"`
 		Options     TimeseriesOptions `json:"options"`
 		FieldConfig FieldConfig       `json:"fieldConfig"`
+		TimeFrom    *string           `json:"timeFrom,omitempty"`
+		TimeShift   *string           `json:"timeShift,omitempty"`
+	}
+	GaugePanel struct {
+		Options     Options     `json:"options"`
+		Targets     []Target    `json:"targets,omitempty"`
+		FieldConfig FieldConfig `json:"fieldConfig"`
 	}
 	TimeseriesOptions struct {
 		Legend  TimeseriesLegendOptions  `json:"legend,omitempty"`
@@ -373,14 +441,23 @@ type (
 		Mode string `json:"mode"`
 	}
 	FieldConfigDefaults struct {
-		Unit       string            `json:"unit"`
-		Decimals   *int              `json:"decimals,omitempty"`
-		Min        *float64          `json:"min,omitempty"`
-		Max        *float64          `json:"max,omitempty"`
-		Color      FieldConfigColor  `json:"color"`
-		Thresholds Thresholds        `json:"thresholds"`
-		Custom     FieldConfigCustom `json:"custom"`
-		Links      []Link            `json:"links,omitempty"`
+		Unit              string            `json:"unit"`
+		Decimals          *int              `json:"decimals,omitempty"`
+		Min               *float64          `json:"min,omitempty"`
+		Max               *float64          `json:"max,omitempty"`
+		Color             FieldConfigColor  `json:"color"`
+		Thresholds        Thresholds        `json:"thresholds"`
+		Custom            FieldConfigCustom `json:"custom"`
+		Links             []Link            `json:"links,omitempty"`
+		Mappings          []ValueMapping    `json:"mappings,omitempty"`
+		NoValue           string            `json:"noValue,omitempty"`
+		DisplayName       string            `json:"displayName,omitempty"`
+		DisplayNameFromDS string            `json:"displayNameFromDS,omitempty"`
+		Description       string            `json:"description,omitempty"`
+		Filterable        *bool             `json:"filterable,omitempty"`
+		Writeable         *bool             `json:"writeable,omitempty"`
+		Path              string            `json:"path,omitempty"`
+		Interval          *float64          `json:"interval,omitempty"`
 	}
 	FieldConfigCustom struct {
 		AxisLabel         string `json:"axisLabel,omitempty"`
@@ -403,6 +480,7 @@ type (
 		} `json:"hideFrom"`
 		LineStyle struct {
 			Fill string `json:"fill"`
+			Dash []int  `json:"dash,omitempty"`
 		} `json:"lineStyle"`
 		ScaleDistribution struct {
 			Type string `json:"type"`
@@ -415,6 +493,13 @@ type (
 		ThresholdsStyle struct {
 			Mode string `json:"mode"`
 		} `json:"thresholdsStyle"`
+		// For the table panel since Grafana 7.
+		Align       string `json:"align,omitempty"`
+		DisplayMode string `json:"displayMode,omitempty"`
+		Width       *int   `json:"width,omitempty"`
+		MinWidth    *int   `json:"minWidth,omitempty"`
+		Filterable  bool   `json:"filterable,omitempty"`
+		Inspect     bool   `json:"inspect,omitempty"`
 	}
 	Thresholds struct {
 		Mode  string          `json:"mode"`
@@ -463,6 +548,7 @@ type (
 		Min      *FloatString `json:"min,omitempty"`
 		Show     bool         `json:"show"`
 		Label    string       `json:"label,omitempty"`
+		Mode     string       `json:"mode,omitempty"` // for the x-axis: time, series or histogram
 	}
 	SeriesOverride struct {
 		Alias         string      `json:"alias"`
@@ -508,6 +594,7 @@ type (
 	}
 	ColumnStyle struct {
 		Alias           *string    `json:"alias"`
+		Align           string     `json:"align,omitempty"`
 		DateFormat      *string    `json:"dateFormat,omitempty"`
 		Pattern         string     `json:"pattern"`
 		Type            string     `json:"type"`
@@ -551,9 +638,9 @@ type (
 
 // for an any panel
 type Target struct {
-	RefID      string      `json:"refId"`
-	Datasource interface{} `json:"datasource,omitempty"`
-	Hide       bool        `json:"hide,omitempty"`
+	RefID      string         `json:"refId"`
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+	Hide       bool           `json:"hide,omitempty"`
 
 	// For PostgreSQL
 	Table        string `json:"table,omitempty"`
@@ -582,7 +669,10 @@ type Target struct {
 	Step           int    `json:"step,omitempty"`
 	LegendFormat   string `json:"legendFormat,omitempty"`
 	Instant        bool   `json:"instant,omitempty"`
+	Range          bool   `json:"range,omitempty"`
+	Exemplar       bool   `json:"exemplar,omitempty"`
 	Format         string `json:"format,omitempty"`
+	EditorMode     string `json:"editorMode,omitempty"`
 
 	// For InfluxDB
 	Measurement string `json:"measurement,omitempty"`
@@ -614,6 +704,10 @@ type Target struct {
 	// For Graphite
 	Target string `json:"target,omitempty"`
 
+	// For server side expressions, see ExpressionDatasourceUID
+	Type       string `json:"type,omitempty"`
+	Expression string `json:"expression,omitempty"`
+
 	// For CloudWatch
 	Namespace  string            `json:"namespace,omitempty"`
 	MetricName string            `json:"metricName,omitempty"`
@@ -640,6 +734,27 @@ type Target struct {
 		Operator string `json:"operator,omitempty"`
 		Value    string `json:"value,omitempty"`
 	} `json:"tags,omitempty"`
+
+	// JSON the target was decoded from and keys of the source that
+	// collide with fields of other datasources, see decodeLossless()
+	keptSource
+}
+
+// UnmarshalJSON decodes the target and keeps the source JSON so fields
+// of datasources unknown to Target are not lost when it is marshalled back.
+// Values that don't fit the fields of the same name (e.g. InfluxDB
+// "groupBy" objects) are not decoded but kept in the source JSON too.
+func (t *Target) UnmarshalJSON(data []byte) error {
+	type plain Target
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t Target) MarshalJSON() ([]byte, error) {
+	type plain Target
+	return encodeLossless(plain(t), t.source, t.skipped)
 }
 
 // StackdriverAlignOptions defines the list of alignment options shown in
@@ -801,6 +916,28 @@ func NewStat(title string) *Panel {
 		StatPanel: &StatPanel{}}
 }
 
+// NewGauge initializes panel with a gauge panel.
+func NewGauge(title string) *Panel {
+	if title == "" {
+		title = "Panel Title"
+	}
+	return &Panel{
+		CommonPanel: CommonPanel{
+			OfType: GaugeType,
+			Title:  title,
+			Type:   "gauge",
+			Span:   12,
+			IsNew:  true,
+		},
+		GaugePanel: &GaugePanel{
+			Options: Options{
+				ShowThresholdLabels:  false,
+				ShowThresholdMarkers: true,
+			},
+		},
+	}
+}
+
 // NewPluginlist initializes panel with a stat panel.
 func NewPluginlist(title string) *Panel {
 	if title == "" {
@@ -847,6 +984,20 @@ func NewHeatmap(title string) *Panel {
 		HeatmapPanel: &HeatmapPanel{}}
 }
 
+// NewRow initializes panel with a row panel (Grafana 5+).
+func NewRow(title string) *Panel {
+	if title == "" {
+		title = "Row title"
+	}
+	return &Panel{
+		CommonPanel: CommonPanel{
+			OfType: RowType,
+			Title:  title,
+			Type:   "row",
+			IsNew:  true},
+		RowPanel: &RowPanel{Panels: []Panel{}}}
+}
+
 // NewCustom initializes panel with a stat panel.
 func NewCustom(title string) *Panel {
 	if title == "" {
@@ -880,14 +1031,20 @@ func (p *Panel) ResetTargets() {
 		p.HeatmapPanel.Targets = nil
 	case TimeseriesType:
 		p.TimeseriesPanel.Targets = nil
+	case GaugeType:
+		p.GaugePanel.Targets = nil
 	}
 }
 
 // AddTarget adds a new target as defined in the argument
 // but with refId letter incremented. Value of refID from
 // the argument will be used only if no target with such
-// value already exists.
+// value already exists. The assigned refID is set to the
+// argument too.
 func (p *Panel) AddTarget(t *Target) {
+	if targets := p.GetTargets(); targets != nil && (t.RefID == "" || hasRefID(*targets, t.RefID)) {
+		t.RefID = nextRefID(*targets)
+	}
 	switch p.OfType {
 	case GraphType:
 		p.GraphPanel.Targets = append(p.GraphPanel.Targets, *t)
@@ -901,8 +1058,9 @@ func (p *Panel) AddTarget(t *Target) {
 		p.HeatmapPanel.Targets = append(p.HeatmapPanel.Targets, *t)
 	case TimeseriesType:
 		p.TimeseriesPanel.Targets = append(p.TimeseriesPanel.Targets, *t)
+	case GaugeType:
+		p.GaugePanel.Targets = append(p.GaugePanel.Targets, *t)
 	}
-	// TODO check for existing refID
 }
 
 // SetTarget updates a target if target with such refId exists
@@ -930,6 +1088,8 @@ func (p *Panel) SetTarget(t *Target) {
 		setTarget(t, &p.HeatmapPanel.Targets)
 	case TimeseriesType:
 		setTarget(t, &p.TimeseriesPanel.Targets)
+	case GaugeType:
+		setTarget(t, &p.GaugePanel.Targets)
 	}
 }
 
@@ -944,7 +1104,7 @@ func (p *Panel) RepeatDatasourcesForEachTarget(dsNames ...string) {
 			for _, ds := range dsNames {
 				newTarget := target
 				newTarget.RefID = refID
-				newTarget.Datasource = ds
+				newTarget.Datasource = NewDatasourceRefByName(ds)
 				refID = incRefID(refID)
 				*targets = append(*targets, newTarget)
 			}
@@ -963,6 +1123,8 @@ func (p *Panel) RepeatDatasourcesForEachTarget(dsNames ...string) {
 		repeatDS(dsNames, &p.HeatmapPanel.Targets)
 	case TimeseriesType:
 		repeatDS(dsNames, &p.TimeseriesPanel.Targets)
+	case GaugeType:
+		repeatDS(dsNames, &p.GaugePanel.Targets)
 	}
 }
 
@@ -971,17 +1133,14 @@ func (p *Panel) RepeatDatasourcesForEachTarget(dsNames ...string) {
 // targets are ignored.
 func (p *Panel) RepeatTargetsForDatasources(dsNames ...string) {
 	repeatTarget := func(dsNames []string, targets *[]Target) {
-		var lastRefID string
 		lenTargets := len(*targets)
 		for i, name := range dsNames {
 			if i < lenTargets {
-				(*targets)[i].Datasource = name
-				lastRefID = (*targets)[i].RefID
+				(*targets)[i].Datasource = NewDatasourceRefByName(name)
 			} else {
 				newTarget := (*targets)[i%lenTargets]
-				lastRefID = incRefID(lastRefID)
-				newTarget.RefID = lastRefID
-				newTarget.Datasource = name
+				newTarget.RefID = nextRefID(*targets)
+				newTarget.Datasource = NewDatasourceRefByName(name)
 				*targets = append(*targets, newTarget)
 			}
 		}
@@ -999,6 +1158,8 @@ func (p *Panel) RepeatTargetsForDatasources(dsNames ...string) {
 		repeatTarget(dsNames, &p.HeatmapPanel.Targets)
 	case TimeseriesType:
 		repeatTarget(dsNames, &p.TimeseriesPanel.Targets)
+	case GaugeType:
+		repeatTarget(dsNames, &p.GaugePanel.Targets)
 	}
 }
 
@@ -1020,6 +1181,8 @@ func (p *Panel) GetTargets() *[]Target {
 		return &p.HeatmapPanel.Targets
 	case TimeseriesType:
 		return &p.TimeseriesPanel.Targets
+	case GaugeType:
+		return &p.GaugePanel.Targets
 	default:
 		return nil
 	}
@@ -1044,7 +1207,7 @@ func (p *Panel) UnmarshalJSON(b []byte) (err error) {
 		if err = json.Unmarshal(b, &graph); err == nil {
 			p.GraphPanel = &graph
 		}
-	case "table":
+	case "table", "table-old":
 		var table TablePanel
 		p.OfType = TableType
 		if err = json.Unmarshal(b, &table); err == nil {
@@ -1092,6 +1255,12 @@ func (p *Panel) UnmarshalJSON(b []byte) (err error) {
 		if err = json.Unmarshal(b, &timeseries); err == nil {
 			p.TimeseriesPanel = &timeseries
 		}
+	case "gauge":
+		var gauge GaugePanel
+		p.OfType = GaugeType
+		if err = json.Unmarshal(b, &gauge); err == nil {
+			p.GaugePanel = &gauge
+		}
 	case "row":
 		var rowpanel RowPanel
 		p.OfType = RowType
@@ -1109,6 +1278,10 @@ func (p *Panel) UnmarshalJSON(b []byte) (err error) {
 	if err != nil && (probe.Title != "" || probe.Type != "") {
 		err = fmt.Errorf("%w (panel %q of type %q)", err, probe.Title, probe.Type)
 	}
+	if err == nil {
+		p.source = keepSource(b)
+		p.sourceType = probe.Type
+	}
 
 	return err
 }
@@ -1120,83 +1293,112 @@ func (p *Panel) MarshalJSON() ([]byte, error) {
 			CommonPanel
 			GraphPanel
 		}{p.CommonPanel, *p.GraphPanel}
-		return json.Marshal(outGraph)
+		return p.marshalWithSource(outGraph)
 	case TableType:
+		if !p.IsLegacyTable() {
+			var outTable = struct {
+				CommonPanel
+				grafana7TablePanel
+			}{p.CommonPanel, newGrafana7TablePanel(*p.TablePanel)}
+			return p.marshalWithSource(outTable)
+		}
 		var outTable = struct {
 			CommonPanel
 			TablePanel
 		}{p.CommonPanel, *p.TablePanel}
-		return json.Marshal(outTable)
+		return p.marshalWithSource(outTable)
 	case TextType:
 		var outText = struct {
 			CommonPanel
 			TextPanel
 		}{p.CommonPanel, *p.TextPanel}
-		return json.Marshal(outText)
+		return p.marshalWithSource(outText)
 	case SinglestatType:
 		var outSinglestat = struct {
 			CommonPanel
 			SinglestatPanel
 		}{p.CommonPanel, *p.SinglestatPanel}
-		return json.Marshal(outSinglestat)
+		return p.marshalWithSource(outSinglestat)
 	case StatType:
+		if p.StatPanel.FieldConfig != nil {
+			var outStat = struct {
+				CommonPanel
+				fieldConfigStatPanel
+			}{p.CommonPanel, newFieldConfigStatPanel(*p.StatPanel)}
+			return p.marshalWithSource(outStat)
+		}
 		var outSinglestat = struct {
 			CommonPanel
 			StatPanel
 		}{p.CommonPanel, *p.StatPanel}
-		return json.Marshal(outSinglestat)
+		return p.marshalWithSource(outSinglestat)
 	case DashlistType:
 		var outDashlist = struct {
 			CommonPanel
 			DashlistPanel
 		}{p.CommonPanel, *p.DashlistPanel}
-		return json.Marshal(outDashlist)
+		return p.marshalWithSource(outDashlist)
 	case BarGaugeType:
 		var outBarGauge = struct {
 			CommonPanel
 			BarGaugePanel
 		}{p.CommonPanel, *p.BarGaugePanel}
-		return json.Marshal(outBarGauge)
+		return p.marshalWithSource(outBarGauge)
 	case PluginlistType:
 		var outPluginlist = struct {
 			CommonPanel
 			PluginlistPanel
 		}{p.CommonPanel, *p.PluginlistPanel}
-		return json.Marshal(outPluginlist)
+		return p.marshalWithSource(outPluginlist)
 	case AlertlistType:
 		var outAlertlist = struct {
 			CommonPanel
 			AlertlistPanel
 		}{p.CommonPanel, *p.AlertlistPanel}
-		return json.Marshal(outAlertlist)
+		return p.marshalWithSource(outAlertlist)
 	case RowType:
 		var outRow = struct {
 			CommonPanel
 			RowPanel
 		}{p.CommonPanel, *p.RowPanel}
-		return json.Marshal(outRow)
+		return p.marshalWithSource(outRow)
 	case HeatmapType:
 		var outHeatmap = struct {
 			CommonPanel
 			HeatmapPanel
 		}{p.CommonPanel, *p.HeatmapPanel}
-		return json.Marshal(outHeatmap)
+		return p.marshalWithSource(outHeatmap)
 	case TimeseriesType:
 		var outTimeseries = struct {
 			CommonPanel
 			TimeseriesPanel
 		}{p.CommonPanel, *p.TimeseriesPanel}
-		return json.Marshal(outTimeseries)
+		return p.marshalWithSource(outTimeseries)
+	case GaugeType:
+		var outGauge = struct {
+			CommonPanel
+			GaugePanel
+		}{p.CommonPanel, *p.GaugePanel}
+		return p.marshalWithSource(outGauge)
 	case CustomType:
 		var outCustom = customPanelOutput{
 			p.CommonPanel,
 			*p.CustomPanel,
 		}
-		return json.Marshal(outCustom)
+		return p.marshalWithSource(outCustom)
 	}
 	return nil, errors.New("can't marshal unknown panel type")
 }
 
+// marshalWithSource merges the panel with its source JSON unless
+// the panel type was changed after decoding.
+func (p *Panel) marshalWithSource(v interface{}) ([]byte, error) {
+	if p.Type != p.sourceType {
+		return json.Marshal(v)
+	}
+	return marshalLossless(v, p.source)
+}
+
 type customPanelOutput struct {
 	CommonPanel
 	CustomPanel
@@ -1207,12 +1409,21 @@ func (c customPanelOutput) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return b, err
 	}
+	// Keys of CommonPanel take precedence over the same keys kept in
+	// CustomPanel so changes of typed fields are not lost.
+	var common map[string]json.RawMessage
+	if err = json.Unmarshal(b, &common); err != nil {
+		return nil, err
+	}
 	// Append custom keys to marshalled CommonPanel.
 	buf := bytes.NewBuffer(b[:len(b)-1])
 
 	// Sort keys to make output idempotent
 	keys := make([]string, 0, len(c.CustomPanel))
 	for k := range c.CustomPanel {
+		if _, ok := common[k]; ok {
+			continue
+		}
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
@@ -1230,10 +1441,3 @@ func (c customPanelOutput) MarshalJSON() ([]byte, error) {
 	buf.WriteString("}")
 	return buf.Bytes(), nil
 }
-
-func incRefID(refID string) string {
-	firstLetter := refID[0]
-	ordinal := int(firstLetter)
-	ordinal++
-	return string(rune(ordinal))
-}
@@ -291,7 +291,7 @@ func TestNewTimeseries(t *testing.T) {
 func TestGraph_AddTarget(t *testing.T) {
 	var target = sdk.Target{
 		RefID:      "A",
-		Datasource: "Sample Source",
+		Datasource: sdk.NewDatasourceRefByName("Sample Source"),
 		Expr:       "sample request"}
 	graph := sdk.NewGraph("")
 
@@ -309,11 +309,11 @@ func TestGraph_SetTargetNew(t *testing.T) {
 	var (
 		target1 = sdk.Target{
 			RefID:      "A",
-			Datasource: "Sample Source 1",
+			Datasource: sdk.NewDatasourceRefByName("Sample Source 1"),
 			Expr:       "sample request 1"}
 		target2 = sdk.Target{
 			RefID:      "B",
-			Datasource: "Sample Source 2",
+			Datasource: sdk.NewDatasourceRefByName("Sample Source 2"),
 			Expr:       "sample request 2"}
 	)
 	graph := sdk.NewGraph("")
@@ -336,11 +336,11 @@ func TestGraph_SetTargetUpdate(t *testing.T) {
 	var (
 		target1 = sdk.Target{
 			RefID:      "A",
-			Datasource: "Sample Source 1",
+			Datasource: sdk.NewDatasourceRefByName("Sample Source 1"),
 			Expr:       "sample request 1"}
 		target2 = sdk.Target{
 			RefID:      "A",
-			Datasource: "Sample Source 2",
+			Datasource: sdk.NewDatasourceRefByName("Sample Source 2"),
 			Expr:       "sample request 2"}
 	)
 	graph := sdk.NewGraph("")
@@ -0,0 +1,72 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+// Types of playlist items.
+const (
+	PlaylistItemDashboardByUID = "dashboard_by_uid"
+	PlaylistItemDashboardByTag = "dashboard_by_tag"
+	// Deprecated: since Grafana 9 dashboards are referred by UID.
+	PlaylistItemDashboardByID = "dashboard_by_id"
+)
+
+// Playlist cycles through the dashboards of its items switching them
+// each Interval (e.g. "5m"). Items are not returned by the playlist
+// listing, use Client.GetPlaylist() or Client.GetPlaylistItems().
+// https://grafana.com/docs/grafana/latest/developers/http_api/playlist/
+type Playlist struct {
+	ID       uint           `json:"id,omitempty"`
+	UID      string         `json:"uid,omitempty"`
+	Name     string         `json:"name"`
+	Interval string         `json:"interval"`
+	Items    []PlaylistItem `json:"items,omitempty"`
+}
+
+// PlaylistItem is a dashboard of the playlist referred by UID or all
+// dashboards with the tag, the Type selects which one Value keeps.
+type PlaylistItem struct {
+	ID         uint   `json:"id,omitempty"`
+	PlaylistID uint   `json:"playlistId,omitempty"`
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Order      int    `json:"order"`
+	Title      string `json:"title,omitempty"`
+}
+
+// NewPlaylist creates a playlist without items.
+func NewPlaylist(name, interval string) *Playlist {
+	return &Playlist{Name: name, Interval: interval}
+}
+
+// AddDashboard adds the dashboard with the UID to the end of the playlist.
+func (p *Playlist) AddDashboard(uid string) *Playlist {
+	return p.addItem(PlaylistItemDashboardByUID, uid)
+}
+
+// AddDashboardsByTag adds all dashboards with the tag to the end of the
+// playlist.
+func (p *Playlist) AddDashboardsByTag(tag string) *Playlist {
+	return p.addItem(PlaylistItemDashboardByTag, tag)
+}
+
+func (p *Playlist) addItem(itemType, value string) *Playlist {
+	p.Items = append(p.Items, PlaylistItem{Type: itemType, Value: value, Order: len(p.Items) + 1})
+	return p
+}
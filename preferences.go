@@ -19,8 +19,24 @@ package sdk
    ॐ तारे तुत्तारे तुरे स्व
 */
 
+// Themes of Grafana UI, empty theme means the default one.
+const (
+	ThemeDark   = "dark"
+	ThemeLight  = "light"
+	ThemeSystem = "system"
+)
+
+// Preferences are preferences of the user, team or organization. Users
+// inherit preferences of their teams and teams inherit preferences of the
+// organization, empty values mean inherited ones. Since Grafana 9 the
+// home dashboard is set by HomeDashboardUID, HomeDashboardId is kept for
+// older versions. WeekStart is "saturday", "sunday", "monday" or empty
+// for the browser default.
 type Preferences struct {
-	Theme           string `json:"theme,omitempty"`
-	HomeDashboardId uint   `json:"homeDashboardId,omitempty"`
-	Timezone        string `json:"timezone,omitempty"`
+	Theme            string `json:"theme,omitempty"`
+	HomeDashboardId  uint   `json:"homeDashboardId,omitempty"`
+	HomeDashboardUID string `json:"homeDashboardUID,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+	WeekStart        string `json:"weekStart,omitempty"`
+	Language         string `json:"language,omitempty"`
 }
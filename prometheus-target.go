@@ -0,0 +1,185 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Formats of Prometheus query results. Table format returns a frame per
+// query with labels in columns and is usually combined with instant
+// queries. Heatmap format treats series as histogram buckets sorted by
+// the "le" label.
+const (
+	PrometheusFormatTimeSeries = "time_series"
+	PrometheusFormatTable      = "table"
+	PrometheusFormatHeatmap    = "heatmap"
+)
+
+// Modes of the query editor of Prometheus and Loki queries.
+const (
+	EditorModeCode    = "code"
+	EditorModeBuilder = "builder"
+)
+
+// PromQLParser checks syntax of the PromQL expression. The SDK does not
+// depend on Prometheus so the parser is optional, for example:
+//
+//	func(expr string) error {
+//		_, err := parser.ParseExpr(expr)
+//		return err
+//	}
+type PromQLParser func(expr string) error
+
+// PrometheusTargetBuilder builds a Prometheus target with chained calls:
+//
+//	target, err := NewPrometheusTarget(`rate(http_requests_total[$__rate_interval])`).
+//		Legend("{{handler}}").
+//		Exemplar().
+//		Build()
+//
+// Errors are reported by Build() and AddTo().
+type PrometheusTargetBuilder struct {
+	target Target
+	parser PromQLParser
+}
+
+// NewPrometheusTarget starts a range query target with the expression.
+func NewPrometheusTarget(expr string) *PrometheusTargetBuilder {
+	return &PrometheusTargetBuilder{target: Target{Expr: expr, Range: true}}
+}
+
+// RefID sets the RefID of the target. Without it the target gets the
+// first free RefID of the panel on AddTo().
+func (b *PrometheusTargetBuilder) RefID(refID string) *PrometheusTargetBuilder {
+	b.target.RefID = refID
+	return b
+}
+
+// Datasource sets the datasource of the target.
+func (b *PrometheusTargetBuilder) Datasource(ds *DatasourceRef) *PrometheusTargetBuilder {
+	b.target.Datasource = ds
+	return b
+}
+
+// Legend sets the legend format, e.g. "{{instance}}".
+func (b *PrometheusTargetBuilder) Legend(format string) *PrometheusTargetBuilder {
+	b.target.LegendFormat = format
+	return b
+}
+
+// Instant makes the target an instant query.
+func (b *PrometheusTargetBuilder) Instant() *PrometheusTargetBuilder {
+	b.target.Instant, b.target.Range = true, false
+	return b
+}
+
+// Range makes the target a range query. It is the default one.
+func (b *PrometheusTargetBuilder) Range() *PrometheusTargetBuilder {
+	b.target.Instant, b.target.Range = false, true
+	return b
+}
+
+// InstantAndRange runs both instant and range queries for the target.
+func (b *PrometheusTargetBuilder) InstantAndRange() *PrometheusTargetBuilder {
+	b.target.Instant, b.target.Range = true, true
+	return b
+}
+
+// Exemplar enables querying exemplars along with the series.
+func (b *PrometheusTargetBuilder) Exemplar() *PrometheusTargetBuilder {
+	b.target.Exemplar = true
+	return b
+}
+
+// Interval sets the minimal step of the query, e.g. "1m".
+func (b *PrometheusTargetBuilder) Interval(interval string) *PrometheusTargetBuilder {
+	b.target.Interval = interval
+	return b
+}
+
+// IntervalFactor sets the resolution of the query as a divider of the
+// step, 1 means a point per pixel.
+func (b *PrometheusTargetBuilder) IntervalFactor(factor int) *PrometheusTargetBuilder {
+	b.target.IntervalFactor = factor
+	return b
+}
+
+// Format sets the result format, see PrometheusFormat* constants. Legend
+// of heatmap targets defaults to "{{le}}" so buckets are named by their
+// upper bounds.
+func (b *PrometheusTargetBuilder) Format(format string) *PrometheusTargetBuilder {
+	b.target.Format = format
+	return b
+}
+
+// EditorMode sets the mode the query editor opens the target in.
+func (b *PrometheusTargetBuilder) EditorMode(mode string) *PrometheusTargetBuilder {
+	b.target.EditorMode = mode
+	return b
+}
+
+// Hide hides results of the target in the panel. Hidden targets are still
+// evaluated when expressions of other targets refer to them, e.g. "$A * 100".
+func (b *PrometheusTargetBuilder) Hide() *PrometheusTargetBuilder {
+	b.target.Hide = true
+	return b
+}
+
+// Validate sets the parser the expression is checked with on Build().
+func (b *PrometheusTargetBuilder) Validate(parser PromQLParser) *PrometheusTargetBuilder {
+	b.parser = parser
+	return b
+}
+
+// Build returns the target. It fails on the empty expression, unknown
+// format and on the expression rejected by the parser set by Validate().
+func (b *PrometheusTargetBuilder) Build() (*Target, error) {
+	t := b.target
+	if t.Expr == "" {
+		return nil, errors.New("prometheus expression is empty")
+	}
+	switch t.Format {
+	case "", PrometheusFormatTimeSeries, PrometheusFormatTable:
+	case PrometheusFormatHeatmap:
+		if t.LegendFormat == "" {
+			t.LegendFormat = "{{le}}"
+		}
+	default:
+		return nil, fmt.Errorf("unknown prometheus format %q", t.Format)
+	}
+	if b.parser != nil {
+		if err := b.parser(t.Expr); err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", t.Expr, err)
+		}
+	}
+	return &t, nil
+}
+
+// AddTo builds the target and adds it to the panel with Panel.AddTarget().
+func (b *PrometheusTargetBuilder) AddTo(p *Panel) (*Target, error) {
+	t, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	p.AddTarget(t)
+	return t, nil
+}
@@ -0,0 +1,88 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestPrometheusTargetBuilder(t *testing.T) {
+	p := sdk.NewTimeseries("Requests")
+	p.AddTarget(&sdk.Target{RefID: "A", Expr: "up"})
+	target, err := sdk.NewPrometheusTarget(`rate(http_requests_total[$__rate_interval])`).
+		Datasource(sdk.NewDatasourceRef("prometheus", "prom1")).
+		Legend("{{handler}}").
+		Exemplar().
+		EditorMode(sdk.EditorModeCode).
+		AddTo(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.RefID != "B" {
+		t.Errorf("expected next free RefID B, got %q", target.RefID)
+	}
+	targets := *p.GetTargets()
+	if len(targets) != 2 || targets[1].Expr != target.Expr {
+		t.Fatalf("target is not added: %+v", targets)
+	}
+	raw, err := json.Marshal(targets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"range":true`, `"exemplar":true`, `"editorMode":"code"`, `"legendFormat":"{{handler}}"`} {
+		if !strings.Contains(string(raw), key) {
+			t.Errorf("expected %s in %s", key, raw)
+		}
+	}
+}
+
+func TestPrometheusTargetBuilder_Modes(t *testing.T) {
+	target, err := sdk.NewPrometheusTarget("sum(up)").Instant().Format(sdk.PrometheusFormatTable).Hide().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !target.Instant || target.Range || !target.Hide || target.Format != "table" {
+		t.Errorf("unexpected instant target %+v", target)
+	}
+	target, err = sdk.NewPrometheusTarget("sum by (le) (rate(latency_bucket[5m]))").Format(sdk.PrometheusFormatHeatmap).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.LegendFormat != "{{le}}" {
+		t.Errorf("expected heatmap legend, got %q", target.LegendFormat)
+	}
+	target, _ = sdk.NewPrometheusTarget("up").InstantAndRange().Build()
+	if !target.Instant || !target.Range {
+		t.Errorf("expected both instant and range, got %+v", target)
+	}
+}
+
+func TestPrometheusTargetBuilder_Errors(t *testing.T) {
+	if _, err := sdk.NewPrometheusTarget("").Build(); err == nil {
+		t.Error("expected error for empty expression")
+	}
+	if _, err := sdk.NewPrometheusTarget("up").Format("csv").Build(); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	errSyntax := errors.New("unclosed parenthesis")
+	parser := func(expr string) error {
+		if strings.Count(expr, "(") != strings.Count(expr, ")") {
+			return errSyntax
+		}
+		return nil
+	}
+	p := sdk.NewTimeseries("Broken")
+	_, err := sdk.NewPrometheusTarget("sum(up").Validate(parser).AddTo(p)
+	if !errors.Is(err, errSyntax) {
+		t.Errorf("expected parser error, got %v", err)
+	}
+	if len(*p.GetTargets()) != 0 {
+		t.Error("invalid target should not be added")
+	}
+	if _, err = sdk.NewPrometheusTarget("sum(up)").Validate(parser).Build(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
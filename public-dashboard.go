@@ -0,0 +1,64 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import "time"
+
+// Modes of sharing public dashboards.
+const (
+	PublicDashboardSharePublic = "public"
+	PublicDashboardShareEmail  = "email"
+)
+
+// PublicDashboard is the configuration of the dashboard available without
+// login by its AccessToken, see Client.PublicDashboardURL(). It is
+// supported by Grafana 10 and later.
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard_public/
+type PublicDashboard struct {
+	UID                  string    `json:"uid,omitempty"`
+	DashboardUID         string    `json:"dashboardUid,omitempty"`
+	AccessToken          string    `json:"accessToken,omitempty"`
+	IsEnabled            bool      `json:"isEnabled"`
+	TimeSelectionEnabled bool      `json:"timeSelectionEnabled"`
+	AnnotationsEnabled   bool      `json:"annotationsEnabled"`
+	Share                string    `json:"share,omitempty"`
+	CreatedBy            int64     `json:"createdBy,omitempty"`
+	UpdatedBy            int64     `json:"updatedBy,omitempty"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// PublicDashboardListItem is an item of the public dashboards listing.
+type PublicDashboardListItem struct {
+	UID          string `json:"uid"`
+	AccessToken  string `json:"accessToken"`
+	Title        string `json:"title"`
+	DashboardUID string `json:"dashboardUid"`
+	Slug         string `json:"slug"`
+	IsEnabled    bool   `json:"isEnabled"`
+}
+
+// PublicDashboardList is a page of the public dashboards listing.
+type PublicDashboardList struct {
+	PublicDashboards []PublicDashboardListItem `json:"publicDashboards"`
+	TotalCount       int                       `json:"totalCount"`
+	Page             int                       `json:"page"`
+	PerPage          int                       `json:"perPage"`
+}
@@ -0,0 +1,432 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+)
+
+// Target combines the fields of all datasources in one structure. The
+// structures below describe targets of the particular datasources, they
+// are converted from and to the generic Target with As*() and ToTarget()
+// methods. The conversion goes through JSON of the target so keys unknown
+// to either structure are kept as they were.
+
+// PrometheusTarget is a query of the Prometheus datasource.
+type PrometheusTarget struct {
+	RefID          string         `json:"refId"`
+	Datasource     *DatasourceRef `json:"datasource,omitempty"`
+	Hide           bool           `json:"hide,omitempty"`
+	Expr           string         `json:"expr"`
+	LegendFormat   string         `json:"legendFormat,omitempty"`
+	Interval       string         `json:"interval,omitempty"`
+	IntervalFactor int            `json:"intervalFactor,omitempty"`
+	Step           int            `json:"step,omitempty"`
+	Instant        bool           `json:"instant,omitempty"`
+	Range          bool           `json:"range,omitempty"`
+	Exemplar       bool           `json:"exemplar,omitempty"`
+	Format         string         `json:"format,omitempty"`     // time_series, table or heatmap
+	EditorMode     string         `json:"editorMode,omitempty"` // code or builder
+
+	keptSource
+}
+
+// LokiTarget is a query of the Loki datasource.
+type LokiTarget struct {
+	RefID        string         `json:"refId"`
+	Datasource   *DatasourceRef `json:"datasource,omitempty"`
+	Hide         bool           `json:"hide,omitempty"`
+	Expr         string         `json:"expr"`
+	LegendFormat string         `json:"legendFormat,omitempty"`
+	QueryType    string         `json:"queryType,omitempty"` // range or instant
+	Instant      bool           `json:"instant,omitempty"`
+	Range        bool           `json:"range,omitempty"`
+	MaxLines     *int           `json:"maxLines,omitempty"`
+	Resolution   int            `json:"resolution,omitempty"`
+	Step         string         `json:"step,omitempty"`
+	EditorMode   string         `json:"editorMode,omitempty"`
+
+	keptSource
+}
+
+// ElasticsearchTarget is a query of the Elasticsearch datasource.
+type ElasticsearchTarget struct {
+	RefID      string                   `json:"refId"`
+	Datasource *DatasourceRef           `json:"datasource,omitempty"`
+	Hide       bool                     `json:"hide,omitempty"`
+	Query      string                   `json:"query"`
+	Alias      string                   `json:"alias,omitempty"`
+	TimeField  string                   `json:"timeField,omitempty"`
+	Metrics    []ElasticsearchMetric    `json:"metrics,omitempty"`
+	BucketAggs []ElasticsearchBucketAgg `json:"bucketAggs,omitempty"`
+
+	keptSource
+}
+
+// ElasticsearchMetric is a metric aggregation of the Elasticsearch query,
+// settings depend on the aggregation type.
+type ElasticsearchMetric struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Field       string                 `json:"field,omitempty"`
+	Hide        bool                   `json:"hide,omitempty"`
+	PipelineAgg string                 `json:"pipelineAgg,omitempty"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+	Meta        map[string]interface{} `json:"meta,omitempty"`
+}
+
+// ElasticsearchBucketAgg is a bucket aggregation of the Elasticsearch
+// query, settings depend on the aggregation type.
+type ElasticsearchBucketAgg struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Field    string                 `json:"field,omitempty"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// SQLTarget is a query of the SQL datasources: PostgreSQL, MySQL and
+// Microsoft SQL Server. Select, Where and Group describe the query built
+// with the visual editor, RawSql is used when RawQuery is set.
+type SQLTarget struct {
+	RefID        string         `json:"refId"`
+	Datasource   *DatasourceRef `json:"datasource,omitempty"`
+	Hide         bool           `json:"hide,omitempty"`
+	Format       string         `json:"format,omitempty"` // time_series or table
+	RawQuery     bool           `json:"rawQuery,omitempty"`
+	RawSql       string         `json:"rawSql,omitempty"`
+	EditorMode   string         `json:"editorMode,omitempty"`
+	Table        string         `json:"table,omitempty"`
+	TimeColumn   string         `json:"timeColumn,omitempty"`
+	MetricColumn string         `json:"metricColumn,omitempty"`
+	Select       [][]SQLPart    `json:"select,omitempty"`
+	Where        []SQLPart      `json:"where,omitempty"`
+	Group        []SQLPart      `json:"group,omitempty"`
+
+	keptSource
+}
+
+// SQLPart is a part of the query built with the visual SQL editor.
+type SQLPart struct {
+	Type     string   `json:"type,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Params   []string `json:"params,omitempty"`
+	Datatype string   `json:"datatype,omitempty"`
+}
+
+// InfluxTarget is an InfluxQL query of the InfluxDB datasource.
+type InfluxTarget struct {
+	RefID        string              `json:"refId"`
+	Datasource   *DatasourceRef      `json:"datasource,omitempty"`
+	Hide         bool                `json:"hide,omitempty"`
+	Query        string              `json:"query,omitempty"`
+	RawQuery     bool                `json:"rawQuery,omitempty"`
+	Alias        string              `json:"alias,omitempty"`
+	Policy       string              `json:"policy,omitempty"`
+	Measurement  string              `json:"measurement,omitempty"`
+	ResultFormat string              `json:"resultFormat,omitempty"` // time_series, table or logs
+	OrderByTime  string              `json:"orderByTime,omitempty"`
+	Select       [][]InfluxQueryPart `json:"select,omitempty"`
+	GroupBy      []InfluxQueryPart   `json:"groupBy,omitempty"`
+	Tags         []InfluxTag         `json:"tags,omitempty"`
+
+	keptSource
+}
+
+// InfluxQueryPart is a function of the query built with the visual
+// InfluxQL editor, e.g. {"type": "mean"} or {"type": "time", "params":
+// ["$__interval"]}.
+type InfluxQueryPart struct {
+	Type   string   `json:"type"`
+	Params []string `json:"params,omitempty"`
+}
+
+// InfluxTag is a tag condition of the InfluxQL query, Condition joins it
+// with the previous one ("AND" or "OR").
+type InfluxTag struct {
+	Key       string `json:"key"`
+	Operator  string `json:"operator,omitempty"`
+	Value     string `json:"value"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// CloudWatchTarget is a query of the CloudWatch datasource. Metric
+// queries use Namespace, MetricName and Dimensions or Expression, logs
+// queries use Expression with LogGroupNames.
+type CloudWatchTarget struct {
+	RefID         string                        `json:"refId"`
+	Datasource    *DatasourceRef                `json:"datasource,omitempty"`
+	Hide          bool                          `json:"hide,omitempty"`
+	QueryMode     string                        `json:"queryMode,omitempty"` // Metrics or Logs
+	ID            string                        `json:"id,omitempty"`
+	Region        string                        `json:"region,omitempty"`
+	Namespace     string                        `json:"namespace,omitempty"`
+	MetricName    string                        `json:"metricName,omitempty"`
+	Dimensions    map[string]*StringSliceString `json:"dimensions,omitempty"`
+	MatchExact    *bool                         `json:"matchExact,omitempty"`
+	Statistic     string                        `json:"statistic,omitempty"`
+	Statistics    []string                      `json:"statistics,omitempty"` // before Grafana 8
+	Period        string                        `json:"period,omitempty"`
+	Expression    string                        `json:"expression,omitempty"`
+	SQLExpression string                        `json:"sqlExpression,omitempty"`
+	Label         string                        `json:"label,omitempty"`
+	Alias         string                        `json:"alias,omitempty"`
+	LogGroupNames []string                      `json:"logGroupNames,omitempty"`
+
+	keptSource
+}
+
+// TempoTarget is a query of the Tempo datasource. QueryType selects the
+// kind of the query: "traceql", "traceqlSearch", "search", "serviceMap" etc.
+type TempoTarget struct {
+	RefID       string         `json:"refId"`
+	Datasource  *DatasourceRef `json:"datasource,omitempty"`
+	Hide        bool           `json:"hide,omitempty"`
+	QueryType   string         `json:"queryType,omitempty"`
+	Query       string         `json:"query,omitempty"`
+	Limit       int            `json:"limit,omitempty"`
+	SpanLimit   int            `json:"spss,omitempty"`
+	ServiceName string         `json:"serviceName,omitempty"`
+	SpanName    string         `json:"spanName,omitempty"`
+	Search      string         `json:"search,omitempty"`
+	MinDuration string         `json:"minDuration,omitempty"`
+	MaxDuration string         `json:"maxDuration,omitempty"`
+	TableType   string         `json:"tableType,omitempty"`
+
+	keptSource
+}
+
+// convertTarget converts one target structure to another through JSON.
+func convertTarget(from json.Marshaler, to json.Unmarshaler) error {
+	b, err := from.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return to.UnmarshalJSON(b)
+}
+
+// AsPrometheus converts the target to the Prometheus one.
+func (t Target) AsPrometheus() (*PrometheusTarget, error) {
+	var target PrometheusTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the Prometheus target to the generic one.
+func (t PrometheusTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *PrometheusTarget) UnmarshalJSON(data []byte) error {
+	type plain PrometheusTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t PrometheusTarget) MarshalJSON() ([]byte, error) {
+	type plain PrometheusTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsLoki converts the target to the Loki one.
+func (t Target) AsLoki() (*LokiTarget, error) {
+	var target LokiTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the Loki target to the generic one.
+func (t LokiTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *LokiTarget) UnmarshalJSON(data []byte) error {
+	type plain LokiTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t LokiTarget) MarshalJSON() ([]byte, error) {
+	type plain LokiTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsElasticsearch converts the target to the Elasticsearch one.
+func (t Target) AsElasticsearch() (*ElasticsearchTarget, error) {
+	var target ElasticsearchTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the Elasticsearch target to the generic one.
+func (t ElasticsearchTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *ElasticsearchTarget) UnmarshalJSON(data []byte) error {
+	type plain ElasticsearchTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t ElasticsearchTarget) MarshalJSON() ([]byte, error) {
+	type plain ElasticsearchTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsSQL converts the target to the SQL one.
+func (t Target) AsSQL() (*SQLTarget, error) {
+	var target SQLTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the SQL target to the generic one.
+func (t SQLTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *SQLTarget) UnmarshalJSON(data []byte) error {
+	type plain SQLTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t SQLTarget) MarshalJSON() ([]byte, error) {
+	type plain SQLTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsInflux converts the target to the InfluxDB one.
+func (t Target) AsInflux() (*InfluxTarget, error) {
+	var target InfluxTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the InfluxDB target to the generic one.
+func (t InfluxTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *InfluxTarget) UnmarshalJSON(data []byte) error {
+	type plain InfluxTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t InfluxTarget) MarshalJSON() ([]byte, error) {
+	type plain InfluxTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsCloudWatch converts the target to the CloudWatch one.
+func (t Target) AsCloudWatch() (*CloudWatchTarget, error) {
+	var target CloudWatchTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the CloudWatch target to the generic one.
+func (t CloudWatchTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *CloudWatchTarget) UnmarshalJSON(data []byte) error {
+	type plain CloudWatchTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t CloudWatchTarget) MarshalJSON() ([]byte, error) {
+	type plain CloudWatchTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
+
+// AsTempo converts the target to the Tempo one.
+func (t Target) AsTempo() (*TempoTarget, error) {
+	var target TempoTarget
+	if err := convertTarget(t, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ToTarget converts the Tempo target to the generic one.
+func (t TempoTarget) ToTarget() (Target, error) {
+	var target Target
+	err := convertTarget(t, &target)
+	return target, err
+}
+
+// UnmarshalJSON decodes the target and keeps its source JSON.
+func (t *TempoTarget) UnmarshalJSON(data []byte) error {
+	type plain TempoTarget
+	var err error
+	t.source, t.skipped, err = decodeLossless(data, (*plain)(t))
+	return err
+}
+
+// MarshalJSON marshals the target with keys of its source JSON preserved.
+func (t TempoTarget) MarshalJSON() ([]byte, error) {
+	type plain TempoTarget
+	return encodeLossless(plain(t), t.source, t.skipped)
+}
@@ -0,0 +1,166 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func decodeGeneric(t *testing.T, raw []byte) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestTarget_InfluxGroupByRoundTrip(t *testing.T) {
+	raw := []byte(`{
+		"refId": "A",
+		"datasource": {"type": "influxdb", "uid": "influx1"},
+		"measurement": "cpu",
+		"policy": "default",
+		"resultFormat": "time_series",
+		"groupBy": [{"type": "time", "params": ["$__interval"]}, {"type": "fill", "params": ["null"]}],
+		"select": [[{"type": "field", "params": ["usage_idle"]}, {"type": "mean", "params": []}]],
+		"tags": [{"key": "host", "operator": "=~", "value": "/^$host$/"}]
+	}`)
+	var target sdk.Target
+	if err := json.Unmarshal(raw, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Measurement != "cpu" {
+		t.Errorf("expected measurement decoded, got %q", target.Measurement)
+	}
+	if skipped := target.Skipped(); !reflect.DeepEqual(skipped, []string{"groupBy"}) {
+		t.Errorf("group by objects should be skipped, got %v", skipped)
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := decodeGeneric(t, raw), decodeGeneric(t, b); !reflect.DeepEqual(exp, got) {
+		t.Errorf("target changed on round trip:\nexpected %v\ngot      %v", exp, got)
+	}
+
+	influx, err := target.AsInflux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(influx.Skipped()) != 0 {
+		t.Errorf("nothing should be skipped by the InfluxDB target, got %v", influx.Skipped())
+	}
+	if len(influx.GroupBy) != 2 || influx.GroupBy[0].Type != "time" || influx.GroupBy[0].Params[0] != "$__interval" {
+		t.Errorf("unexpected group by %+v", influx.GroupBy)
+	}
+	if influx.Policy != "default" || influx.ResultFormat != "time_series" {
+		t.Errorf("unexpected influx target %+v", influx)
+	}
+	influx.GroupBy = influx.GroupBy[:1]
+	if target, err = influx.ToTarget(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = json.Marshal(target); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeGeneric(t, b)
+	if groupBy := got["groupBy"].([]interface{}); len(groupBy) != 1 {
+		t.Errorf("expected changed group by, got %v", groupBy)
+	}
+	if got["policy"] != "default" || got["measurement"] != "cpu" {
+		t.Errorf("influx keys lost: %v", got)
+	}
+}
+
+func TestTarget_AsPrometheus(t *testing.T) {
+	raw := []byte(`{"refId": "A", "expr": "up", "exemplar": true, "range": true, "editorMode": "code", "futureOption": 1}`)
+	var target sdk.Target
+	if err := json.Unmarshal(raw, &target); err != nil {
+		t.Fatal(err)
+	}
+	prom, err := target.AsPrometheus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prom.Expr != "up" || !prom.Exemplar || !prom.Range || prom.EditorMode != "code" {
+		t.Errorf("unexpected prometheus target %+v", prom)
+	}
+	prom.Expr = "rate(up[5m])"
+	prom.Instant = true
+	prom.Range = false
+	if target, err = prom.ToTarget(); err != nil {
+		t.Fatal(err)
+	}
+	if target.Expr != "rate(up[5m])" || !target.Instant {
+		t.Errorf("fields are not converted: %+v", target)
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeGeneric(t, b)
+	if _, ok := got["range"]; ok {
+		t.Error("expected cleared range to be omitted")
+	}
+	if got["futureOption"] != float64(1) || got["exemplar"] != true {
+		t.Errorf("unknown keys lost: %v", got)
+	}
+}
+
+func TestTarget_TypedCollisions(t *testing.T) {
+	raw := []byte(`{"refId": "A", "expr": "{job=\"app\"}", "queryType": "range", "step": "30s", "maxLines": 500}`)
+	var target sdk.Target
+	if err := json.Unmarshal(raw, &target); err != nil {
+		t.Fatal(err)
+	}
+	loki, err := target.AsLoki()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loki.Step != "30s" || loki.MaxLines == nil || *loki.MaxLines != 500 || loki.QueryType != "range" {
+		t.Errorf("unexpected loki target %+v", loki)
+	}
+
+	raw = []byte(`{"refId": "B", "namespace": "AWS/EC2", "metricName": "CPUUtilization", "dimensions": {"InstanceId": ["i-1", "i-2"]}, "statistic": "Average"}`)
+	if err = json.Unmarshal(raw, &target); err != nil {
+		t.Fatal(err)
+	}
+	cw, err := target.AsCloudWatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dim := cw.Dimensions["InstanceId"]; dim == nil || len(dim.Value) != 2 {
+		t.Errorf("unexpected dimensions %+v", cw.Dimensions)
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := decodeGeneric(t, raw), decodeGeneric(t, b); !reflect.DeepEqual(exp, got) {
+		t.Errorf("target changed on round trip:\nexpected %v\ngot      %v", exp, got)
+	}
+}
+
+func TestSQLTarget_ToTarget(t *testing.T) {
+	sql := sdk.SQLTarget{
+		RefID:      "A",
+		Datasource: sdk.NewDatasourceRef("postgres", "pg1"),
+		Format:     "table",
+		RawQuery:   true,
+		RawSql:     "SELECT 1",
+		Where:      []sdk.SQLPart{{Type: "macro", Name: "$__timeFilter", Params: []string{}}},
+	}
+	target, err := sql.ToTarget()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.RawSql != "SELECT 1" || target.Format != "table" || !target.RawQuery || len(target.Where) != 1 {
+		t.Errorf("unexpected target %+v", target)
+	}
+	if target.Datasource.UID != "pg1" {
+		t.Errorf("unexpected datasource %+v", target.Datasource)
+	}
+}
@@ -0,0 +1,199 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// ExpressionDatasourceUID is the UID of the datasource of server side
+// expressions. Expression targets refer to other targets of the panel by
+// their RefIDs: math expressions as "$A" or "${A}", other types (reduce,
+// resample, threshold) have the RefID as the whole expression and classic
+// conditions have it as the first parameter of queries of the conditions.
+const ExpressionDatasourceUID = "__expr__"
+
+// Types of server side expressions.
+const (
+	ExpressionMath      = "math"
+	ExpressionReduce    = "reduce"
+	ExpressionResample  = "resample"
+	ExpressionThreshold = "threshold"
+	// Conditions of classic conditions are not decoded by Target, they
+	// are kept in its source JSON.
+	ExpressionClassicConditions = "classic_conditions"
+)
+
+// mathRefRe matches references to targets in math expressions.
+var mathRefRe = regexp.MustCompile(`\$(?:\{([^}]+)\}|([A-Za-z0-9_]+))`)
+
+// IsExpression reports whether the target is a server side expression.
+func (t *Target) IsExpression() bool {
+	return t.Datasource != nil && (t.Datasource.UID == ExpressionDatasourceUID || t.Datasource.Type == ExpressionDatasourceUID)
+}
+
+// NormalizeRefIDs renames targets of the panel to A, B, C... in their
+// order and updates references of expressions to the renamed targets.
+// It returns old RefIDs mapped to new ones, unchanged ones are omitted.
+func (p *Panel) NormalizeRefIDs() map[string]string {
+	targets := p.GetTargets()
+	if targets == nil {
+		return nil
+	}
+	var (
+		renamed = make(map[string]string)
+		seen    = make(map[string]bool)
+		refID   = "A"
+	)
+	for i := range *targets {
+		t := &(*targets)[i]
+		// Expressions refer to the first target of duplicated RefIDs.
+		if !seen[t.RefID] && t.RefID != refID {
+			renamed[t.RefID] = refID
+		}
+		seen[t.RefID] = true
+		t.RefID = refID
+		refID = incRefID(refID)
+	}
+	if len(renamed) == 0 {
+		return renamed
+	}
+	for i := range *targets {
+		t := &(*targets)[i]
+		if !t.IsExpression() {
+			continue
+		}
+		if t.Type == ExpressionClassicConditions {
+			t.source = renameConditionRefs(t.source, renamed)
+			continue
+		}
+		t.Expression = renameRefs(t.Type, t.Expression, renamed)
+	}
+	return renamed
+}
+
+// renameRefs replaces RefIDs in the expression at once so swapped
+// RefIDs are not renamed twice.
+func renameRefs(exprType, expr string, renamed map[string]string) string {
+	if exprType != ExpressionMath {
+		if newID, ok := renamed[expr]; ok {
+			return newID
+		}
+		return expr
+	}
+	return mathRefRe.ReplaceAllStringFunc(expr, func(ref string) string {
+		m := mathRefRe.FindStringSubmatch(ref)
+		if newID, ok := renamed[m[1]]; ok {
+			return "${" + newID + "}"
+		}
+		if newID, ok := renamed[m[2]]; ok {
+			return "$" + newID
+		}
+		return ref
+	})
+}
+
+// renameConditionRefs replaces RefIDs in queries of classic conditions
+// of the target source JSON. The source is returned as is when it has no
+// conditions in the expected form.
+func renameConditionRefs(source json.RawMessage, renamed map[string]string) json.RawMessage {
+	var (
+		target     map[string]json.RawMessage
+		conditions []map[string]json.RawMessage
+	)
+	if json.Unmarshal(source, &target) != nil || json.Unmarshal(target["conditions"], &conditions) != nil {
+		return source
+	}
+	changed := false
+	for _, cond := range conditions {
+		var query map[string]json.RawMessage
+		if json.Unmarshal(cond["query"], &query) != nil {
+			continue
+		}
+		var params []json.RawMessage
+		if json.Unmarshal(query["params"], &params) != nil || len(params) == 0 {
+			continue
+		}
+		var refID string
+		if json.Unmarshal(params[0], &refID) != nil {
+			continue
+		}
+		newID, ok := renamed[refID]
+		if !ok {
+			continue
+		}
+		params[0], _ = json.Marshal(newID)
+		query["params"], _ = json.Marshal(params)
+		cond["query"], _ = json.Marshal(query)
+		changed = true
+	}
+	if !changed {
+		return source
+	}
+	target["conditions"], _ = json.Marshal(conditions)
+	b, err := json.Marshal(target)
+	if err != nil {
+		return source
+	}
+	return b
+}
+
+// nextRefID returns the first RefID in A..Z, AA..AZ... sequence that is
+// not used by the targets.
+func nextRefID(targets []Target) string {
+	refID := "A"
+	for hasRefID(targets, refID) {
+		refID = incRefID(refID)
+	}
+	return refID
+}
+
+func hasRefID(targets []Target, refID string) bool {
+	for _, t := range targets {
+		if t.RefID == refID {
+			return true
+		}
+	}
+	return false
+}
+
+// incRefID returns the RefID following the argument in the spreadsheet
+// like sequence: "A" to "B", "Z" to "AA", "AZ" to "BA", "ZZ" to "AAA".
+// RefIDs other than upper case letters restart the sequence from "A".
+func incRefID(refID string) string {
+	if refID == "" {
+		return "A"
+	}
+	b := []byte(refID)
+	for _, c := range b {
+		if c < 'A' || c > 'Z' {
+			return "A"
+		}
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 'Z' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = 'A'
+	}
+	return "A" + string(b)
+}
@@ -0,0 +1,138 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestPanel_RepeatDatasourcesForEachTarget_ManyRefIDs(t *testing.T) {
+	p := sdk.NewTimeseries("Many")
+	p.AddTarget(&sdk.Target{Expr: "up"})
+	var names []string
+	for i := 0; i < 55; i++ {
+		names = append(names, fmt.Sprintf("ds%d", i))
+	}
+	p.RepeatDatasourcesForEachTarget(names...)
+	targets := *p.GetTargets()
+	for i, exp := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 54: "BC"} {
+		if targets[i].RefID != exp {
+			t.Errorf("target %d: expected %s, got %s", i, exp, targets[i].RefID)
+		}
+	}
+}
+
+func TestPanel_AddTarget_AvoidsCollisions(t *testing.T) {
+	p := sdk.NewTimeseries("Collisions")
+	p.AddTarget(&sdk.Target{RefID: "B", Expr: "b"})
+	first := sdk.Target{Expr: "a"}
+	p.AddTarget(&first)
+	dup := sdk.Target{RefID: "B", Expr: "dup"}
+	p.AddTarget(&dup)
+	if first.RefID != "A" || dup.RefID != "C" {
+		t.Errorf("expected A and C, got %s and %s", first.RefID, dup.RefID)
+	}
+	var got []string
+	for _, target := range *p.GetTargets() {
+		got = append(got, target.RefID)
+	}
+	if !reflect.DeepEqual(got, []string{"B", "A", "C"}) {
+		t.Errorf("unexpected RefIDs %v", got)
+	}
+}
+
+func TestPanel_RepeatTargetsForDatasources_AvoidsCollisions(t *testing.T) {
+	p := sdk.NewTimeseries("Repeat")
+	p.AddTarget(&sdk.Target{RefID: "C", Expr: "c"})
+	p.AddTarget(&sdk.Target{RefID: "A", Expr: "a"})
+	p.RepeatTargetsForDatasources("one", "two", "three", "four")
+	var got []string
+	for _, target := range *p.GetTargets() {
+		got = append(got, target.RefID)
+	}
+	if !reflect.DeepEqual(got, []string{"C", "A", "B", "D"}) {
+		t.Errorf("unexpected RefIDs %v", got)
+	}
+}
+
+func TestPanel_NormalizeRefIDs(t *testing.T) {
+	expr := sdk.NewDatasourceRef(sdk.ExpressionDatasourceUID, sdk.ExpressionDatasourceUID)
+	p := sdk.NewTimeseries("Expressions")
+	p.AddTarget(&sdk.Target{RefID: "requests", Expr: "sum(rate(requests[5m]))"})
+	p.AddTarget(&sdk.Target{RefID: "errors", Expr: "sum(rate(errors[5m]))"})
+	p.AddTarget(&sdk.Target{RefID: "ratio", Datasource: expr, Type: sdk.ExpressionMath, Expression: "${errors} / $requests * 100"})
+	p.AddTarget(&sdk.Target{RefID: "last", Datasource: expr, Type: sdk.ExpressionReduce, Expression: "ratio"})
+	p.AddTarget(&sdk.Target{RefID: "D", Datasource: expr, Type: sdk.ExpressionMath, Expression: "$A + $D"})
+
+	renamed := p.NormalizeRefIDs()
+	exp := map[string]string{"requests": "A", "errors": "B", "ratio": "C", "last": "D", "D": "E"}
+	if !reflect.DeepEqual(renamed, exp) {
+		t.Errorf("expected %v, got %v", exp, renamed)
+	}
+	targets := *p.GetTargets()
+	if targets[2].Expression != "${B} / $A * 100" {
+		t.Errorf("unexpected math expression %q", targets[2].Expression)
+	}
+	if targets[3].Expression != "C" {
+		t.Errorf("unexpected reduce expression %q", targets[3].Expression)
+	}
+	if targets[4].Expression != "$A + $E" {
+		t.Errorf("unexpected math expression %q", targets[4].Expression)
+	}
+	if renamed = p.NormalizeRefIDs(); len(renamed) != 0 {
+		t.Errorf("expected nothing renamed, got %v", renamed)
+	}
+}
+
+func TestPanel_NormalizeRefIDs_ClassicConditions(t *testing.T) {
+	var p sdk.Panel
+	err := json.Unmarshal([]byte(`{"type": "timeseries", "targets": [
+		{"refId": "requests", "expr": "sum(rate(requests[5m]))"},
+		{"refId": "errors", "expr": "sum(rate(errors[5m]))"},
+		{"refId": "alert", "datasource": {"type": "__expr__", "uid": "__expr__"}, "type": "classic_conditions",
+			"conditions": [
+				{"evaluator": {"params": [5], "type": "gt"}, "operator": {"type": "and"},
+					"query": {"params": ["errors"]}, "reducer": {"type": "last"}, "type": "query"},
+				{"evaluator": {"params": [100], "type": "lt"}, "operator": {"type": "or"},
+					"query": {"params": ["requests"]}, "reducer": {"type": "avg"}, "type": "query"}
+			]}
+	]}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.NormalizeRefIDs()
+
+	b, err := json.Marshal((*p.GetTargets())[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		RefID      string `json:"refId"`
+		Conditions []struct {
+			Evaluator struct {
+				Type string `json:"type"`
+			} `json:"evaluator"`
+			Query struct {
+				Params []string `json:"params"`
+			} `json:"query"`
+		} `json:"conditions"`
+	}
+	if err = json.Unmarshal(b, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.RefID != "C" || len(target.Conditions) != 2 {
+		t.Fatalf("unexpected target %s", b)
+	}
+	if ref := target.Conditions[0].Query.Params[0]; ref != "B" {
+		t.Errorf("condition should refer to B but got %q", ref)
+	}
+	if ref := target.Conditions[1].Query.Params[0]; ref != "A" {
+		t.Errorf("condition should refer to A but got %q", ref)
+	}
+	if target.Conditions[1].Evaluator.Type != "lt" {
+		t.Errorf("other keys of conditions should be kept: %s", b)
+	}
+}
@@ -0,0 +1,116 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ImportDashboardInput is the value of the dashboard input for the import:
+// the UID of the datasource for datasource inputs or the text for constant
+// ones.
+type ImportDashboardInput struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	PluginID string `json:"pluginId,omitempty"`
+	Value    string `json:"value"`
+}
+
+// ImportDashboardParams contains the parameters of the dashboard import.
+// Inputs are required for all inputs of the dashboard exported for sharing.
+type ImportDashboardParams struct {
+	FolderID  int
+	FolderUID string
+	Overwrite bool
+	Inputs    []ImportDashboardInput
+}
+
+// ImportDashboardResult is the reply of Grafana on the dashboard import.
+type ImportDashboardResult struct {
+	UID              string `json:"uid"`
+	PluginID         string `json:"pluginId"`
+	Title            string `json:"title"`
+	Imported         bool   `json:"imported"`
+	ImportedURI      string `json:"importedUri"`
+	ImportedURL      string `json:"importedUrl"`
+	Slug             string `json:"slug"`
+	DashboardID      uint   `json:"dashboardId"`
+	FolderID         int    `json:"folderId"`
+	FolderUID        string `json:"folderUid"`
+	ImportedRevision int64  `json:"importedRevision"`
+	Revision         int64  `json:"revision"`
+	Description      string `json:"description"`
+	Path             string `json:"path"`
+	Removed          bool   `json:"removed"`
+}
+
+// Import returns the value of the input for the dashboard import.
+func (in DashboardInput) Import(value string) ImportDashboardInput {
+	return ImportDashboardInput{Name: in.Name, Type: in.Type, PluginID: in.PluginID, Value: value}
+}
+
+// ImportDashboard imports the dashboard exported for sharing. Contrary to
+// SetDashboard() Grafana replaces "${NAME}" placeholders of the dashboard
+// with values of the inputs, see Board.ExportForSharing(). Use
+// Board.ResolveInputs() to do the same locally.
+//
+// Reflects POST /api/dashboards/import API call.
+func (r *Client) ImportDashboard(ctx context.Context, board Board, params ImportDashboardParams) (ImportDashboardResult, error) {
+	raw, err := json.Marshal(board)
+	if err != nil {
+		return ImportDashboardResult{}, err
+	}
+	return r.ImportRawDashboard(ctx, raw, params)
+}
+
+// ImportRawDashboard imports the dashboard JSON exported for sharing, for
+// example the one downloaded from grafana.com.
+//
+// Reflects POST /api/dashboards/import API call.
+func (r *Client) ImportRawDashboard(ctx context.Context, raw []byte, params ImportDashboardParams) (ImportDashboardResult, error) {
+	var (
+		request = struct {
+			Dashboard json.RawMessage        `json:"dashboard"`
+			Overwrite bool                   `json:"overwrite"`
+			Inputs    []ImportDashboardInput `json:"inputs"`
+			FolderID  int                    `json:"folderId"`
+			FolderUID string                 `json:"folderUid,omitempty"`
+		}{raw, params.Overwrite, params.Inputs, params.FolderID, params.FolderUID}
+		result ImportDashboardResult
+		code   int
+		err    error
+	)
+	if request.Inputs == nil {
+		request.Inputs = []ImportDashboardInput{}
+	}
+	if raw, err = json.Marshal(request); err != nil {
+		return result, err
+	}
+	if raw, code, err = r.post(ctx, "api/dashboards/import", nil, raw); err != nil {
+		return result, err
+	}
+	if code != 200 {
+		return result, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &result)
+	return result, err
+}
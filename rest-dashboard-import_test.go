@@ -0,0 +1,74 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_ImportDashboard(t *testing.T) {
+	var request struct {
+		Dashboard map[string]interface{}     `json:"dashboard"`
+		Overwrite bool                       `json:"overwrite"`
+		Inputs    []sdk.ImportDashboardInput `json:"inputs"`
+		FolderUID string                     `json:"folderUid"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/dashboards/import" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"uid": "shared", "title": "Shared", "imported": true, "importedUrl": "/d/shared/shared", "dashboardId": 7}`))
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+
+	b := sharedBoard()
+	if err := b.ExportForSharing(sharedDatasources); err != nil {
+		t.Fatal(err)
+	}
+	var inputs []sdk.ImportDashboardInput
+	for _, in := range b.Inputs {
+		inputs = append(inputs, in.Import("value"))
+	}
+	result, err := client.ImportDashboard(context.Background(), *b, sdk.ImportDashboardParams{
+		FolderUID: "team",
+		Overwrite: true,
+		Inputs:    inputs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Imported || result.DashboardID != 7 || result.ImportedURL != "/d/shared/shared" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !request.Overwrite || request.FolderUID != "team" || len(request.Inputs) != 3 {
+		t.Errorf("unexpected request %+v", request)
+	}
+	if request.Inputs[0].PluginID != "loki" || request.Inputs[0].Type != sdk.InputTypeDatasource {
+		t.Errorf("unexpected input %+v", request.Inputs[0])
+	}
+	if _, ok := request.Dashboard["__inputs"]; !ok {
+		t.Error("dashboard should be posted with its inputs")
+	}
+}
+
+func TestClient_ImportDashboard_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message": "dashboard input not found"}`))
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	if _, err := client.ImportRawDashboard(context.Background(), []byte(`{}`), sdk.ImportDashboardParams{}); err == nil {
+		t.Error("expected error")
+	}
+}
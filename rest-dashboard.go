@@ -456,6 +456,13 @@ func QueryParamLimit(limit uint) QueryParam {
 	}
 }
 
+// QueryParamQuery sets `query` parameter
+func QueryParamQuery(query string) QueryParam {
+	return func(v *url.Values) {
+		v.Set("query", query)
+	}
+}
+
 // Search entities to be used with SearchType().
 const (
 	SearchTypeFolder    SearchParamType = "dash-folder"
@@ -23,6 +23,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 )
 
 // GetAllDatasources gets all datasources.
@@ -82,6 +83,80 @@ func (r *Client) GetDatasourceByName(ctx context.Context, name string) (Datasour
 	return ds, err
 }
 
+// GetDatasourceByUID gets a datasource by UID. Unlike IDs the UIDs may be
+// the same on different Grafana instances.
+// Reflects GET /api/datasources/uid/:uid API call.
+func (r *Client) GetDatasourceByUID(ctx context.Context, uid string) (Datasource, error) {
+	var (
+		raw  []byte
+		ds   Datasource
+		code int
+		err  error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/datasources/uid/%s", url.PathEscape(uid)), nil); err != nil {
+		return ds, err
+	}
+	if code != 200 {
+		return ds, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &ds)
+	return ds, err
+}
+
+// GetDefaultDatasource gets the default datasource of the organization.
+// It returns an error if no datasource is set as the default one.
+func (r *Client) GetDefaultDatasource(ctx context.Context) (Datasource, error) {
+	datasources, err := r.GetAllDatasources(ctx)
+	if err != nil {
+		return Datasource{}, err
+	}
+	for _, ds := range datasources {
+		if ds.IsDefault {
+			return ds, nil
+		}
+	}
+	return Datasource{}, fmt.Errorf("no default datasource")
+}
+
+// SetDefaultDatasource makes the datasource by UID the default one,
+// Grafana unsets the previous default datasource itself. Nothing is
+// changed if the datasource is the default one already.
+func (r *Client) SetDefaultDatasource(ctx context.Context, uid string) (StatusMessage, error) {
+	ds, err := r.GetDatasourceByUID(ctx, uid)
+	if err != nil {
+		return StatusMessage{}, err
+	}
+	if ds.IsDefault {
+		return StatusMessage{}, nil
+	}
+	ds.IsDefault = true
+	return r.UpdateDatasourceByUID(ctx, ds)
+}
+
+// GetDatasourceHealth checks the datasource by UID in the same way as
+// "Save & test" button of the datasource settings does. A failed check
+// is not an error, it is reported with the ERROR status and the message
+// of the datasource plugin.
+// Reflects GET /api/datasources/uid/:uid/health API call.
+func (r *Client) GetDatasourceHealth(ctx context.Context, uid string) (DatasourceHealth, error) {
+	var (
+		raw    []byte
+		health DatasourceHealth
+		code   int
+		err    error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/datasources/uid/%s/health", url.PathEscape(uid)), nil); err != nil {
+		return health, err
+	}
+	if code != 200 && code != 400 {
+		return health, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if err = json.Unmarshal(raw, &health); err != nil || health.Status == "" {
+		return health, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	return health, nil
+}
+
 // CreateDatasource creates a new datasource.
 // Reflects POST /api/datasources API call.
 func (r *Client) CreateDatasource(ctx context.Context, ds Datasource) (StatusMessage, error) {
@@ -122,6 +197,33 @@ func (r *Client) UpdateDatasource(ctx context.Context, ds Datasource) (StatusMes
 	return resp, nil
 }
 
+// UpdateDatasourceByUID updates the datasource with the UID of the
+// argument. Version of the datasource, if it is set, should match the
+// actual one, otherwise Grafana rejects the change made concurrently.
+// Reflects PUT /api/datasources/uid/:uid API call.
+func (r *Client) UpdateDatasourceByUID(ctx context.Context, ds Datasource) (StatusMessage, error) {
+	var (
+		raw  []byte
+		resp StatusMessage
+		code int
+		err  error
+	)
+	if ds.UID == "" {
+		return StatusMessage{}, fmt.Errorf("datasource UID is empty")
+	}
+	if raw, err = json.Marshal(ds); err != nil {
+		return StatusMessage{}, err
+	}
+	if raw, code, err = r.put(ctx, fmt.Sprintf("api/datasources/uid/%s", url.PathEscape(ds.UID)), nil, raw); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &resp)
+	return resp, err
+}
+
 // DeleteDatasource deletes an existing datasource by ID.
 // Reflects DELETE /api/datasources/:datasourceId API call.
 func (r *Client) DeleteDatasource(ctx context.Context, id uint) (StatusMessage, error) {
@@ -152,6 +254,25 @@ func (r *Client) DeleteDatasourceByName(ctx context.Context, name string) (Statu
 	return reply, err
 }
 
+// DeleteDatasourceByUID deletes an existing datasource by UID.
+// Reflects DELETE /api/datasources/uid/:uid API call.
+func (r *Client) DeleteDatasourceByUID(ctx context.Context, uid string) (StatusMessage, error) {
+	var (
+		raw   []byte
+		reply StatusMessage
+		code  int
+		err   error
+	)
+	if raw, code, err = r.delete(ctx, fmt.Sprintf("api/datasources/uid/%s", url.PathEscape(uid))); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &reply)
+	return reply, err
+}
+
 // GetDatasourceTypes gets all available plugins for the datasources.
 // Reflects GET /api/datasources/plugins API call.
 func (r *Client) GetDatasourceTypes(ctx context.Context) (map[string]DatasourceType, error) {
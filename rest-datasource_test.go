@@ -0,0 +1,83 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_DatasourceByUID(t *testing.T) {
+	var updated map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/datasources":
+			_, _ = w.Write([]byte(`[{"id": 1, "uid": "prom", "name": "Prometheus", "type": "prometheus", "isDefault": true},
+				{"id": 2, "uid": "loki", "name": "Loki", "type": "loki"}]`))
+		case "GET /api/datasources/uid/loki":
+			_, _ = w.Write([]byte(`{"id": 2, "uid": "loki", "name": "Loki", "type": "loki", "version": 4,
+				"jsonData": {"maxLines": "1000", "derivedFields": [{"name": "traceID", "matcherRegex": "traceID=(\\w+)", "datasourceUid": "tempo"}]}}`))
+		case "GET /api/datasources/uid/prom":
+			_, _ = w.Write([]byte(`{"id": 1, "uid": "prom", "name": "Prometheus", "type": "prometheus", "isDefault": true}`))
+		case "PUT /api/datasources/uid/loki":
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &updated)
+			_, _ = w.Write([]byte(`{"id": 2, "message": "Datasource updated", "name": "Loki"}`))
+		case "DELETE /api/datasources/uid/loki":
+			_, _ = w.Write([]byte(`{"id": 2, "message": "Data source deleted"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Data source not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	ds, err := client.GetDatasourceByUID(ctx, "loki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, ok := ds.JSONData.(*sdk.LokiJSONData)
+	if !ok || settings.MaxLines != "1000" || settings.DerivedFields[0].DatasourceUID != "tempo" {
+		t.Fatalf("unexpected settings %#v", ds.JSONData)
+	}
+
+	settings.MaxLines = "5000"
+	if _, err = client.UpdateDatasourceByUID(ctx, ds); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, _ := updated["jsonData"].(map[string]interface{})
+	if jsonData["maxLines"] != "5000" || jsonData["derivedFields"] == nil || updated["version"] != 4.0 {
+		t.Errorf("unexpected update request %v", updated)
+	}
+
+	def, err := client.GetDefaultDatasource(ctx)
+	if err != nil || def.UID != "prom" {
+		t.Errorf("expected prom as the default datasource, got %+v: %v", def, err)
+	}
+	updated = nil
+	if _, err = client.SetDefaultDatasource(ctx, "prom"); err != nil || updated != nil {
+		t.Errorf("expected no update of the default datasource: %v", err)
+	}
+	if _, err = client.SetDefaultDatasource(ctx, "loki"); err != nil {
+		t.Fatal(err)
+	}
+	if updated["isDefault"] != true {
+		t.Errorf("expected the datasource set as default, got %v", updated)
+	}
+
+	if _, err = client.DeleteDatasourceByUID(ctx, "loki"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.DeleteDatasourceByUID(ctx, "missing"); err == nil {
+		t.Error("expected error for missing datasource")
+	}
+	if _, err = client.UpdateDatasourceByUID(ctx, sdk.Datasource{Name: "no uid"}); err == nil {
+		t.Error("expected error for datasource without UID")
+	}
+}
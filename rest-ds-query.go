@@ -0,0 +1,131 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana-tools/sdk/dataframe"
+)
+
+// QueryDataRequest is the request of the queries to datasources. From and
+// To are in the same format as the dashboard time range, e.g. "now-1h"
+// or milliseconds since epoch returned by URLTime().
+type QueryDataRequest struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Queries []Target `json:"queries"`
+	Debug   bool     `json:"debug,omitempty"`
+}
+
+// QueryDataResponse holds results of the queries by their RefIDs.
+type QueryDataResponse struct {
+	Results map[string]QueryDataResult `json:"results"`
+}
+
+// QueryDataResult is the result of a single query. Error is set if the
+// query is failed.
+type QueryDataResult struct {
+	Status      int                `json:"status,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	ErrorSource string             `json:"errorSource,omitempty"`
+	Frames      []*dataframe.Frame `json:"frames,omitempty"`
+}
+
+// Err returns the error of the failed queries or nil if all the queries
+// succeeded.
+func (r QueryDataResponse) Err() error {
+	var errs []string
+	for refID, result := range r.Results {
+		if result.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", refID, result.Error))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("query failed: %s", strings.Join(errs, "; "))
+}
+
+// QueryTargets returns copies of the panel targets ready for QueryData():
+// targets without their own datasource get the datasource of the panel.
+// Datasource variables and the legacy references by name should be
+// resolved before, see Board.ResolveDatasourceRefs().
+func (p *Panel) QueryTargets() []Target {
+	targets := p.GetTargets()
+	if targets == nil {
+		return nil
+	}
+	result := make([]Target, 0, len(*targets))
+	for _, t := range *targets {
+		if t.Datasource == nil && p.Datasource != nil {
+			ds := *p.Datasource
+			t.Datasource = &ds
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// QueryData runs the queries of the targets through Grafana for the time
+// range and returns data frames of the results. Each target should refer
+// to its datasource by UID, targets without RefID are given one. Failed
+// queries are not an error of the call, check QueryDataResponse.Err().
+// Reflects POST /api/ds/query API call.
+func (r *Client) QueryData(ctx context.Context, timeRange Time, targets ...Target) (QueryDataResponse, error) {
+	req := QueryDataRequest{From: timeRange.From, To: timeRange.To, Queries: append([]Target{}, targets...)}
+	for i := range req.Queries {
+		if req.Queries[i].RefID == "" {
+			req.Queries[i].RefID = nextRefID(req.Queries)
+		}
+	}
+	return r.QueryDataRequest(ctx, req)
+}
+
+// QueryDataRequest runs the queries of the request through Grafana in the
+// same way as QueryData() does.
+// Reflects POST /api/ds/query API call.
+func (r *Client) QueryDataRequest(ctx context.Context, req QueryDataRequest) (QueryDataResponse, error) {
+	var (
+		raw  []byte
+		resp QueryDataResponse
+		code int
+		err  error
+	)
+	if raw, err = json.Marshal(req); err != nil {
+		return resp, err
+	}
+	if raw, code, err = r.post(ctx, "api/ds/query", nil, raw); err != nil {
+		return resp, err
+	}
+	// 207 Multi-Status is returned when some of the queries failed
+	if code != 200 && code != 207 {
+		return resp, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if err = json.Unmarshal(raw, &resp); err != nil {
+		return resp, fmt.Errorf("unmarshal query data: %s\n%s", err, raw)
+	}
+	return resp, nil
+}
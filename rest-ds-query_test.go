@@ -0,0 +1,108 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk"
+	"github.com/grafana-tools/sdk/dataframe"
+)
+
+func TestClient_GetDatasourceHealth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/datasources/uid/prom/health":
+			_, _ = w.Write([]byte(`{"status": "OK", "message": "Successfully queried the Prometheus API."}`))
+		case "/api/datasources/uid/loki/health":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": "ERROR", "message": "Unable to connect with Loki."}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Data source not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	health, err := client.GetDatasourceHealth(ctx, "prom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !health.IsOK() || health.Message != "Successfully queried the Prometheus API." {
+		t.Errorf("unexpected health %+v", health)
+	}
+	if health, err = client.GetDatasourceHealth(ctx, "loki"); err != nil {
+		t.Fatal(err)
+	}
+	if health.IsOK() || health.Status != sdk.DatasourceHealthError {
+		t.Errorf("expected failed check, got %+v", health)
+	}
+	if _, err = client.GetDatasourceHealth(ctx, "missing"); err == nil {
+		t.Error("expected error for missing datasource")
+	}
+}
+
+func TestClient_QueryData(t *testing.T) {
+	var req sdk.QueryDataRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/ds/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`{"results": {
+			"A": {"status": 200, "frames": [{
+				"schema": {"refId": "A", "meta": {"executedQueryString": "up"}, "fields": [
+					{"name": "Time", "type": "time", "typeInfo": {"frame": "time.Time"}},
+					{"name": "Value", "type": "number", "typeInfo": {"frame": "float64"}, "labels": {"job": "api"}}]},
+				"data": {"values": [[1700000000000, 1700000015000], [1, 0.5]]}}]},
+			"B": {"status": 400, "error": "parse error at char 4", "errorSource": "downstream"}}}`))
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+
+	p := sdk.NewTimeseries("Up")
+	p.Datasource = sdk.NewDatasourceRef("prometheus", "prom")
+	p.AddTarget(&sdk.Target{Expr: "up"})
+	p.AddTarget(&sdk.Target{Expr: "up{"})
+	targets := append(p.QueryTargets(), sdk.Target{Expr: "vector(1)"})
+	resp, err := client.QueryData(context.Background(), sdk.Time{From: "now-1h", To: "now"}, targets...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.From != "now-1h" || req.To != "now" || len(req.Queries) != 3 {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if req.Queries[0].Datasource == nil || req.Queries[0].Datasource.UID != "prom" || req.Queries[2].Datasource != nil {
+		t.Errorf("expected the panel datasource for panel targets only, got %+v", req.Queries)
+	}
+	if req.Queries[2].RefID != "C" {
+		t.Errorf("expected RefID C for the target without it, got %q", req.Queries[2].RefID)
+	}
+
+	a := resp.Results["A"]
+	if len(a.Frames) != 1 || a.Frames[0].RefID != "A" || a.Frames[0].Len() != 2 {
+		t.Fatalf("unexpected result %+v", a)
+	}
+	fields := a.Frames[0].Fields
+	if fields[0].Type != dataframe.FieldTypeTime || !fields[0].Values[1].(time.Time).Equal(time.Unix(1700000015, 0)) {
+		t.Errorf("unexpected time field %+v", fields[0])
+	}
+	if fields[1].Labels["job"] != "api" || fields[1].Values[1] != 0.5 {
+		t.Errorf("unexpected value field %+v", fields[1])
+	}
+	if err = resp.Err(); err == nil || err.Error() != "query failed: B: parse error at char 4" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -351,50 +351,6 @@ func (r *Client) DeleteOrgUser(ctx context.Context, oid, uid uint) (StatusMessag
 	return reply, err
 }
 
-// UpdateActualOrgPreferences updates preferences of the actual organization.
-// Reflects PUT /api/org/preferences API call.
-func (r *Client) UpdateActualOrgPreferences(ctx context.Context, prefs Preferences) (StatusMessage, error) {
-	var (
-		raw  []byte
-		resp StatusMessage
-		err  error
-	)
-	if raw, err = json.Marshal(prefs); err != nil {
-		return StatusMessage{}, err
-	}
-	if raw, _, err = r.put(ctx, "api/org/preferences/", nil, raw); err != nil {
-		return StatusMessage{}, err
-	}
-	if err = json.Unmarshal(raw, &resp); err != nil {
-		return StatusMessage{}, err
-	}
-	return resp, nil
-}
-
-// GetActualOrgPreferences gets preferences of the actual organization.
-// It reflects GET /api/org/preferences API call.
-func (r *Client) GetActualOrgPreferences(ctx context.Context) (Preferences, error) {
-	var (
-		raw  []byte
-		pref Preferences
-		code int
-		err  error
-	)
-	if raw, code, err = r.get(ctx, "/api/org/preferences", nil); err != nil {
-		return pref, err
-	}
-
-	if code != http.StatusOK {
-		return pref, fmt.Errorf("HTTP error %d: returns %s", code, raw)
-	}
-	dec := json.NewDecoder(bytes.NewReader(raw))
-	dec.UseNumber()
-	if err := dec.Decode(&pref); err != nil {
-		return pref, fmt.Errorf("unmarshal prefs: %s\n%s", err, raw)
-	}
-	return pref, err
-}
-
 // UpdateActualOrgAddress updates current organization's address.
 // It reflects PUT /api/org/address API call.
 func (r *Client) UpdateActualOrgAddress(ctx context.Context, address Address) (StatusMessage, error) {
@@ -0,0 +1,174 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// https://grafana.com/docs/grafana/latest/developers/http_api/playlist/
+
+// GetAllPlaylists gets playlists without their items. Use
+// QueryParamQuery() to filter them by name and QueryParamLimit() to
+// limit their number.
+// Reflects GET /api/playlists API call.
+func (r *Client) GetAllPlaylists(ctx context.Context, params ...QueryParam) ([]Playlist, error) {
+	var (
+		raw       []byte
+		playlists []Playlist
+		code      int
+		err       error
+	)
+	if raw, code, err = r.get(ctx, "api/playlists", queryParams(params...)); err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &playlists)
+	return playlists, err
+}
+
+// GetPlaylist gets the playlist with its items by UID.
+// Reflects GET /api/playlists/:uid API call.
+func (r *Client) GetPlaylist(ctx context.Context, uid string) (Playlist, error) {
+	var (
+		raw      []byte
+		playlist Playlist
+		code     int
+		err      error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/playlists/%s", url.PathEscape(uid)), nil); err != nil {
+		return playlist, err
+	}
+	if code != 200 {
+		return playlist, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &playlist)
+	return playlist, err
+}
+
+// GetPlaylistItems gets items of the playlist by UID.
+// Reflects GET /api/playlists/:uid/items API call.
+func (r *Client) GetPlaylistItems(ctx context.Context, uid string) ([]PlaylistItem, error) {
+	var (
+		raw   []byte
+		items []PlaylistItem
+		code  int
+		err   error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/playlists/%s/items", url.PathEscape(uid)), nil); err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &items)
+	return items, err
+}
+
+// CreatePlaylist creates the playlist with its items. The returned
+// playlist has UID assigned by Grafana.
+// Reflects POST /api/playlists API call.
+func (r *Client) CreatePlaylist(ctx context.Context, p Playlist) (Playlist, error) {
+	var (
+		raw     []byte
+		created Playlist
+		code    int
+		err     error
+	)
+	if raw, err = json.Marshal(playlistRequest(p)); err != nil {
+		return created, err
+	}
+	if raw, code, err = r.post(ctx, "api/playlists", nil, raw); err != nil {
+		return created, err
+	}
+	if code != 200 {
+		return created, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &created)
+	return created, err
+}
+
+// UpdatePlaylist replaces the name, interval and items of the playlist
+// with the UID of the argument.
+// Reflects PUT /api/playlists/:uid API call.
+func (r *Client) UpdatePlaylist(ctx context.Context, p Playlist) (Playlist, error) {
+	var (
+		raw     []byte
+		updated Playlist
+		code    int
+		err     error
+	)
+	if raw, err = json.Marshal(playlistRequest(p)); err != nil {
+		return updated, err
+	}
+	if raw, code, err = r.put(ctx, fmt.Sprintf("api/playlists/%s", url.PathEscape(p.UID)), nil, raw); err != nil {
+		return updated, err
+	}
+	if code != 200 {
+		return updated, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if len(raw) == 0 {
+		// Grafana before 9.1 replies with an empty body
+		return p, nil
+	}
+	err = json.Unmarshal(raw, &updated)
+	return updated, err
+}
+
+// DeletePlaylist deletes the playlist by UID.
+// Reflects DELETE /api/playlists/:uid API call.
+func (r *Client) DeletePlaylist(ctx context.Context, uid string) (StatusMessage, error) {
+	var (
+		raw   []byte
+		reply StatusMessage
+		code  int
+		err   error
+	)
+	if raw, code, err = r.delete(ctx, fmt.Sprintf("api/playlists/%s", url.PathEscape(uid))); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if len(raw) > 0 {
+		err = json.Unmarshal(raw, &reply)
+	}
+	return reply, err
+}
+
+// playlistRequest is the body of create and update requests, Grafana
+// assigns IDs of the playlist and its items itself.
+func playlistRequest(p Playlist) interface{} {
+	items := make([]PlaylistItem, len(p.Items))
+	for i, item := range p.Items {
+		items[i] = PlaylistItem{Type: item.Type, Value: item.Value, Order: item.Order, Title: item.Title}
+	}
+	return struct {
+		UID      string         `json:"uid,omitempty"`
+		Name     string         `json:"name"`
+		Interval string         `json:"interval"`
+		Items    []PlaylistItem `json:"items"`
+	}{p.UID, p.Name, p.Interval, items}
+}
@@ -0,0 +1,94 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_Playlists(t *testing.T) {
+	var created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/playlists":
+			if q := r.URL.Query().Get("query"); q != "noc" {
+				t.Errorf("unexpected query %q", q)
+			}
+			_, _ = w.Write([]byte(`[{"id": 1, "uid": "noc", "name": "NOC wall", "interval": "5m"}]`))
+		case "GET /api/playlists/noc":
+			_, _ = w.Write([]byte(`{"id": 1, "uid": "noc", "name": "NOC wall", "interval": "5m",
+				"items": [{"id": 3, "playlistId": 1, "type": "dashboard_by_uid", "value": "abc", "order": 1, "title": "Overview"}]}`))
+		case "GET /api/playlists/noc/items":
+			_, _ = w.Write([]byte(`[{"type": "dashboard_by_tag", "value": "noc", "order": 1}]`))
+		case "POST /api/playlists":
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &created)
+			_, _ = w.Write([]byte(`{"id": 2, "uid": "new", "name": "Wall", "interval": "1m"}`))
+		case "PUT /api/playlists/new":
+			// Grafana before 9.1
+		case "DELETE /api/playlists/new":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "playlist not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	playlists, err := client.GetAllPlaylists(ctx, sdk.QueryParamQuery("noc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 1 || playlists[0].UID != "noc" || playlists[0].Interval != "5m" {
+		t.Errorf("unexpected playlists %+v", playlists)
+	}
+	playlist, err := client.GetPlaylist(ctx, "noc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlist.Items) != 1 || playlist.Items[0].Type != sdk.PlaylistItemDashboardByUID || playlist.Items[0].Title != "Overview" {
+		t.Errorf("unexpected playlist %+v", playlist)
+	}
+	items, err := client.GetPlaylistItems(ctx, "noc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Type != sdk.PlaylistItemDashboardByTag {
+		t.Errorf("unexpected items %+v", items)
+	}
+
+	p := sdk.NewPlaylist("Wall", "1m").AddDashboard("abc").AddDashboardsByTag("noc")
+	p.Items[0].ID = 3
+	if playlist, err = client.CreatePlaylist(ctx, *p); err != nil {
+		t.Fatal(err)
+	}
+	if playlist.UID != "new" {
+		t.Errorf("unexpected created playlist %+v", playlist)
+	}
+	items = nil
+	raw, _ := json.Marshal(created["items"])
+	_ = json.Unmarshal(raw, &items)
+	if len(items) != 2 || items[0].ID != 0 || items[1].Order != 2 || items[1].Value != "noc" {
+		t.Errorf("unexpected items of the request %+v", items)
+	}
+
+	p.UID = "new"
+	if playlist, err = client.UpdatePlaylist(ctx, *p); err != nil {
+		t.Fatal(err)
+	}
+	if playlist.Name != "Wall" {
+		t.Errorf("expected the playlist on empty reply, got %+v", playlist)
+	}
+	if _, err = client.DeletePlaylist(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetPlaylist(ctx, "missing"); err == nil {
+		t.Error("expected error for missing playlist")
+	}
+}
@@ -0,0 +1,164 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// https://grafana.com/docs/grafana/latest/developers/http_api/preferences/
+
+// GetActualUserPreferences gets preferences of the actual user.
+// Reflects GET /api/user/preferences API call.
+func (r *Client) GetActualUserPreferences(ctx context.Context) (Preferences, error) {
+	return r.getPreferences(ctx, "api/user/preferences")
+}
+
+// UpdateActualUserPreferences replaces preferences of the actual user,
+// omitted values are reset to the inherited ones.
+// Reflects PUT /api/user/preferences API call.
+func (r *Client) UpdateActualUserPreferences(ctx context.Context, prefs Preferences) (StatusMessage, error) {
+	return r.updatePreferences(ctx, "api/user/preferences", prefs)
+}
+
+// GetTeamPreferences gets the preferences for a team by id.
+// Reflects GET /api/teams/:teamId/preferences API call.
+func (r *Client) GetTeamPreferences(ctx context.Context, teamId uint) (Preferences, error) {
+	return r.getPreferences(ctx, fmt.Sprintf("api/teams/%d/preferences", teamId))
+}
+
+// UpdateTeamPreferences replaces the preferences for a team by id,
+// omitted values are reset to the inherited ones.
+// Reflects PUT /api/teams/:teamId/preferences API call.
+func (r *Client) UpdateTeamPreferences(ctx context.Context, teamId uint, prefs Preferences) (StatusMessage, error) {
+	return r.updatePreferences(ctx, fmt.Sprintf("api/teams/%d/preferences", teamId), prefs)
+}
+
+// GetActualOrgPreferences gets preferences of the actual organization.
+// Reflects GET /api/org/preferences API call.
+func (r *Client) GetActualOrgPreferences(ctx context.Context) (Preferences, error) {
+	return r.getPreferences(ctx, "api/org/preferences")
+}
+
+// UpdateActualOrgPreferences replaces preferences of the actual
+// organization, omitted values are reset to the defaults.
+// Reflects PUT /api/org/preferences API call.
+func (r *Client) UpdateActualOrgPreferences(ctx context.Context, prefs Preferences) (StatusMessage, error) {
+	return r.updatePreferences(ctx, "api/org/preferences", prefs)
+}
+
+func (r *Client) getPreferences(ctx context.Context, path string) (Preferences, error) {
+	var (
+		raw   []byte
+		prefs Preferences
+		code  int
+		err   error
+	)
+	if raw, code, err = r.get(ctx, path, nil); err != nil {
+		return prefs, err
+	}
+	if code != 200 {
+		return prefs, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if err = json.Unmarshal(raw, &prefs); err != nil {
+		return prefs, fmt.Errorf("unmarshal prefs: %s\n%s", err, raw)
+	}
+	return prefs, nil
+}
+
+func (r *Client) updatePreferences(ctx context.Context, path string, prefs Preferences) (StatusMessage, error) {
+	var (
+		raw  []byte
+		resp StatusMessage
+		code int
+		err  error
+	)
+	if raw, err = json.Marshal(prefs); err != nil {
+		return StatusMessage{}, err
+	}
+	if raw, code, err = r.put(ctx, path, nil, raw); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &resp)
+	return resp, err
+}
+
+// GetStarredDashboards gets dashboards starred by the actual user.
+// Reflects GET /api/search?starred=true API call.
+func (r *Client) GetStarredDashboards(ctx context.Context) ([]FoundBoard, error) {
+	return r.Search(ctx, SearchType(SearchTypeDashboard), SearchStarred(true))
+}
+
+// StarDashboard stars the dashboard by UID for the actual user. It is
+// supported by Grafana 10 and later, use StarDashboardByID() for older
+// versions.
+// Reflects POST /api/user/stars/dashboard/uid/:uid API call.
+func (r *Client) StarDashboard(ctx context.Context, uid string) (StatusMessage, error) {
+	return r.star(ctx, true, fmt.Sprintf("api/user/stars/dashboard/uid/%s", url.PathEscape(uid)))
+}
+
+// UnstarDashboard removes the star of the actual user from the dashboard
+// by UID. It is supported by Grafana 10 and later, use
+// UnstarDashboardByID() for older versions.
+// Reflects DELETE /api/user/stars/dashboard/uid/:uid API call.
+func (r *Client) UnstarDashboard(ctx context.Context, uid string) (StatusMessage, error) {
+	return r.star(ctx, false, fmt.Sprintf("api/user/stars/dashboard/uid/%s", url.PathEscape(uid)))
+}
+
+// StarDashboardByID stars the dashboard by ID for the actual user.
+// Reflects POST /api/user/stars/dashboard/:dashboardId API call.
+func (r *Client) StarDashboardByID(ctx context.Context, id uint) (StatusMessage, error) {
+	return r.star(ctx, true, fmt.Sprintf("api/user/stars/dashboard/%d", id))
+}
+
+// UnstarDashboardByID removes the star of the actual user from the
+// dashboard by ID.
+// Reflects DELETE /api/user/stars/dashboard/:dashboardId API call.
+func (r *Client) UnstarDashboardByID(ctx context.Context, id uint) (StatusMessage, error) {
+	return r.star(ctx, false, fmt.Sprintf("api/user/stars/dashboard/%d", id))
+}
+
+func (r *Client) star(ctx context.Context, star bool, path string) (StatusMessage, error) {
+	var (
+		raw   []byte
+		reply StatusMessage
+		code  int
+		err   error
+	)
+	if star {
+		raw, code, err = r.post(ctx, path, nil, nil)
+	} else {
+		raw, code, err = r.delete(ctx, path)
+	}
+	if err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &reply)
+	return reply, err
+}
@@ -0,0 +1,145 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_Preferences(t *testing.T) {
+	updated := make(map[string]sdk.Preferences)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/user/preferences", "GET /api/teams/7/preferences", "GET /api/org/preferences":
+			_, _ = w.Write([]byte(`{"theme": "dark", "homeDashboardId": 4, "homeDashboardUID": "home", "timezone": "utc", "weekStart": "monday"}`))
+		case "PUT /api/user/preferences", "PUT /api/teams/7/preferences", "PUT /api/org/preferences":
+			var prefs sdk.Preferences
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &prefs)
+			updated[r.URL.Path] = prefs
+			_, _ = w.Write([]byte(`{"message": "Preferences updated"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Permission denied"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	for path, get := range map[string]func(context.Context) (sdk.Preferences, error){
+		"/api/user/preferences": client.GetActualUserPreferences,
+		"/api/org/preferences":  client.GetActualOrgPreferences,
+		"/api/teams/7/preferences": func(ctx context.Context) (sdk.Preferences, error) {
+			return client.GetTeamPreferences(ctx, 7)
+		},
+	} {
+		prefs, err := get(ctx)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		if prefs.HomeDashboardUID != "home" || prefs.HomeDashboardId != 4 || prefs.WeekStart != "monday" || prefs.Theme != sdk.ThemeDark {
+			t.Errorf("%s: unexpected preferences %+v", path, prefs)
+		}
+	}
+
+	prefs := sdk.Preferences{Theme: sdk.ThemeLight, HomeDashboardUID: "ops", WeekStart: "sunday"}
+	if msg, err := client.UpdateActualUserPreferences(ctx, prefs); err != nil || *msg.Message != "Preferences updated" {
+		t.Fatalf("unexpected reply %+v: %v", msg, err)
+	}
+	if _, err := client.UpdateTeamPreferences(ctx, 7, prefs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateActualOrgPreferences(ctx, prefs); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/user/preferences", "/api/teams/7/preferences", "/api/org/preferences"} {
+		if updated[path] != prefs {
+			t.Errorf("%s: unexpected preferences of the request %+v", path, updated[path])
+		}
+	}
+
+	if _, err := client.GetTeamPreferences(ctx, 8); err == nil {
+		t.Error("expected error for forbidden team")
+	}
+}
+
+func TestClient_Stars(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/user/stars/dashboard/uid/abc", "DELETE /api/user/stars/dashboard/uid/abc":
+			calls = append(calls, r.Method+" uid")
+			_, _ = w.Write([]byte(`{"message": "Dashboard starred!"}`))
+		case "POST /api/user/stars/dashboard/12", "DELETE /api/user/stars/dashboard/12":
+			calls = append(calls, r.Method+" id")
+			_, _ = w.Write([]byte(`{"message": "Dashboard unstarred"}`))
+		case "GET /api/search":
+			if q := r.URL.Query(); q.Get("starred") != "true" || q.Get("type") != string(sdk.SearchTypeDashboard) {
+				t.Errorf("unexpected search query %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"id": 12, "uid": "abc", "title": "Overview", "isStarred": true}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Dashboard not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	if _, err := client.StarDashboard(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UnstarDashboard(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.StarDashboardByID(ctx, 12); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UnstarDashboardByID(ctx, 12); err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"POST uid", "DELETE uid", "POST id", "DELETE id"}
+	if len(calls) != len(exp) {
+		t.Fatalf("expected calls %v, got %v", exp, calls)
+	}
+	for i := range exp {
+		if calls[i] != exp[i] {
+			t.Errorf("expected calls %v, got %v", exp, calls)
+		}
+	}
+	if _, err := client.StarDashboard(ctx, "missing"); err == nil {
+		t.Error("expected error for missing dashboard")
+	}
+
+	starred, err := client.GetStarredDashboards(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(starred) != 1 || starred[0].UID != "abc" || !starred[0].IsStarred {
+		t.Errorf("unexpected starred dashboards %+v", starred)
+	}
+}
+
+func TestTeamPreferences_Preferences(t *testing.T) {
+	prefs := sdk.TeamPreferences{Theme: sdk.ThemeDark, HomeDashboardId: 7, Timezone: "utc"}.Preferences()
+	if prefs != (sdk.Preferences{Theme: sdk.ThemeDark, HomeDashboardId: 7, Timezone: "utc"}) {
+		t.Errorf("unexpected preferences %+v", prefs)
+	}
+	if prefs = (sdk.TeamPreferences{HomeDashboardId: -1}).Preferences(); prefs.HomeDashboardId != 0 {
+		t.Errorf("expected no home dashboard, got %d", prefs.HomeDashboardId)
+	}
+	raw, err := json.Marshal(sdk.TeamPreferences{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"theme":"","homeDashboardId":0,"timezone":""}` {
+		t.Errorf("unexpected team preferences JSON %s", raw)
+	}
+}
@@ -0,0 +1,169 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard_public/
+
+// GetPublicDashboards gets a page of public dashboards of the organization.
+// Pages are numbered from 1, perPage of 0 leaves the default page size.
+// Reflects GET /api/dashboards/public-dashboards API call.
+func (r *Client) GetPublicDashboards(ctx context.Context, page, perPage int) (PublicDashboardList, error) {
+	var (
+		raw    []byte
+		list   PublicDashboardList
+		code   int
+		err    error
+		params = url.Values{}
+	)
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		params.Set("perpage", strconv.Itoa(perPage))
+	}
+	if raw, code, err = r.get(ctx, "api/dashboards/public-dashboards", params); err != nil {
+		return list, err
+	}
+	if code != 200 {
+		return list, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &list)
+	return list, err
+}
+
+// GetPublicDashboard gets the public dashboard configuration of the
+// dashboard, e.g. Board.UID or FoundBoard.UID.
+// Reflects GET /api/dashboards/uid/:dashboardUid/public-dashboards API call.
+func (r *Client) GetPublicDashboard(ctx context.Context, dashboardUID string) (PublicDashboard, error) {
+	var (
+		raw  []byte
+		pd   PublicDashboard
+		code int
+		err  error
+	)
+	if raw, code, err = r.get(ctx, publicDashboardsPath(dashboardUID), nil); err != nil {
+		return pd, err
+	}
+	if code != 200 {
+		return pd, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &pd)
+	return pd, err
+}
+
+// CreatePublicDashboard makes the dashboard public with the configuration.
+// UID and AccessToken are generated by Grafana unless set.
+// Reflects POST /api/dashboards/uid/:dashboardUid/public-dashboards API call.
+func (r *Client) CreatePublicDashboard(ctx context.Context, dashboardUID string, pd PublicDashboard) (PublicDashboard, error) {
+	var (
+		raw     []byte
+		created PublicDashboard
+		code    int
+		err     error
+	)
+	if raw, err = json.Marshal(publicDashboardRequest(pd)); err != nil {
+		return created, err
+	}
+	if raw, code, err = r.post(ctx, publicDashboardsPath(dashboardUID), nil, raw); err != nil {
+		return created, err
+	}
+	if code != 200 {
+		return created, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &created)
+	return created, err
+}
+
+// UpdatePublicDashboard changes flags and the share mode of the public
+// dashboard with UID of the argument, e.g. disables it with IsEnabled
+// set to false.
+// Reflects PATCH /api/dashboards/uid/:dashboardUid/public-dashboards/:uid API call.
+func (r *Client) UpdatePublicDashboard(ctx context.Context, dashboardUID string, pd PublicDashboard) (PublicDashboard, error) {
+	var (
+		raw     []byte
+		updated PublicDashboard
+		code    int
+		err     error
+	)
+	if raw, err = json.Marshal(publicDashboardRequest(pd)); err != nil {
+		return updated, err
+	}
+	path := publicDashboardsPath(dashboardUID) + "/" + url.PathEscape(pd.UID)
+	if raw, code, err = r.patch(ctx, path, nil, raw); err != nil {
+		return updated, err
+	}
+	if code != 200 {
+		return updated, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &updated)
+	return updated, err
+}
+
+// DeletePublicDashboard revokes public access to the dashboard, the access
+// token stops working.
+// Reflects DELETE /api/dashboards/uid/:dashboardUid/public-dashboards/:uid API call.
+func (r *Client) DeletePublicDashboard(ctx context.Context, dashboardUID, uid string) error {
+	raw, code, err := r.delete(ctx, publicDashboardsPath(dashboardUID)+"/"+url.PathEscape(uid))
+	if err != nil {
+		return err
+	}
+	if code != 200 {
+		return fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	return nil
+}
+
+// PublicDashboardURL returns the link to the public dashboard on the
+// Grafana instance of the client.
+func (r *Client) PublicDashboardURL(accessToken string) string {
+	u, err := url.Parse(r.baseURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.Path = path.Join(u.Path, "public-dashboards", accessToken)
+	return u.String()
+}
+
+func publicDashboardsPath(dashboardUID string) string {
+	return fmt.Sprintf("api/dashboards/uid/%s/public-dashboards", url.PathEscape(dashboardUID))
+}
+
+// publicDashboardRequest leaves only the fields Grafana accepts on create
+// and update.
+func publicDashboardRequest(pd PublicDashboard) interface{} {
+	return struct {
+		UID                  string `json:"uid,omitempty"`
+		AccessToken          string `json:"accessToken,omitempty"`
+		IsEnabled            bool   `json:"isEnabled"`
+		TimeSelectionEnabled bool   `json:"timeSelectionEnabled"`
+		AnnotationsEnabled   bool   `json:"annotationsEnabled"`
+		Share                string `json:"share,omitempty"`
+	}{pd.UID, pd.AccessToken, pd.IsEnabled, pd.TimeSelectionEnabled, pd.AnnotationsEnabled, pd.Share}
+}
@@ -0,0 +1,112 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_CreateShortURL(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/short-urls" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req struct {
+			Path string `json:"path"`
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &req)
+		path = req.Path
+		_, _ = w.Write([]byte(`{"uid": "AT76wBvnz", "url": "http://grafana/goto/AT76wBvnz?orgId=1"}`))
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+
+	found := sdk.FoundBoard{UID: "svc", URL: "/d/svc/service"}
+	short, err := client.CreateShortURL(context.Background(), "/"+found.URLPath(sdk.DashboardURLParams{From: "now-1h", To: "now"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if short.UID != "AT76wBvnz" || short.URL == "" {
+		t.Errorf("unexpected short URL %+v", short)
+	}
+	if path != "d/svc/service?from=now-1h&to=now" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
+
+func TestClient_PublicDashboards(t *testing.T) {
+	var patch map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/dashboards/public-dashboards":
+			if r.URL.Query().Get("perpage") != "50" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"publicDashboards": [{"uid": "pub", "accessToken": "token", "title": "Service", "dashboardUid": "svc", "isEnabled": true}], "totalCount": 1, "page": 1, "perPage": 50}`))
+		case "GET /api/dashboards/uid/svc/public-dashboards":
+			_, _ = w.Write([]byte(`{"uid": "pub", "dashboardUid": "svc", "accessToken": "token", "isEnabled": true, "share": "public"}`))
+		case "POST /api/dashboards/uid/svc/public-dashboards":
+			_, _ = w.Write([]byte(`{"uid": "pub", "dashboardUid": "svc", "accessToken": "token", "isEnabled": true, "timeSelectionEnabled": true}`))
+		case "PATCH /api/dashboards/uid/svc/public-dashboards/pub":
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &patch)
+			_, _ = w.Write([]byte(`{"uid": "pub", "dashboardUid": "svc", "accessToken": "token", "isEnabled": false}`))
+		case "DELETE /api/dashboards/uid/svc/public-dashboards/pub":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "public dashboard not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "admin:secret", ts.Client())
+	ctx := context.Background()
+
+	list, err := client.GetPublicDashboards(ctx, 1, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.TotalCount != 1 || list.PublicDashboards[0].AccessToken != "token" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	created, err := client.CreatePublicDashboard(ctx, "svc", sdk.PublicDashboard{
+		IsEnabled:            true,
+		TimeSelectionEnabled: true,
+		Share:                sdk.PublicDashboardSharePublic,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UID != "pub" || !created.TimeSelectionEnabled {
+		t.Errorf("unexpected public dashboard %+v", created)
+	}
+	pd, err := client.GetPublicDashboard(ctx, "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pd.IsEnabled = false
+	if pd, err = client.UpdatePublicDashboard(ctx, "svc", pd); err != nil {
+		t.Fatal(err)
+	}
+	if pd.IsEnabled || patch["isEnabled"] != false || patch["share"] != "public" {
+		t.Errorf("unexpected update %+v with %v", pd, patch)
+	}
+	if _, ok := patch["dashboardUid"]; ok {
+		t.Error("dashboard UID should not be sent")
+	}
+	if err = client.DeletePublicDashboard(ctx, "svc", "pub"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetPublicDashboard(ctx, "missing"); err == nil {
+		t.Error("expected error for missing public dashboard")
+	}
+	if u := client.PublicDashboardURL("token"); u != ts.URL+"/public-dashboards/token" {
+		t.Errorf("unexpected public URL %s", u)
+	}
+}
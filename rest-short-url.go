@@ -0,0 +1,59 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ShortURL is the short link to a Grafana page, URL redirects to the path
+// it was created for.
+type ShortURL struct {
+	UID string `json:"uid"`
+	URL string `json:"url"`
+}
+
+// CreateShortURL creates the short URL for the path relative to Grafana
+// root URL, e.g. the one returned by Board.URLPath() or FoundBoard.URLPath().
+// Reflects POST /api/short-urls API call.
+func (r *Client) CreateShortURL(ctx context.Context, path string) (ShortURL, error) {
+	var (
+		raw      []byte
+		shortURL ShortURL
+		code     int
+		err      error
+	)
+	if raw, err = json.Marshal(struct {
+		Path string `json:"path"`
+	}{strings.TrimPrefix(path, "/")}); err != nil {
+		return shortURL, err
+	}
+	if raw, code, err = r.post(ctx, "api/short-urls", nil, raw); err != nil {
+		return shortURL, err
+	}
+	if code != 200 {
+		return shortURL, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &shortURL)
+	return shortURL, err
+}
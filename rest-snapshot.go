@@ -4,31 +4,119 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/pkg/errors"
 )
 
 // https://grafana.com/docs/grafana/latest/http_api/snapshot/
 
-// CreateAnnotation creates a new snapshot.
-func (r *Client) CreateSnapshot(ctx context.Context, a CreateSnapshotRequest) (StatusMessage, error) {
+// CreateSnapshot creates a new snapshot. Use Board.MakeSnapshot() to
+// embed data of the panels into the dashboard so the snapshot could be
+// viewed without access to the datasources.
+// Reflects POST /api/snapshots API call.
+func (r *Client) CreateSnapshot(ctx context.Context, a CreateSnapshotRequest) (CreateSnapshotResponse, error) {
 	var (
 		raw  []byte
-		resp StatusMessage
+		resp CreateSnapshotResponse
 		err  error
 		code int
 	)
 	if raw, err = json.Marshal(a); err != nil {
-		return StatusMessage{}, errors.Wrap(err, "marshal request")
+		return CreateSnapshotResponse{}, errors.Wrap(err, "marshal request")
 	}
 	if raw, code, err = r.post(ctx, "api/snapshots", nil, raw); err != nil {
-		return StatusMessage{}, errors.Wrap(err, "create snapshot")
+		return CreateSnapshotResponse{}, errors.Wrap(err, "create snapshot")
 	}
 	if code/100 != 2 {
-		return StatusMessage{}, fmt.Errorf("bad response: %d", code)
+		return CreateSnapshotResponse{}, fmt.Errorf("bad response: %d", code)
 	}
 	if err = json.Unmarshal(raw, &resp); err != nil {
-		return StatusMessage{}, errors.Wrap(err, "unmarshal response message")
+		return CreateSnapshotResponse{}, errors.Wrap(err, "unmarshal response message")
 	}
 	return resp, nil
 }
+
+// GetSnapshots gets snapshots of the current organization. Use
+// QueryParamQuery() to filter them by name and QueryParamLimit() to
+// limit their number.
+// Reflects GET /api/dashboard/snapshots API call.
+func (r *Client) GetSnapshots(ctx context.Context, params ...QueryParam) ([]Snapshot, error) {
+	var (
+		raw       []byte
+		snapshots []Snapshot
+		code      int
+		err       error
+	)
+	if raw, code, err = r.get(ctx, "api/dashboard/snapshots", queryParams(params...)); err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &snapshots)
+	return snapshots, err
+}
+
+// GetSnapshot loads the snapshot dashboard and its metadata by the key.
+// Reflects GET /api/snapshots/:key API call.
+func (r *Client) GetSnapshot(ctx context.Context, key string) (Board, BoardProperties, error) {
+	var (
+		raw    []byte
+		result struct {
+			Meta  BoardProperties `json:"meta"`
+			Board Board           `json:"dashboard"`
+		}
+		code int
+		err  error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/snapshots/%s", url.PathEscape(key)), nil); err != nil {
+		return Board{}, BoardProperties{}, err
+	}
+	if code != 200 {
+		return Board{}, BoardProperties{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	if err = json.Unmarshal(raw, &result); err != nil {
+		return Board{}, BoardProperties{}, errors.Wrap(err, "unmarshal snapshot")
+	}
+	return result.Board, result.Meta, nil
+}
+
+// DeleteSnapshot deletes the snapshot by the key.
+// Reflects DELETE /api/snapshots/:key API call.
+func (r *Client) DeleteSnapshot(ctx context.Context, key string) (StatusMessage, error) {
+	var (
+		raw   []byte
+		reply StatusMessage
+		code  int
+		err   error
+	)
+	if raw, code, err = r.delete(ctx, fmt.Sprintf("api/snapshots/%s", url.PathEscape(key))); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &reply)
+	return reply, err
+}
+
+// DeleteSnapshotByDeleteKey deletes the snapshot by the delete key
+// returned on its creation. The delete key works without authentication.
+// Reflects GET /api/snapshots-delete/:deleteKey API call.
+func (r *Client) DeleteSnapshotByDeleteKey(ctx context.Context, deleteKey string) (StatusMessage, error) {
+	var (
+		raw   []byte
+		reply StatusMessage
+		code  int
+		err   error
+	)
+	if raw, code, err = r.get(ctx, fmt.Sprintf("api/snapshots-delete/%s", url.PathEscape(deleteKey)), nil); err != nil {
+		return StatusMessage{}, err
+	}
+	if code != 200 {
+		return StatusMessage{}, fmt.Errorf("HTTP error %d: returns %s", code, raw)
+	}
+	err = json.Unmarshal(raw, &reply)
+	return reply, err
+}
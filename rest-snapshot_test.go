@@ -0,0 +1,81 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestClient_Snapshots(t *testing.T) {
+	var request map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/snapshots":
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &request)
+			_, _ = w.Write([]byte(`{"deleteKey": "del", "deleteUrl": "http://grafana/api/snapshots-delete/del", "id": 1, "key": "snap", "url": "http://grafana/dashboard/snapshot/snap"}`))
+		case "GET /api/dashboard/snapshots":
+			if r.URL.Query().Get("limit") != "10" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"id": 1, "name": "Incident", "key": "snap", "external": false, "expires": "2030-01-01T00:00:00Z", "created": "2020-01-01T00:00:00Z"}]`))
+		case "GET /api/snapshots/snap":
+			_, _ = w.Write([]byte(`{"meta": {"isSnapshot": true, "type": "snapshot", "created": "2020-01-01T00:00:00Z"}, "dashboard": {"title": "Incident", "panels": []}}`))
+		case "DELETE /api/snapshots/snap", "GET /api/snapshots-delete/del":
+			_, _ = w.Write([]byte(`{"message": "Snapshot deleted. It might take an hour before it's cleared from any CDN caches.", "id": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Snapshot not found"}`))
+		}
+	}))
+	defer ts.Close()
+	client, _ := sdk.NewClient(ts.URL, "", ts.Client())
+	ctx := context.Background()
+
+	resp, err := client.CreateSnapshot(ctx, sdk.CreateSnapshotRequest{Dashboard: *sdk.NewBoard("Incident"), Name: "Incident", Expires: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Key != "snap" || resp.DeleteKey != "del" || resp.URL == nil || *resp.URL == "" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if request["name"] != "Incident" {
+		t.Errorf("unexpected request %v", request)
+	}
+	if _, ok := request["external"]; ok {
+		t.Error("external flag should be omitted")
+	}
+
+	snapshots, err := client.GetSnapshots(ctx, sdk.QueryParamLimit(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Key != "snap" || snapshots[0].Expires.Year() != 2030 {
+		t.Errorf("unexpected snapshots %+v", snapshots)
+	}
+	board, meta, err := client.GetSnapshot(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Title != "Incident" || !meta.IsSnapshot {
+		t.Errorf("unexpected snapshot %s %+v", board.Title, meta)
+	}
+	if _, err = client.DeleteSnapshot(ctx, "snap"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.DeleteSnapshotByDeleteKey(ctx, "del")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ID == nil || *status.ID != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, _, err = client.GetSnapshot(ctx, "missing"); err == nil {
+		t.Error("expected error for missing snapshot")
+	}
+}
@@ -217,49 +217,6 @@ func (r *Client) DeleteTeamMember(ctx context.Context, teamId uint, userId uint)
 	return resp, nil
 }
 
-// GetTeamPreferences gets the preferences for a team by id.
-// Reflects GET /api/teams/:teamId/preferences API call.
-func (r *Client) GetTeamPreferences(ctx context.Context, teamId uint) (TeamPreferences, error) {
-	var (
-		raw             []byte
-		teamPreferences TeamPreferences
-		code            int
-		err             error
-	)
-	if raw, code, err = r.get(ctx, fmt.Sprintf("api/teams/%d/preferences", teamId), nil); err != nil {
-		return teamPreferences, err
-	}
-	if code != 200 {
-		return teamPreferences, fmt.Errorf("HTTP error %d: returns %s", code, raw)
-	}
-	dec := json.NewDecoder(bytes.NewReader(raw))
-	dec.UseNumber()
-	if err := dec.Decode(&teamPreferences); err != nil {
-		return teamPreferences, fmt.Errorf("unmarshal team: %s\n%s", err, raw)
-	}
-	return teamPreferences, err
-}
-
-// UpdateTeamPreferences updates the preferences for a team by id.
-// Reflects PUT /api/teams/:teamId/preferences API call.
-func (r *Client) UpdateTeamPreferences(ctx context.Context, teamId uint, tp TeamPreferences) (StatusMessage, error) {
-	var (
-		raw  []byte
-		resp StatusMessage
-		err  error
-	)
-	if raw, err = json.Marshal(tp); err != nil {
-		return StatusMessage{}, err
-	}
-	if raw, _, err = r.put(ctx, fmt.Sprintf("api/teams/%d/preferences", teamId), nil, raw); err != nil {
-		return StatusMessage{}, err
-	}
-	if err = json.Unmarshal(raw, &resp); err != nil {
-		return StatusMessage{}, err
-	}
-	return resp, nil
-}
-
 // TeamNotFound is an error returned if the given team was not found.
 var TeamNotFound = fmt.Errorf("team not found")
 
@@ -152,7 +152,7 @@ func Test_TeamPreferences(t *testing.T) {
 		Theme:           "dark",
 		HomeDashboardId: 0,
 		Timezone:        "UTC",
-	})
+	}.Preferences())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,68 +28,83 @@ type Row struct {
 	Height    Height  `json:"height"`
 	Panels    []Panel `json:"panels"`
 	Repeat    *string `json:"repeat"`
+
+	// board the row belongs to, see Board.bindRows()
+	board *Board
 }
 
-var lastPanelID uint
+// add appends the panel with the next ID of the board the row belongs to
+// or with the ID following the maximum ID of the row panels for rows
+// made without a board.
+func (r *Row) add(panel *Panel) {
+	if r.board == nil {
+		panel.ID = maxPanelID(r.Panels) + 1
+		r.Panels = append(r.Panels, *panel)
+		return
+	}
+	r.board.AddRowPanel(r, panel)
+}
 
+// Add appends the panel to the row. Rows of a board (made with
+// Board.AddRow() or decoded with the board) get IDs unique within the
+// whole board including panels of other rows.
 func (r *Row) Add(panel *Panel) {
-	lastPanelID++
-	panel.ID = lastPanelID
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddDashlist(data *DashlistPanel) {
-	lastPanelID++
 	panel := NewDashlist("")
-	panel.ID = lastPanelID
 	panel.DashlistPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddGraph(data *GraphPanel) {
-	lastPanelID++
 	panel := NewGraph("")
-	panel.ID = lastPanelID
 	panel.GraphPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddTable(data *TablePanel) {
-	lastPanelID++
 	panel := NewTable("")
-	panel.ID = lastPanelID
 	panel.TablePanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddText(data *TextPanel) {
-	lastPanelID++
 	panel := NewText("")
-	panel.ID = lastPanelID
 	panel.TextPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddStat(data *StatPanel) {
-	lastPanelID++
 	panel := NewStat("")
-	panel.ID = lastPanelID
 	panel.StatPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddSinglestat(data *SinglestatPanel) {
-	lastPanelID++
 	panel := NewSinglestat("")
-	panel.ID = lastPanelID
 	panel.SinglestatPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
 }
 
 func (r *Row) AddCustom(data *CustomPanel) {
-	lastPanelID++
 	panel := NewCustom("")
-	panel.ID = lastPanelID
 	panel.CustomPanel = data
-	r.Panels = append(r.Panels, *panel)
+	r.add(panel)
+}
+
+func maxPanelID(panels []Panel) uint {
+	var max uint
+	for _, p := range panels {
+		if p.ID > max {
+			max = p.ID
+		}
+		if p.RowPanel != nil {
+			if id := maxPanelID(p.RowPanel.Panels); id > max {
+				max = id
+			}
+		}
+	}
+	return max
 }
@@ -1,7 +1,147 @@
 package sdk
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // CreateSnapshotRequest is representation of a snapshot request.
+// Key and DeleteKey are generated by Grafana unless set. External
+// snapshots are stored on the external snapshot server configured in
+// Grafana (e.g. snapshots.raintank.io).
 type CreateSnapshotRequest struct {
-	Expires   uint  `json:"expires"`
-	Dashboard Board `json:"dashboard"`
+	Expires   uint   `json:"expires"`
+	Dashboard Board  `json:"dashboard"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key,omitempty"`
+	DeleteKey string `json:"deleteKey,omitempty"`
+	External  bool   `json:"external,omitempty"`
+}
+
+// CreateSnapshotResponse is the reply of Grafana on the snapshot creation.
+// It keeps the fields of StatusMessage returned before: URL is the link
+// to share. DeleteURL removes the snapshot without authentication.
+type CreateSnapshotResponse struct {
+	StatusMessage
+	Key       string `json:"key"`
+	DeleteKey string `json:"deleteKey"`
+	DeleteURL string `json:"deleteUrl"`
+}
+
+// Snapshot is an item of the snapshot listing.
+type Snapshot struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Key         string    `json:"key"`
+	OrgID       uint      `json:"orgId"`
+	UserID      uint      `json:"userId"`
+	External    bool      `json:"external"`
+	ExternalURL string    `json:"externalUrl"`
+	Expires     time.Time `json:"expires"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// SnapshotInfo marks the dashboard as a snapshot.
+type SnapshotInfo struct {
+	Timestamp   time.Time `json:"timestamp"`
+	OriginalURL string    `json:"originalUrl,omitempty"`
+}
+
+// MakeSnapshot returns a copy of the board prepared for a snapshot in the
+// same way as Grafana does on sharing a snapshot: data of the panels is
+// embedded as their SnapshotData, queries, datasources and panel links are
+// removed, enabled annotations keep their settings without queries,
+// variables keep only their current values and the time range becomes
+// the absolute one (unless from and to are zero). Data is keyed by panel
+// IDs and is usually a list of data frames. The board is not changed.
+func (b *Board) MakeSnapshot(from, to time.Time, data map[uint][]interface{}) (*Board, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = scrubAnnotations(raw); err != nil {
+		return nil, err
+	}
+	var snap Board
+	if err = json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+	snap.ID = 0
+	snap.Snapshot = &SnapshotInfo{Timestamp: time.Now().UTC()}
+	if !from.IsZero() && !to.IsZero() {
+		snap.Time = Time{From: from.UTC().Format(time.RFC3339Nano), To: to.UTC().Format(time.RFC3339Nano)}
+	}
+	scrub := func(p *Panel) {
+		p.Datasource = nil
+		p.Links = nil
+		p.SnapshotData = data[p.ID]
+		if targets := p.GetTargets(); targets != nil {
+			*targets = nil
+		}
+	}
+	for _, p := range snap.Panels {
+		scrub(p)
+		if p.RowPanel != nil {
+			for i := range p.RowPanel.Panels {
+				scrub(&p.RowPanel.Panels[i])
+			}
+		}
+	}
+	for _, r := range snap.Rows {
+		for i := range r.Panels {
+			scrub(&r.Panels[i])
+		}
+	}
+	for i := range snap.Templating.List {
+		v := &snap.Templating.List[i]
+		values, _ := currentValues(v)
+		v.Options = make([]Option, 0, len(values))
+		for _, value := range values {
+			v.Options = append(v.Options, Option{Text: value, Value: value, Selected: true})
+		}
+		v.Query = ""
+		v.SetRefresh(VariableRefreshNever)
+	}
+	return &snap, nil
+}
+
+// annotationQueryKeys are the keys of annotations that query their
+// datasources, snapshots have no access to them.
+var annotationQueryKeys = []string{"datasource", "query", "expr", "step", "target", "tags", "tagKeys"}
+
+// scrubAnnotations removes disabled annotations from the board JSON and
+// the query keys from the enabled ones. Other keys of the annotations
+// (e.g. "builtIn" or the ones unknown to Annotation) are kept.
+func scrubAnnotations(raw []byte) ([]byte, error) {
+	var (
+		board       map[string]json.RawMessage
+		annotations map[string]json.RawMessage
+		list        []map[string]json.RawMessage
+	)
+	if err := json.Unmarshal(raw, &board); err != nil {
+		return nil, err
+	}
+	if json.Unmarshal(board["annotations"], &annotations) != nil || json.Unmarshal(annotations["list"], &list) != nil {
+		return raw, nil
+	}
+	enabled := make([]map[string]json.RawMessage, 0, len(list))
+	for _, a := range list {
+		var enable bool
+		if json.Unmarshal(a["enable"], &enable) != nil || !enable {
+			continue
+		}
+		for _, k := range annotationQueryKeys {
+			delete(a, k)
+		}
+		enabled = append(enabled, a)
+	}
+	var err error
+	if annotations["list"], err = json.Marshal(enabled); err != nil {
+		return nil, err
+	}
+	if board["annotations"], err = json.Marshal(annotations); err != nil {
+		return nil, err
+	}
+	return json.Marshal(board)
 }
@@ -0,0 +1,109 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestBoard_MakeSnapshot(t *testing.T) {
+	b := sdk.NewBoard("Snapshot")
+	b.ID = 5
+	p := sdk.NewTimeseries("Requests")
+	p.Datasource = sdk.NewDatasourceRef("prometheus", "prom1")
+	p.AddTarget(&sdk.Target{Expr: "up"})
+	b.AddPanel(p)
+	empty := sdk.NewTimeseries("No data")
+	b.AddPanel(empty)
+	b.Annotations.List = []sdk.Annotation{
+		{Name: "Deploys", Enable: true, Datasource: sdk.NewDatasourceRef("loki", "loki1"), Expr: `{app="deploy"}`, Type: "dashboard"},
+		{Name: "Disabled", Enable: false},
+	}
+	job := sdk.NewQueryVariable("job", sdk.NewDatasourceRef("prometheus", "prom1"), "label_values(job)")
+	job.Current = sdk.Current{Value: []interface{}{"api", "web"}}
+	_ = b.AddVariable(job)
+
+	frame := map[string]interface{}{"schema": map[string]interface{}{"fields": []interface{}{}}}
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	snap, err := b.MakeSnapshot(from, from.Add(time.Hour), map[uint][]interface{}{p.ID: {frame}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Snapshot == nil || snap.ID != 0 {
+		t.Errorf("expected snapshot info and reset ID, got %+v and %d", snap.Snapshot, snap.ID)
+	}
+	if snap.Time.From != "2020-01-01T00:00:00Z" || snap.Time.To != "2020-01-01T01:00:00Z" {
+		t.Errorf("unexpected time range %+v", snap.Time)
+	}
+	sp := snap.Panels[0]
+	if sp.Datasource != nil || len(*sp.GetTargets()) != 0 || len(sp.SnapshotData) != 1 {
+		t.Errorf("unexpected snapshot panel %+v", sp.CommonPanel)
+	}
+	if len(snap.Panels[1].SnapshotData) != 0 {
+		t.Error("panel without data should have no snapshot data")
+	}
+	if len(snap.Annotations.List) != 1 || snap.Annotations.List[0].Datasource != nil {
+		t.Errorf("unexpected annotations %+v", snap.Annotations.List)
+	}
+	v := snap.Templating.List[0]
+	if v.Query != "" || len(v.Options) != 2 || v.Options[1].Value != "web" {
+		t.Errorf("unexpected variable %+v", v)
+	}
+	if b.ID != 5 || b.Panels[0].Datasource == nil || len(*b.Panels[0].GetTargets()) != 1 {
+		t.Error("original board should not be changed")
+	}
+
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"snapshot":{"timestamp":`, `"snapshotData":[{"schema"`} {
+		if !strings.Contains(string(raw), key) {
+			t.Errorf("expected %s in %s", key, raw)
+		}
+	}
+}
+
+func TestBoard_MakeSnapshot_KeepsAnnotationSettings(t *testing.T) {
+	var b sdk.Board
+	err := json.Unmarshal([]byte(`{"title": "Annotations", "annotations": {"list": [
+		{"builtIn": 1, "datasource": {"type": "grafana", "uid": "-- Grafana --"}, "enable": true, "hide": true,
+			"iconColor": "rgba(0, 211, 255, 1)", "name": "Annotations & Alerts", "type": "dashboard",
+			"target": {"limit": 100, "matchAny": false, "type": "dashboard"}},
+		{"datasource": {"type": "loki", "uid": "loki1"}, "enable": false, "expr": "{app=\"deploy\"}", "name": "Deploys"}
+	]}}`), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := b.MakeSnapshot(time.Time{}, time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Annotations struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"annotations"`
+	}
+	if err = json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Annotations.List) != 1 {
+		t.Fatalf("only the enabled annotation should be kept: %s", raw)
+	}
+	a := out.Annotations.List[0]
+	if a["builtIn"] != 1.0 || a["hide"] != true || a["iconColor"] != "rgba(0, 211, 255, 1)" || a["name"] != "Annotations & Alerts" {
+		t.Errorf("annotation settings should be kept: %v", a)
+	}
+	for _, key := range []string{"datasource", "target", "expr"} {
+		if _, ok := a[key]; ok {
+			t.Errorf("query key %q should be removed: %v", key, a)
+		}
+	}
+}
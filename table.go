@@ -0,0 +1,334 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// IsLegacy reports whether the table uses the Angular table model of
+// Grafana < 7 (columns, styles, transform) rather than the options and
+// fieldConfig of the current table panel. An empty table is reported as
+// a legacy one because it has no Grafana 7 options at all.
+func (t *TablePanel) IsLegacy() bool {
+	if len(t.Styles) > 0 || len(t.Columns) > 0 || t.Transform != "" {
+		return true
+	}
+	return t.Options == nil && t.FieldConfig == nil
+}
+
+// IsLegacyTable reports whether the panel is a table of the legacy
+// Angular generation. It is true for "table-old" panels and for "table"
+// panels that still keep the legacy styles.
+func (p *Panel) IsLegacyTable() bool {
+	if p.OfType != TableType || p.TablePanel == nil {
+		return false
+	}
+	return p.Type == "table-old" || p.TablePanel.IsLegacy()
+}
+
+// StylesToFieldConfig converts legacy column styles to the field config
+// of the Grafana 7 table. A style with the "/.*/" pattern becomes the
+// defaults, other styles become overrides matched by name or by regexp.
+// The table itself is not changed.
+func (t *TablePanel) StylesToFieldConfig() FieldConfig {
+	var fc FieldConfig
+	if t.FieldConfig != nil {
+		fc = *t.FieldConfig
+	}
+	for _, style := range t.Styles {
+		props := styleToProperties(style)
+		if style.Pattern == "/.*/" || style.Pattern == "" {
+			applyDefaultProperties(&fc.Defaults, props)
+			continue
+		}
+		if len(props) == 0 {
+			continue
+		}
+		matcher := MatchFieldsByName(style.Pattern)
+		if isRegexPattern(style.Pattern) {
+			matcher = MatchFieldsByRegexp(style.Pattern)
+		}
+		fc.Overrides = append(fc.Overrides, FieldConfigOverride{Matcher: matcher, Properties: props})
+	}
+	return fc
+}
+
+// legacyTableTransforms maps legacy table transforms to transformations
+// in the same way as Grafana does on the table migration.
+var legacyTableTransforms = map[string]string{
+	"timeseries_to_rows":      TransformationSeriesToRows,
+	"timeseries_to_columns":   TransformationSeriesToColumns,
+	"timeseries_aggregations": TransformationReduce,
+	"table":                   TransformationMerge,
+}
+
+// legacyTableReducers maps columns of "timeseries_aggregations" transform
+// to reducers.
+var legacyTableReducers = map[string]string{
+	"avg":     "mean",
+	"min":     "min",
+	"max":     "max",
+	"total":   "sum",
+	"current": "last",
+	"count":   "count",
+}
+
+// MigrateLegacyTable converts a legacy table panel to the Grafana 7 table
+// in place: styles are converted with StylesToFieldConfig() and dropped,
+// the known transform with its columns is replaced by a transformation,
+// the header option is enabled and "table-old" type is renamed to
+// "table". Unknown transform (e.g. "json") is kept as is.
+func (p *Panel) MigrateLegacyTable() error {
+	if p.OfType != TableType || p.TablePanel == nil {
+		return errors.New("panel is not a table")
+	}
+	if !p.IsLegacyTable() {
+		return nil
+	}
+	fc := p.TablePanel.StylesToFieldConfig()
+	p.TablePanel.FieldConfig = &fc
+	p.TablePanel.Styles = nil
+	if id, ok := legacyTableTransforms[p.TablePanel.Transform]; ok {
+		t := NewTransformation(id)
+		if id == TransformationReduce {
+			reducers := []string{}
+			for _, col := range p.TablePanel.Columns {
+				if reducer, ok := legacyTableReducers[col.Value]; ok {
+					reducers = append(reducers, reducer)
+				}
+			}
+			t = NewReduceTransformation(reducers...)
+		}
+		p.AddTransformation(t)
+		p.TablePanel.Transform = ""
+		p.TablePanel.Columns = nil
+	}
+	if p.TablePanel.Options == nil {
+		p.TablePanel.Options = &TableOptions{ShowHeader: true}
+	}
+	// The Grafana 7 table is the same plugin, its version still applies.
+	p.retype("table", "pluginVersion")
+	return nil
+}
+
+// grafana7TablePanel is the table panel of Grafana 7+ as it is
+// marshalled: the keys of the legacy table are omitted while they are not
+// set and the field config is written as tableFieldConfig.
+type grafana7TablePanel struct {
+	TablePanel
+	Columns     []Column          `json:"columns,omitempty"`
+	Styles      []ColumnStyle     `json:"styles,omitempty"`
+	Transform   string            `json:"transform,omitempty"`
+	Scroll      bool              `json:"scroll,omitempty"`
+	FieldConfig *tableFieldConfig `json:"fieldConfig,omitempty"`
+}
+
+func newGrafana7TablePanel(t TablePanel) grafana7TablePanel {
+	return grafana7TablePanel{
+		TablePanel:  t,
+		Columns:     t.Columns,
+		Styles:      t.Styles,
+		Transform:   t.Transform,
+		Scroll:      t.Scroll,
+		FieldConfig: newTableFieldConfig(t.FieldConfig),
+	}
+}
+
+// tableFieldConfig is the field config of the table panel as it is
+// marshalled. Color, thresholds and custom options are omitted while they
+// are not set and only the custom options of the table are written
+// instead of the ones of the time series panel.
+type tableFieldConfig struct {
+	Defaults  tableFieldDefaults    `json:"defaults"`
+	Overrides []FieldConfigOverride `json:"overrides,omitempty"`
+}
+
+type tableFieldDefaults struct {
+	FieldConfigDefaults
+	Color      *FieldConfigColor `json:"color,omitempty"`
+	Thresholds *Thresholds       `json:"thresholds,omitempty"`
+	Custom     *tableFieldCustom `json:"custom,omitempty"`
+}
+
+type tableFieldCustom struct {
+	Align       string `json:"align,omitempty"`
+	DisplayMode string `json:"displayMode,omitempty"`
+	Width       *int   `json:"width,omitempty"`
+	MinWidth    *int   `json:"minWidth,omitempty"`
+	Filterable  bool   `json:"filterable,omitempty"`
+	Inspect     bool   `json:"inspect,omitempty"`
+}
+
+func newTableFieldConfig(fc *FieldConfig) *tableFieldConfig {
+	if fc == nil {
+		return nil
+	}
+	d := fc.Defaults
+	result := &tableFieldConfig{
+		Defaults:  tableFieldDefaults{FieldConfigDefaults: d},
+		Overrides: fc.Overrides,
+	}
+	if d.Color != (FieldConfigColor{}) {
+		result.Defaults.Color = &d.Color
+	}
+	if d.Thresholds.Mode != "" || len(d.Thresholds.Steps) > 0 {
+		result.Defaults.Thresholds = &d.Thresholds
+	}
+	custom := tableFieldCustom{
+		Align:       d.Custom.Align,
+		DisplayMode: d.Custom.DisplayMode,
+		Width:       d.Custom.Width,
+		MinWidth:    d.Custom.MinWidth,
+		Filterable:  d.Custom.Filterable,
+		Inspect:     d.Custom.Inspect,
+	}
+	if custom != (tableFieldCustom{}) {
+		result.Defaults.Custom = &custom
+	}
+	return result
+}
+
+func styleToProperties(style ColumnStyle) []FieldOverrideProperty {
+	var props []FieldOverrideProperty
+	add := func(id string, value interface{}) {
+		props = append(props, FieldOverrideProperty{ID: id, Value: value})
+	}
+	if style.Alias != nil && *style.Alias != "" {
+		add("displayName", *style.Alias)
+	}
+	switch style.Type {
+	case "date":
+		if style.DateFormat != nil && *style.DateFormat != "" {
+			add("unit", "time: "+*style.DateFormat)
+		}
+	case "hidden":
+		add("custom.hidden", true)
+	default:
+		if style.Unit != nil && *style.Unit != "" {
+			add("unit", *style.Unit)
+		}
+		if style.Decimals != nil {
+			add("decimals", *style.Decimals)
+		}
+	}
+	if style.Align != "" {
+		add("custom.align", style.Align)
+	}
+	if style.ColorMode != nil {
+		switch *style.ColorMode {
+		case "cell":
+			add("custom.displayMode", "color-background")
+		case "value":
+			add("custom.displayMode", "color-text")
+		}
+	}
+	if style.Colors != nil && style.Thresholds != nil {
+		add("thresholds", stylesToThresholds(*style.Colors, *style.Thresholds))
+	}
+	if len(style.ValueMaps) > 0 {
+		add("mappings", valueMapsToMappings(style.ValueMaps))
+	}
+	if style.Link && style.LinkUrl != nil {
+		link := Link{Type: "link", URL: style.LinkUrl}
+		if style.LinkTooltip != nil {
+			link.Title = *style.LinkTooltip
+		}
+		if style.LinkTargetBlank {
+			blank := true
+			link.TargetBlank = &blank
+		}
+		add("links", []Link{link})
+	}
+	return props
+}
+
+// stylesToThresholds builds absolute threshold steps in the same way as
+// Grafana does on the table migration: the first color is the base step
+// and each threshold value starts the step of the next color.
+func stylesToThresholds(colors, thresholds []string) Thresholds {
+	result := Thresholds{Mode: "absolute"}
+	for i, color := range colors {
+		if i == 0 {
+			result.Steps = append(result.Steps, ThresholdStep{Color: color})
+			continue
+		}
+		if i > len(thresholds) {
+			break
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(thresholds[i-1]), 64)
+		if err != nil {
+			continue
+		}
+		result.Steps = append(result.Steps, ThresholdStep{Color: color, Value: &value})
+	}
+	return result
+}
+
+// valueMapsToMappings converts legacy value maps to a single value mapping.
+// The "null" value of legacy maps becomes a special mapping.
+func valueMapsToMappings(valueMaps []ValueMap) []ValueMapping {
+	var (
+		mappings []ValueMapping
+		values   = make(map[string]ValueMappingResult)
+	)
+	for i, vm := range valueMaps {
+		result := ValueMappingResult{Text: vm.TextType, Index: i}
+		if vm.Value == "null" {
+			mappings = append(mappings, NewSpecialMapping(SpecialMatchNull, result))
+			continue
+		}
+		values[vm.Value] = result
+	}
+	if len(values) > 0 {
+		mappings = append([]ValueMapping{NewValueMapping(values)}, mappings...)
+	}
+	return mappings
+}
+
+func applyDefaultProperties(d *FieldConfigDefaults, props []FieldOverrideProperty) {
+	for _, prop := range props {
+		switch prop.ID {
+		case "displayName":
+			d.DisplayName = prop.Value.(string)
+		case "unit":
+			d.Unit = prop.Value.(string)
+		case "decimals":
+			decimals := prop.Value.(int)
+			d.Decimals = &decimals
+		case "thresholds":
+			d.Thresholds = prop.Value.(Thresholds)
+		case "links":
+			d.Links = append(d.Links, prop.Value.([]Link)...)
+		case "mappings":
+			d.Mappings = append(d.Mappings, prop.Value.([]ValueMapping)...)
+		case "custom.align":
+			d.Custom.Align = prop.Value.(string)
+		case "custom.displayMode":
+			d.Custom.DisplayMode = prop.Value.(string)
+		}
+	}
+}
+
+func isRegexPattern(pattern string) bool {
+	return len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.LastIndex(pattern, "/") > 0
+}
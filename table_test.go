@@ -0,0 +1,326 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestTablePanel_Modern(t *testing.T) {
+	var rawPanel = []byte(`{
+		"id": 3,
+		"type": "table",
+		"title": "Modern table",
+		"options": {
+			"showHeader": true,
+			"cellHeight": "sm",
+			"footer": {
+				"show": true,
+				"reducer": ["sum"],
+				"countRows": false
+			},
+			"sortBy": [{"displayName": "Value", "desc": true}]
+		},
+		"fieldConfig": {
+			"defaults": {
+				"unit": "short",
+				"custom": {
+					"align": "auto",
+					"displayMode": "color-text",
+					"width": 120,
+					"filterable": true
+				},
+				"color": {"mode": "thresholds"},
+				"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}]}
+			},
+			"overrides": [
+				{
+					"matcher": {"id": "byName", "options": "Value"},
+					"properties": [{"id": "custom.width", "value": 80}]
+				}
+			]
+		},
+		"targets": [{"refId": "A", "expr": "up"}]
+	}`)
+	var panel sdk.Panel
+	if err := json.Unmarshal(rawPanel, &panel); err != nil {
+		t.Fatal(err)
+	}
+	if panel.IsLegacyTable() {
+		t.Fatal("should be detected as a modern table")
+	}
+
+	out, err := json.Marshal(&panel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back sdk.Panel
+	if err = json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	opts := back.TablePanel.Options
+	if opts == nil || !opts.ShowHeader || opts.CellHeight != "sm" {
+		t.Fatalf("table options lost on round trip: %s", out)
+	}
+	if opts.Footer == nil || !opts.Footer.Show || len(opts.Footer.Reducer) != 1 {
+		t.Fatalf("table footer lost on round trip: %s", out)
+	}
+	if len(opts.SortBy) != 1 || !opts.SortBy[0].Desc {
+		t.Fatalf("table sorting lost on round trip: %s", out)
+	}
+	custom := back.TablePanel.FieldConfig.Defaults.Custom
+	if custom.Align != "auto" || custom.DisplayMode != "color-text" || custom.Width == nil || *custom.Width != 120 || !custom.Filterable {
+		t.Fatalf("custom field config lost on round trip: %s", out)
+	}
+	overrides := back.TablePanel.FieldConfig.Overrides
+	if len(overrides) != 1 || overrides[0].Matcher.ID != "byName" || overrides[0].Properties[0].ID != "custom.width" {
+		t.Fatalf("overrides lost on round trip: %s", out)
+	}
+
+	var plain map[string]interface{}
+	if err = json.Unmarshal(out, &plain); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"styles", "columns", "transform"} {
+		if _, ok := plain[key]; ok {
+			t.Errorf("legacy key %q should not be set for a modern table", key)
+		}
+	}
+}
+
+func TestTablePanel_LegacyDetection(t *testing.T) {
+	var rawPanel = []byte(`{
+		"id": 4,
+		"type": "table-old",
+		"title": "Legacy table",
+		"columns": [],
+		"styles": [{"pattern": "/.*/", "type": "number", "unit": "short"}],
+		"transform": "timeseries_to_rows"
+	}`)
+	var panel sdk.Panel
+	if err := json.Unmarshal(rawPanel, &panel); err != nil {
+		t.Fatal(err)
+	}
+	if panel.OfType != sdk.TableType {
+		t.Fatalf("table-old should be parsed as a table")
+	}
+	if !panel.IsLegacyTable() {
+		t.Fatal("should be detected as a legacy table")
+	}
+	if sdk.NewGraph("").IsLegacyTable() {
+		t.Fatal("graph is not a table")
+	}
+}
+
+func TestPanel_MigrateLegacyTable(t *testing.T) {
+	var (
+		alias     = "Status"
+		colorMode = "cell"
+		colors    = []string{"green", "orange", "red"}
+		limits    = []string{"50", "80"}
+		unit      = "percent"
+		decimals  = 2
+		dateFmt   = "YYYY-MM-DD"
+		linkURL   = "http://example.com/${__cell}"
+	)
+	table := sdk.NewTable("Legacy")
+	table.TablePanel.Transform = "timeseries_aggregations"
+	table.TablePanel.Columns = []sdk.Column{{TextType: "Avg", Value: "avg"}, {TextType: "Current", Value: "current"}}
+	table.TablePanel.Styles = []sdk.ColumnStyle{
+		{Pattern: "/.*/", Type: "number", Unit: &unit, Decimals: &decimals},
+		{Pattern: "status", Type: "number", Alias: &alias, ColorMode: &colorMode, Colors: &colors, Thresholds: &limits,
+			Link: true, LinkUrl: &linkURL, LinkTargetBlank: true,
+			ValueMaps: []sdk.ValueMap{{Op: "=", TextType: "OK", Value: "1"}, {Op: "=", TextType: "N/A", Value: "null"}}},
+		{Pattern: "/time.*/", Type: "date", DateFormat: &dateFmt},
+		{Pattern: "secret", Type: "hidden"},
+	}
+
+	if err := table.MigrateLegacyTable(); err != nil {
+		t.Fatal(err)
+	}
+	if table.IsLegacyTable() {
+		t.Fatal("table should not be legacy after migration")
+	}
+	if table.TablePanel.Styles != nil {
+		t.Error("styles should be dropped")
+	}
+	if table.TablePanel.Options == nil || !table.TablePanel.Options.ShowHeader {
+		t.Error("header should be shown")
+	}
+	if len(table.Transformations) != 1 || table.Transformations[0].ID != sdk.TransformationReduce {
+		t.Fatalf("transform should become reduce transformation, got %+v", table.Transformations)
+	}
+	if reducers := table.Transformations[0].Options["reducers"]; !reflect.DeepEqual(reducers, []string{"mean", "last"}) {
+		t.Errorf("columns should become reducers, got %v", reducers)
+	}
+	if table.TablePanel.Transform != "" || table.TablePanel.Columns != nil {
+		t.Error("transform and columns should be dropped")
+	}
+
+	fc := table.TablePanel.FieldConfig
+	if fc.Defaults.Unit != unit || fc.Defaults.Decimals == nil || *fc.Defaults.Decimals != decimals {
+		t.Errorf("defaults should be taken from /.*/ style, got %+v", fc.Defaults)
+	}
+	if len(fc.Overrides) != 3 {
+		t.Fatalf("should be 3 overrides but got %d", len(fc.Overrides))
+	}
+
+	status := fc.Overrides[0]
+	if status.Matcher.ID != sdk.FieldMatcherByName || status.Matcher.Options != "status" {
+		t.Errorf("unexpected matcher %+v", status.Matcher)
+	}
+	props := make(map[string]interface{})
+	for _, p := range status.Properties {
+		props[p.ID] = p.Value
+	}
+	if props["displayName"] != alias {
+		t.Errorf("alias should become displayName, got %v", props["displayName"])
+	}
+	if props["custom.displayMode"] != "color-background" {
+		t.Errorf("cell color mode should become color-background, got %v", props["custom.displayMode"])
+	}
+	thresholds, ok := props["thresholds"].(sdk.Thresholds)
+	if !ok || len(thresholds.Steps) != 3 {
+		t.Fatalf("thresholds should have 3 steps, got %+v", props["thresholds"])
+	}
+	if thresholds.Steps[0].Value != nil || *thresholds.Steps[2].Value != 80 || thresholds.Steps[2].Color != "red" {
+		t.Errorf("unexpected threshold steps %+v", thresholds.Steps)
+	}
+	if mappings, ok := props["mappings"].([]sdk.ValueMapping); !ok || len(mappings) != 2 ||
+		mappings[0].ValueOptions["1"].Text != "OK" || mappings[1].SpecialOptions.Match != sdk.SpecialMatchNull {
+		t.Errorf("value maps should become mappings, got %+v", props["mappings"])
+	}
+	if links, ok := props["links"].([]sdk.Link); !ok || *links[0].URL != linkURL || !*links[0].TargetBlank {
+		t.Errorf("link should be converted, got %+v", props["links"])
+	}
+
+	if fc.Overrides[1].Matcher.ID != sdk.FieldMatcherByRegexp {
+		t.Errorf("regexp pattern should use byRegexp matcher, got %s", fc.Overrides[1].Matcher.ID)
+	}
+	if fc.Overrides[1].Properties[0].Value != "time: "+dateFmt {
+		t.Errorf("date format should become time unit, got %v", fc.Overrides[1].Properties[0].Value)
+	}
+	if fc.Overrides[2].Properties[0].ID != "custom.hidden" {
+		t.Errorf("hidden style should hide the field, got %v", fc.Overrides[2].Properties[0].ID)
+	}
+}
+
+func TestPanel_MigrateLegacyTable_OmitsUnsetDefaults(t *testing.T) {
+	var rawPanel = []byte(`{
+		"id": 2,
+		"type": "table-old",
+		"title": "Legacy",
+		"styles": [{"pattern": "/.*/", "type": "number", "unit": "short", "align": "right"}],
+		"targets": [{"refId": "A", "expr": "up"}]
+	}`)
+	var panel sdk.Panel
+	if err := json.Unmarshal(rawPanel, &panel); err != nil {
+		t.Fatal(err)
+	}
+	if err := panel.MigrateLegacyTable(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(&panel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain struct {
+		FieldConfig struct {
+			Defaults map[string]interface{} `json:"defaults"`
+		} `json:"fieldConfig"`
+	}
+	if err = json.Unmarshal(out, &plain); err != nil {
+		t.Fatal(err)
+	}
+	defaults := plain.FieldConfig.Defaults
+	for _, key := range []string{"color", "thresholds"} {
+		if _, ok := defaults[key]; ok {
+			t.Errorf("unset %q should be omitted: %s", key, out)
+		}
+	}
+	custom, ok := defaults["custom"].(map[string]interface{})
+	if !ok || len(custom) != 1 || custom["align"] != "right" {
+		t.Errorf("custom should keep only the table options: %s", out)
+	}
+	if defaults["unit"] != "short" {
+		t.Errorf("unit should be kept: %s", out)
+	}
+}
+
+func TestPanel_MigrateLegacyTable_KeepsUnknownKeys(t *testing.T) {
+	var rawPanel = []byte(`{
+		"id": 2,
+		"type": "table-old",
+		"title": "Legacy",
+		"pluginVersion": "6.7.4",
+		"maxDataPoints": 100,
+		"interval": "1m",
+		"styles": [{"pattern": "/.*/", "type": "number", "unit": "short"}],
+		"columns": [],
+		"transform": "timeseries_to_rows",
+		"targets": [{"refId": "A", "expr": "up"}]
+	}`)
+	var panel sdk.Panel
+	if err := json.Unmarshal(rawPanel, &panel); err != nil {
+		t.Fatal(err)
+	}
+	if err := panel.MigrateLegacyTable(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(&panel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain map[string]interface{}
+	if err = json.Unmarshal(out, &plain); err != nil {
+		t.Fatal(err)
+	}
+	if plain["pluginVersion"] != "6.7.4" || plain["maxDataPoints"] != 100.0 || plain["interval"] != "1m" {
+		t.Errorf("unknown keys should be kept: %s", out)
+	}
+	if plain["type"] != "table" {
+		t.Errorf("type should be table: %s", out)
+	}
+	for _, key := range []string{"styles", "columns", "transform", "scroll"} {
+		if _, ok := plain[key]; ok {
+			t.Errorf("legacy key %q should be dropped: %s", key, out)
+		}
+	}
+}
+
+func TestTablePanel_LegacyKeys(t *testing.T) {
+	out, err := json.Marshal(sdk.NewTable("Legacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain map[string]interface{}
+	if err = json.Unmarshal(out, &plain); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"columns", "styles", "transform", "scroll"} {
+		if _, ok := plain[key]; !ok {
+			t.Errorf("legacy table should have %q key: %s", key, out)
+		}
+	}
+}
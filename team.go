@@ -44,8 +44,21 @@ type TeamMember struct {
 	AvatarUrl string `json:"avatarUrl"`
 }
 
+// TeamPreferences are preferences of the team.
+// Deprecated: use Preferences, the same structure is used for users,
+// teams and organizations, see TeamPreferences.Preferences().
 type TeamPreferences struct {
 	Theme           string `json:"theme"`
 	HomeDashboardId int    `json:"homeDashboardId"`
 	Timezone        string `json:"timezone"`
 }
+
+// Preferences converts the team preferences for GetTeamPreferences and
+// UpdateTeamPreferences.
+func (tp TeamPreferences) Preferences() Preferences {
+	prefs := Preferences{Theme: tp.Theme, Timezone: tp.Timezone}
+	if tp.HomeDashboardId > 0 {
+		prefs.HomeDashboardId = uint(tp.HomeDashboardId)
+	}
+	return prefs
+}
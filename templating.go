@@ -0,0 +1,218 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types of the template variables.
+const (
+	VariableTypeQuery      = "query"
+	VariableTypeCustom     = "custom"
+	VariableTypeInterval   = "interval"
+	VariableTypeDatasource = "datasource"
+	VariableTypeConstant   = "constant"
+	VariableTypeTextbox    = "textbox"
+	VariableTypeAdhoc      = "adhoc"
+)
+
+// VariableRefresh defines when Grafana updates options of a query or
+// datasource variable.
+type VariableRefresh int64
+
+const (
+	VariableRefreshNever VariableRefresh = iota
+	VariableRefreshOnDashboardLoad
+	VariableRefreshOnTimeRangeChange
+)
+
+// VariableSort defines the order of the options of a query variable.
+type VariableSort int
+
+const (
+	VariableSortDisabled VariableSort = iota
+	VariableSortAlphabeticalAsc
+	VariableSortAlphabeticalDesc
+	VariableSortNumericalAsc
+	VariableSortNumericalDesc
+	VariableSortAlphabeticalCaseInsensitiveAsc
+	VariableSortAlphabeticalCaseInsensitiveDesc
+)
+
+// AutoIntervalValue is the option value Grafana uses for the "auto" step of
+// an interval variable with the given name.
+func AutoIntervalValue(name string) string {
+	return "$__auto_interval_" + name
+}
+
+// NewQueryVariable initializes a variable that gets its options from the
+// datasource query. Options are refreshed on dashboard load, use
+// SetRefresh to change it.
+func NewQueryVariable(name string, datasource *DatasourceRef, query string) TemplateVar {
+	v := newVariable(name, VariableTypeQuery)
+	v.Datasource = datasource
+	v.Query = query
+	v.Definition = query
+	v.SetRefresh(VariableRefreshOnDashboardLoad)
+	return v
+}
+
+// NewCustomVariable initializes a variable with the fixed list of values.
+// The first value becomes the current one.
+func NewCustomVariable(name string, values ...string) TemplateVar {
+	v := newVariable(name, VariableTypeCustom)
+	v.Query = strings.Join(values, ",")
+	v.setOptions(values)
+	return v
+}
+
+// NewIntervalVariable initializes a variable with the list of time
+// intervals, e.g. "1m", "10m", "1h". The first interval becomes the
+// current one. Options are refreshed on time range change as Grafana does.
+func NewIntervalVariable(name string, intervals ...string) TemplateVar {
+	v := newVariable(name, VariableTypeInterval)
+	v.Query = strings.Join(intervals, ",")
+	v.setOptions(intervals)
+	v.SetRefresh(VariableRefreshOnTimeRangeChange)
+	autoCount := 30
+	v.AutoCount = &autoCount
+	v.AutoMin = "10s"
+	return v
+}
+
+// NewDatasourceVariable initializes a variable that lists all datasources
+// of the plugin type, e.g. "prometheus".
+func NewDatasourceVariable(name, pluginType string) TemplateVar {
+	v := newVariable(name, VariableTypeDatasource)
+	v.Query = pluginType
+	v.SetRefresh(VariableRefreshOnDashboardLoad)
+	return v
+}
+
+// NewConstantVariable initializes a hidden variable with the fixed value.
+func NewConstantVariable(name, value string) TemplateVar {
+	v := newVariable(name, VariableTypeConstant)
+	v.Query = value
+	v.Hide = TemplatingHideVariable
+	v.setCurrent(value)
+	return v
+}
+
+// NewTextboxVariable initializes a variable with the free text input and
+// the default value.
+func NewTextboxVariable(name, defaultValue string) TemplateVar {
+	v := newVariable(name, VariableTypeTextbox)
+	v.Query = defaultValue
+	v.setOptions([]string{defaultValue})
+	return v
+}
+
+// NewAdhocVariable initializes a variable with ad hoc filters applied to
+// all queries of the datasource.
+func NewAdhocVariable(name string, datasource *DatasourceRef, filters ...AdhocFilter) TemplateVar {
+	v := newVariable(name, VariableTypeAdhoc)
+	v.Datasource = datasource
+	v.Filters = append([]AdhocFilter{}, filters...)
+	return v
+}
+
+func newVariable(name, varType string) TemplateVar {
+	return TemplateVar{
+		Name:    name,
+		Type:    varType,
+		Options: []Option{},
+	}
+}
+
+// SetRefresh sets when Grafana updates the variable options.
+func (v *TemplateVar) SetRefresh(refresh VariableRefresh) {
+	value := int64(refresh)
+	v.Refresh = BoolInt{Value: &value}
+}
+
+// SetSort sets the order of the query variable options.
+func (v *TemplateVar) SetSort(sort VariableSort) {
+	v.Sort = int(sort)
+}
+
+// SetMulti allows to select several values of the variable. If allValue
+// is not empty the "All" option is added with this value, pass "" to let
+// Grafana build it from all the options.
+func (v *TemplateVar) SetMulti(includeAll bool, allValue string) {
+	v.Multi = true
+	v.IncludeAll = includeAll
+	v.AllValue = allValue
+}
+
+func (v *TemplateVar) setOptions(values []string) {
+	v.Options = make([]Option, 0, len(values))
+	for i, value := range values {
+		v.Options = append(v.Options, Option{Text: value, Value: value, Selected: i == 0})
+	}
+	if len(values) > 0 {
+		v.setCurrent(values[0])
+	}
+}
+
+func (v *TemplateVar) setCurrent(value string) {
+	v.Current = Current{
+		Text:  &StringSliceString{Value: []string{value}, Valid: true},
+		Value: value,
+	}
+}
+
+// AddVariable appends the variable to the board templating. Grafana
+// requires variable names to be unique within the dashboard so an error
+// is returned if the board already has a variable with the same name.
+func (b *Board) AddVariable(v TemplateVar) error {
+	if v.Name == "" {
+		return fmt.Errorf("variable name is empty")
+	}
+	if _, ok := b.GetVariable(v.Name); ok {
+		return fmt.Errorf("variable %q already exists", v.Name)
+	}
+	b.Templating.List = append(b.Templating.List, v)
+	return nil
+}
+
+// GetVariable looks up the board variable by name. The returned pointer
+// refers to the variable in the board so it may be changed in place.
+func (b *Board) GetVariable(name string) (*TemplateVar, bool) {
+	for i := range b.Templating.List {
+		if b.Templating.List[i].Name == name {
+			return &b.Templating.List[i], true
+		}
+	}
+	return nil, false
+}
+
+// RemoveVariable removes the board variable by name. It reports whether
+// the variable was found.
+func (b *Board) RemoveVariable(name string) bool {
+	for i, v := range b.Templating.List {
+		if v.Name == name {
+			b.Templating.List = append(b.Templating.List[:i], b.Templating.List[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func loadVariables(t *testing.T, fixture string) map[string]sdk.TemplateVar {
+	t.Helper()
+	raw, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var board sdk.Board
+	if err = json.Unmarshal(raw, &board); err != nil {
+		t.Fatal(err)
+	}
+	vars := make(map[string]sdk.TemplateVar)
+	for _, v := range board.Templating.List {
+		vars[v.Name] = v
+	}
+	return vars
+}
+
+func checkVariable(t *testing.T, got, exp sdk.TemplateVar) {
+	t.Helper()
+	if got.Type != exp.Type {
+		t.Errorf("%s: expected type %q, got %q", exp.Name, exp.Type, got.Type)
+	}
+	if !reflect.DeepEqual(got.Refresh, exp.Refresh) {
+		t.Errorf("%s: expected refresh %v, got %v", exp.Name, exp.Refresh, got.Refresh)
+	}
+	if got.Hide != exp.Hide {
+		t.Errorf("%s: expected hide %d, got %d", exp.Name, exp.Hide, got.Hide)
+	}
+	if got.Sort != exp.Sort {
+		t.Errorf("%s: expected sort %d, got %d", exp.Name, exp.Sort, got.Sort)
+	}
+	if got.Multi != exp.Multi || got.IncludeAll != exp.IncludeAll {
+		t.Errorf("%s: expected multi=%v includeAll=%v, got multi=%v includeAll=%v",
+			exp.Name, exp.Multi, exp.IncludeAll, got.Multi, got.IncludeAll)
+	}
+	if !reflect.DeepEqual(got.Datasource, exp.Datasource) {
+		t.Errorf("%s: expected datasource %v, got %v", exp.Name, exp.Datasource, got.Datasource)
+	}
+	if got.Definition != exp.Definition || got.Regex != exp.Regex {
+		t.Errorf("%s: expected definition %q regex %q, got %q %q",
+			exp.Name, exp.Definition, exp.Regex, got.Definition, got.Regex)
+	}
+	if !reflect.DeepEqual(got.AutoCount, exp.AutoCount) || got.AutoMin != exp.AutoMin || got.Auto != exp.Auto {
+		t.Errorf("%s: expected auto %v/%v/%q, got %v/%v/%q", exp.Name,
+			exp.Auto, exp.AutoCount, exp.AutoMin, got.Auto, got.AutoCount, got.AutoMin)
+	}
+	if len(exp.Options) > 0 && !reflect.DeepEqual(got.Options, exp.Options) {
+		t.Errorf("%s: expected options %v, got %v", exp.Name, exp.Options, got.Options)
+	}
+	if !reflect.DeepEqual(got.Filters, exp.Filters) && len(got.Filters)+len(exp.Filters) > 0 {
+		t.Errorf("%s: expected filters %v, got %v", exp.Name, exp.Filters, got.Filters)
+	}
+	// current value of query and datasource variables is resolved by Grafana
+	if exp.Current.Text != nil && exp.Type != "query" && exp.Type != "datasource" {
+		if got.Current.Text == nil || !reflect.DeepEqual(got.Current.Text.Value, exp.Current.Text.Value) {
+			t.Errorf("%s: expected current %v, got %v", exp.Name, exp.Current.Text, got.Current.Text)
+		}
+	}
+	if _, isObject := exp.Query.(map[string]interface{}); !isObject && got.Query != exp.Query {
+		t.Errorf("%s: expected query %v, got %v", exp.Name, exp.Query, got.Query)
+	}
+}
+
+func TestVariableBuilders_Grafana8(t *testing.T) {
+	vars := loadVariables(t, "testdata/templating-all-types-8.2.json")
+
+	checkVariable(t, sdk.NewDatasourceVariable("datasource", "prometheus"), vars["datasource"])
+	checkVariable(t, sdk.NewQueryVariable("job", sdk.NewDatasourceRefByName("${datasource}"), "label_values(up, job)"), vars["job"])
+	checkVariable(t, sdk.NewCustomVariable("env", "prod", "dev"), vars["env"])
+	interval := sdk.NewIntervalVariable("interval", "5m", "1h")
+	checkVariable(t, interval, vars["interval"])
+	checkVariable(t, sdk.NewConstantVariable("cluster", "eu-west-1"), vars["cluster"])
+	checkVariable(t, sdk.NewTextboxVariable("filter", ""), vars["filter"])
+	checkVariable(t, sdk.NewAdhocVariable("Filters", sdk.NewDatasourceRefByName("${datasource}")), vars["Filters"])
+}
+
+func TestVariableBuilders_Grafana9(t *testing.T) {
+	vars := loadVariables(t, "testdata/templating-all-types-9.3.json")
+	ds := sdk.NewDatasourceRef("prometheus", "${datasource}")
+
+	checkVariable(t, sdk.NewDatasourceVariable("datasource", "prometheus"), vars["datasource"])
+
+	job := sdk.NewQueryVariable("job", ds, "label_values(up, job)")
+	job.Regex = "/(.*)-exporter/"
+	job.SetRefresh(sdk.VariableRefreshOnTimeRangeChange)
+	job.SetSort(sdk.VariableSortAlphabeticalAsc)
+	job.SetMulti(true, "")
+	checkVariable(t, job, vars["job"])
+
+	checkVariable(t, sdk.NewCustomVariable("env", "prod", "staging", "dev"), vars["env"])
+
+	interval := sdk.NewIntervalVariable("interval", "1m", "10m", "1h")
+	interval.Auto = true
+	interval.Options = append([]sdk.Option{{Text: "auto", Value: sdk.AutoIntervalValue("interval")}}, interval.Options...)
+	checkVariable(t, interval, vars["interval"])
+
+	checkVariable(t, sdk.NewConstantVariable("cluster", "eu-west-1"), vars["cluster"])
+	checkVariable(t, sdk.NewTextboxVariable("filter", ".*"), vars["filter"])
+	checkVariable(t, sdk.NewAdhocVariable("Filters", ds,
+		sdk.AdhocFilter{Key: "instance", Operator: "=", Value: "localhost:9090"}), vars["Filters"])
+}
+
+func TestVariableBuilders_MarshalledByGrafanaNames(t *testing.T) {
+	v := sdk.NewIntervalVariable("interval", "1m")
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err = json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["refresh"] != float64(2) {
+		t.Errorf("expected refresh 2, got %v", got["refresh"])
+	}
+	if got["auto_min"] != "10s" || got["auto_count"] != float64(30) {
+		t.Errorf("expected auto_min 10s and auto_count 30, got %v and %v", got["auto_min"], got["auto_count"])
+	}
+}
+
+func TestBoard_Variables(t *testing.T) {
+	b := sdk.NewBoard("Sample")
+
+	if err := b.AddVariable(sdk.NewCustomVariable("env", "prod", "dev")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddVariable(sdk.NewConstantVariable("cluster", "eu")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddVariable(sdk.NewConstantVariable("env", "x")); err == nil {
+		t.Error("expected error on duplicate variable name")
+	}
+	if err := b.AddVariable(sdk.TemplateVar{}); err == nil {
+		t.Error("expected error on empty variable name")
+	}
+
+	v, ok := b.GetVariable("cluster")
+	if !ok {
+		t.Fatal("variable cluster not found")
+	}
+	v.Query = "us"
+	if b.Templating.List[1].Query != "us" {
+		t.Error("expected GetVariable to return the variable of the board")
+	}
+
+	if !b.RemoveVariable("env") {
+		t.Error("expected env variable to be removed")
+	}
+	if b.RemoveVariable("env") {
+		t.Error("expected env variable to be removed only once")
+	}
+	if len(b.Templating.List) != 1 || b.Templating.List[0].Name != "cluster" {
+		t.Errorf("unexpected variables after removal: %v", b.Templating.List)
+	}
+}
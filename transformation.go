@@ -0,0 +1,133 @@
+package sdk
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+// IDs of the common transformations.
+const (
+	TransformationMerge              = "merge"
+	TransformationOrganize           = "organize"
+	TransformationReduce             = "reduce"
+	TransformationFilterFieldsByName = "filterFieldsByName"
+	TransformationCalculateField     = "calculateField"
+	TransformationJoinByField        = "joinByField"
+	TransformationSeriesToRows       = "seriesToRows"
+	TransformationSeriesToColumns    = "seriesToColumns"
+)
+
+// Transformation represents a single step of the panel transformations.
+// Options are specific for each transformation ID so they are kept as
+// a generic map. Filter optionally restricts the transformation to the
+// frames selected by the matcher, e.g. {"id": "byRefId", "options": "A"}.
+type Transformation struct {
+	ID       string                 `json:"id"`
+	Options  map[string]interface{} `json:"options"`
+	Disabled bool                   `json:"disabled,omitempty"`
+	Filter   *FieldMatcher          `json:"filter,omitempty"`
+}
+
+// NewTransformation initializes a transformation with empty options.
+func NewTransformation(id string) Transformation {
+	return Transformation{ID: id, Options: map[string]interface{}{}}
+}
+
+// NewMergeTransformation merges all frames into a single table.
+func NewMergeTransformation() Transformation {
+	return NewTransformation(TransformationMerge)
+}
+
+// NewOrganizeTransformation hides, reorders and renames fields. Any of
+// arguments may be nil.
+func NewOrganizeTransformation(excludeByName map[string]bool, indexByName map[string]int, renameByName map[string]string) Transformation {
+	t := NewTransformation(TransformationOrganize)
+	if excludeByName == nil {
+		excludeByName = map[string]bool{}
+	}
+	if indexByName == nil {
+		indexByName = map[string]int{}
+	}
+	if renameByName == nil {
+		renameByName = map[string]string{}
+	}
+	t.Options["excludeByName"] = excludeByName
+	t.Options["indexByName"] = indexByName
+	t.Options["renameByName"] = renameByName
+	return t
+}
+
+// NewReduceTransformation reduces each field to the values calculated
+// by reducers (e.g. "mean", "max", "lastNotNull").
+func NewReduceTransformation(reducers ...string) Transformation {
+	t := NewTransformation(TransformationReduce)
+	if reducers == nil {
+		reducers = []string{}
+	}
+	t.Options["reducers"] = reducers
+	return t
+}
+
+// NewFilterFieldsByNameTransformation keeps only the fields with the names.
+func NewFilterFieldsByNameTransformation(names ...string) Transformation {
+	t := NewTransformation(TransformationFilterFieldsByName)
+	if names == nil {
+		names = []string{}
+	}
+	t.Options["include"] = map[string]interface{}{"names": names}
+	return t
+}
+
+// NewCalculateFieldTransformation adds the field calculated with the binary
+// operation on two fields (or a field and a number), e.g. "A", "/", "B".
+func NewCalculateFieldTransformation(alias, left, operator, right string) Transformation {
+	t := NewTransformation(TransformationCalculateField)
+	t.Options["mode"] = "binary"
+	t.Options["alias"] = alias
+	t.Options["binary"] = map[string]interface{}{
+		"left":     left,
+		"operator": operator,
+		"right":    right,
+	}
+	return t
+}
+
+// NewReduceRowTransformation adds the field calculated with the reducer
+// over all numeric fields of each row.
+func NewReduceRowTransformation(alias, reducer string) Transformation {
+	t := NewTransformation(TransformationCalculateField)
+	t.Options["mode"] = "reduceRow"
+	t.Options["alias"] = alias
+	t.Options["reduce"] = map[string]interface{}{"reducer": reducer}
+	return t
+}
+
+// NewJoinByFieldTransformation joins frames by the field. Mode is "outer"
+// or "inner", empty mode means Grafana default.
+func NewJoinByFieldTransformation(field, mode string) Transformation {
+	t := NewTransformation(TransformationJoinByField)
+	t.Options["byField"] = field
+	if mode != "" {
+		t.Options["mode"] = mode
+	}
+	return t
+}
+
+// AddTransformation appends the transformation to the panel.
+func (p *Panel) AddTransformation(t Transformation) {
+	p.Transformations = append(p.Transformations, t)
+}
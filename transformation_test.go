@@ -0,0 +1,99 @@
+package sdk_test
+
+/*
+   Copyright 2016 Alexander I.Grafov <grafov@gmail.com>
+   Copyright 2016-2019 The Grafana SDK authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+	   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+   ॐ तारे तुत्तारे तुरे स्व
+*/
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grafana-tools/sdk"
+)
+
+func TestTransformations_RoundTrip(t *testing.T) {
+	var rawTransformations = `[
+		{"id": "merge", "options": {}},
+		{"id": "organize", "options": {"excludeByName": {"Time": true}, "indexByName": {}, "renameByName": {"Value": "Requests"}}},
+		{"id": "reduce", "options": {"reducers": ["max", "mean"]}, "disabled": true},
+		{"id": "filterFieldsByName", "options": {"include": {"names": ["instance", "Value"]}}, "filter": {"id": "byRefId", "options": "A"}},
+		{"id": "calculateField", "options": {"mode": "binary", "alias": "ratio", "binary": {"left": "A", "operator": "/", "right": "B"}}},
+		{"id": "joinByField", "options": {"byField": "Time", "mode": "outer"}}
+	]`
+	for _, panelType := range []string{"graph", "timeseries", "table", "stat", "text", "row", "some-plugin"} {
+		var rawPanel = []byte(`{"id": 1, "type": "` + panelType + `", "title": "Panel", "transformations": ` + rawTransformations + `}`)
+		var panel sdk.Panel
+		if err := json.Unmarshal(rawPanel, &panel); err != nil {
+			t.Fatalf("%s: %s", panelType, err)
+		}
+		if len(panel.Transformations) != 6 {
+			t.Fatalf("%s: should be 6 transformations but got %d", panelType, len(panel.Transformations))
+		}
+		if !panel.Transformations[2].Disabled || panel.Transformations[3].Filter == nil {
+			t.Errorf("%s: disabled flag and filter should be decoded", panelType)
+		}
+
+		// Typed changes should win over the raw keys kept by custom panels.
+		panel.AddTransformation(sdk.NewMergeTransformation())
+		out, err := json.Marshal(&panel)
+		if err != nil {
+			t.Fatalf("%s: %s", panelType, err)
+		}
+		var got struct {
+			Transformations []interface{} `json:"transformations"`
+		}
+		var expected []interface{}
+		if err = json.Unmarshal(out, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err = json.Unmarshal([]byte(rawTransformations), &expected); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, map[string]interface{}{"id": "merge", "options": map[string]interface{}{}})
+		if !reflect.DeepEqual(expected, got.Transformations) {
+			t.Errorf("%s: transformations changed on round trip:\n%s", panelType, out)
+		}
+	}
+}
+
+func TestTransformations_Constructors(t *testing.T) {
+	for i, tc := range []struct {
+		In  sdk.Transformation
+		Out string
+	}{
+		{sdk.NewMergeTransformation(), `{"id":"merge","options":{}}`},
+		{sdk.NewReduceTransformation("max"), `{"id":"reduce","options":{"reducers":["max"]}}`},
+		{sdk.NewFilterFieldsByNameTransformation("a", "b"), `{"id":"filterFieldsByName","options":{"include":{"names":["a","b"]}}}`},
+		{sdk.NewOrganizeTransformation(map[string]bool{"Time": true}, nil, map[string]string{"Value": "v"}),
+			`{"id":"organize","options":{"excludeByName":{"Time":true},"indexByName":{},"renameByName":{"Value":"v"}}}`},
+		{sdk.NewCalculateFieldTransformation("sum", "A", "+", "B"),
+			`{"id":"calculateField","options":{"alias":"sum","binary":{"left":"A","operator":"+","right":"B"},"mode":"binary"}}`},
+		{sdk.NewReduceRowTransformation("total", "sum"),
+			`{"id":"calculateField","options":{"alias":"total","mode":"reduceRow","reduce":{"reducer":"sum"}}}`},
+		{sdk.NewJoinByFieldTransformation("Time", "outer"), `{"id":"joinByField","options":{"byField":"Time","mode":"outer"}}`},
+	} {
+		out, err := json.Marshal(tc.In)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tc.Out {
+			t.Errorf("test %d: should be %s but got %s", i, tc.Out, out)
+		}
+	}
+}